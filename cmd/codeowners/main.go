@@ -0,0 +1,98 @@
+// Command codeowners uploads a CODEOWNERS file as the ownership rules of a
+// repository and prints the lines the service could not resolve.
+//
+//	go run ./cmd/codeowners -repo acme/api -file .github/CODEOWNERS -dry-run
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/app/handlers"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+)
+
+func main() {
+	server := flag.String("server", "http://localhost:8080", "service base URL")
+	repo := flag.String("repo", "", "repository the rules belong to, e.g. acme/api")
+	path := flag.String("file", "CODEOWNERS", "path to the CODEOWNERS file")
+	dryRun := flag.Bool("dry-run", false, "validate the file without storing the rules")
+	flag.Parse()
+
+	if *repo == "" {
+		fmt.Fprintln(os.Stderr, "-repo is required")
+		os.Exit(2)
+	}
+
+	result, err := upload(*server, *repo, *path, *dryRun)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	for _, p := range result.Problems {
+		if p.Owner != "" {
+			fmt.Printf("%s:%d: %s %s\n", *path, p.Line, p.Message, p.Owner)
+		} else {
+			fmt.Printf("%s:%d: %s\n", *path, p.Line, p.Message)
+		}
+	}
+	verb := "imported"
+	if result.DryRun {
+		verb = "checked"
+	}
+	fmt.Printf("%s %d rules for %s, %d problems\n", verb, len(result.Rules), result.RepositoryID, len(result.Problems))
+	if len(result.Problems) > 0 {
+		os.Exit(1)
+	}
+}
+
+func upload(server, repo, path string, dryRun bool) (*models.CodeOwnersImport, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	query := url.Values{}
+	query.Set("repository_id", repo)
+	query.Set("dry_run", strconv.FormatBool(dryRun))
+	endpoint := strings.TrimSuffix(server, "/") + "/codeOwners/import?" + query.Encode()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "text/plain")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload CODEOWNERS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp handlers.ErrorResponse
+		if err := json.NewDecoder(resp.Body).Decode(&errResp); err != nil || errResp.Error.Message == "" {
+			return nil, fmt.Errorf("unexpected response: %s", resp.Status)
+		}
+		return nil, fmt.Errorf("%s: %s", errResp.Error.Code, errResp.Error.Message)
+	}
+
+	var result models.CodeOwnersImport
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &result, nil
+}
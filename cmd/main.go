@@ -5,16 +5,17 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
-	"github.com/jackc/pgx/v5/pgxpool"
-
 	"github.com/yohnnn/pr_reviewer_assignment_service/internal/app/handlers"
 	"github.com/yohnnn/pr_reviewer_assignment_service/internal/app/router"
 	"github.com/yohnnn/pr_reviewer_assignment_service/internal/config"
 	"github.com/yohnnn/pr_reviewer_assignment_service/internal/logger"
-	postgresRepo "github.com/yohnnn/pr_reviewer_assignment_service/internal/repository/postgres"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/notifier"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/outbox"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/scheduler"
 	"github.com/yohnnn/pr_reviewer_assignment_service/internal/services"
 )
 
@@ -26,45 +27,54 @@ func main() {
 
 	log := logger.New("prod", cfg.Server.LogLevel)
 
-	ctx := context.Background()
-	poolConfig, err := pgxpool.ParseConfig(cfg.Postgres.ConnectionString())
+	db, err := openStorage(context.Background(), cfg, log)
 	if err != nil {
-		log.Error("failed to parse db config", "error", err)
+		log.Error("failed to open storage", "error", err)
 		os.Exit(1)
 	}
+	defer db.close()
 
-	poolConfig.MaxConns = 20
-	poolConfig.MinConns = 5
-
-	dbPool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	reviewerSelector, err := services.NewConfiguredSelector(
+		cfg.Reviewer.Strategy,
+		cfg.Reviewer.TeamStrategies,
+		services.NewTeamReviewLoad(db.users),
+	)
 	if err != nil {
-		log.Error("failed to create db pool", "error", err)
-		os.Exit(1)
-	}
-	defer dbPool.Close()
-
-	if err := dbPool.Ping(ctx); err != nil {
-		log.Error("failed to ping db", "error", err)
+		log.Error("failed to create reviewer selector", "error", err)
 		os.Exit(1)
 	}
-	log.Info("connected to database via pgxpool")
-
-	userRepo := postgresRepo.NewUserRepository(dbPool)
-	teamRepo := postgresRepo.NewTeamRepository(dbPool)
-	prRepo := postgresRepo.NewPullRequestRepository(dbPool)
-	statsRepo := postgresRepo.NewStatsRepository(dbPool)
 
-	userService := services.NewUserService(userRepo, log)
-	teamService := services.NewTeamService(teamRepo, log)
-	prService := services.NewPullRequestService(prRepo, userRepo, log)
-	statsService := services.NewStatsService(statsRepo, log)
+	notificationService := services.NewNotificationService(db.notifications, log)
+	userService := services.NewUserService(db.users, db.teams, db.repositories, db.txManager, reviewerSelector, log)
+	teamService := services.NewTeamService(db.teams, log)
+	prService := services.NewPullRequestService(
+		db.prs, db.users, db.teams, db.repositories, db.codeOwners, db.txManager, reviewerSelector, log,
+	)
+	statsService := services.NewStatsService(db.stats, log)
+	codeOwnerService := services.NewCodeOwnerService(db.codeOwners, db.users, db.teams, log)
+	repositoryService := services.NewRepositoryService(db.repositories, db.teams, log)
+	githubService := services.NewGitHubWebhookService(cfg.Webhook.GitHubSecret, prService, db.users, log)
+	gitlabService := services.NewGitLabWebhookService(cfg.Webhook.GitLabToken, prService, db.users, db.deliveries, log)
 
 	userHandler := handlers.NewUserHandler(userService, prService, log)
 	teamHandler := handlers.NewTeamHandler(teamService, log)
 	prHandler := handlers.NewPullRequestHandler(prService, log)
 	statsHandler := handlers.NewStatsHandler(statsService, log)
-
-	r := router.NewRouter(userHandler, teamHandler, prHandler, statsHandler)
+	webhookHandler := handlers.NewWebhookHandler(githubService, gitlabService, log)
+	notificationHandler := handlers.NewNotificationHandler(notificationService, log)
+	codeOwnerHandler := handlers.NewCodeOwnerHandler(codeOwnerService, log)
+	repositoryHandler := handlers.NewRepositoryHandler(repositoryService, log)
+
+	r := router.NewRouter(
+		userHandler,
+		teamHandler,
+		prHandler,
+		statsHandler,
+		webhookHandler,
+		notificationHandler,
+		codeOwnerHandler,
+		repositoryHandler,
+	)
 	ginEngine := r.InitRoutes()
 
 	srv := &http.Server{
@@ -72,6 +82,45 @@ func main() {
 		Handler: ginEngine,
 	}
 
+	worker := notifier.NewWorker(db.notifications, &http.Client{Timeout: 10 * time.Second}, notifier.Config{
+		MaxAttempts:  cfg.Notifier.MaxAttempts,
+		BaseBackoff:  cfg.Notifier.BaseBackoff,
+		PollInterval: cfg.Notifier.PollInterval,
+	}, log)
+
+	chatNotifier := notifier.NewChatNotifier(&http.Client{Timeout: 10 * time.Second}, db.users, db.teams, log)
+
+	dispatcher := outbox.NewDispatcher(
+		db.outbox,
+		outbox.Publishers{notificationService, chatNotifier},
+		cfg.Notifier.PollInterval,
+		log,
+	)
+
+	jobs := scheduler.New(log)
+	jobs.Every("review_sla", cfg.Scheduler.SLAInterval,
+		scheduler.NewSLAJob(db.locker, db.prs, db.teams, prService, log).Run)
+	jobs.Every("review_digest", cfg.Scheduler.DigestInterval,
+		scheduler.NewDigestJob(db.locker, db.users, db.prs, log).Run)
+	jobs.Every("absence_reassign", cfg.Scheduler.AbsenceInterval,
+		scheduler.NewAbsenceJob(db.locker, db.txManager, db.users, db.prs, prService, log).Run)
+
+	workerCtx, stopWorker := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	workers.Add(3)
+	go func() {
+		defer workers.Done()
+		dispatcher.Run(workerCtx)
+	}()
+	go func() {
+		defer workers.Done()
+		worker.Run(workerCtx)
+	}()
+	go func() {
+		defer workers.Done()
+		jobs.Run(workerCtx)
+	}()
+
 	go func() {
 		log.Info("server started", "port", cfg.Server.Port)
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -91,5 +140,8 @@ func main() {
 	if err := srv.Shutdown(shutdownCtx); err != nil {
 		log.Error("forced shutdown", "error", err)
 	}
+
+	stopWorker()
+	workers.Wait()
 	log.Info("server exited")
 }
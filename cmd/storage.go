@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/config"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/repository"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/repository/inmemory"
+	postgresRepo "github.com/yohnnn/pr_reviewer_assignment_service/internal/repository/postgres"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/repository/sqlite"
+)
+
+type storage struct {
+	users         repository.UserRepositoryInterface
+	teams         repository.TeamRepositoryInterface
+	prs           repository.PullRequestRepositoryInterface
+	stats         repository.StatsRepositoryInterface
+	deliveries    repository.WebhookDeliveryRepositoryInterface
+	notifications repository.NotificationRepositoryInterface
+	outbox        repository.OutboxRepositoryInterface
+	codeOwners    repository.CodeOwnerRepositoryInterface
+	repositories  repository.RepositoryRepositoryInterface
+	txManager     repository.TxManager
+	locker        repository.LockerInterface
+	close         func()
+}
+
+func openStorage(ctx context.Context, cfg *config.Config, log *slog.Logger) (*storage, error) {
+	switch cfg.Storage.Driver {
+	case config.StorageMemory:
+		log.Warn("using in-memory storage, data is lost on exit")
+		return newMemoryStorage(), nil
+	case config.StorageSQLite:
+		return openSQLite(ctx, cfg.Storage.SQLitePath, log)
+	default:
+		return openPostgres(ctx, cfg.Postgres, log)
+	}
+}
+
+func openPostgres(ctx context.Context, cfg config.PostgresConfig, log *slog.Logger) (*storage, error) {
+	poolConfig, err := pgxpool.ParseConfig(cfg.ConnectionString())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse db config: %w", err)
+	}
+
+	poolConfig.MaxConns = 20
+	poolConfig.MinConns = 5
+
+	dbPool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create db pool: %w", err)
+	}
+
+	if err := dbPool.Ping(ctx); err != nil {
+		dbPool.Close()
+		return nil, fmt.Errorf("failed to ping db: %w", err)
+	}
+	log.Info("connected to database via pgxpool")
+
+	return &storage{
+		users:         postgresRepo.NewUserRepository(dbPool),
+		teams:         postgresRepo.NewTeamRepository(dbPool),
+		prs:           postgresRepo.NewPullRequestRepository(dbPool),
+		stats:         postgresRepo.NewStatsRepository(dbPool),
+		deliveries:    postgresRepo.NewWebhookDeliveryRepository(dbPool),
+		notifications: postgresRepo.NewNotificationRepository(dbPool),
+		outbox:        postgresRepo.NewOutboxRepository(dbPool),
+		codeOwners:    postgresRepo.NewCodeOwnerRepository(dbPool),
+		repositories:  postgresRepo.NewRepositoryRepository(dbPool),
+		txManager:     postgresRepo.NewTxManager(dbPool),
+		locker:        postgresRepo.NewLocker(dbPool),
+		close:         dbPool.Close,
+	}, nil
+}
+
+// openSQLite serves a single replica: the scheduler locks are held in the process.
+func openSQLite(ctx context.Context, path string, log *slog.Logger) (*storage, error) {
+	db, err := sqlite.Open(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	log.Info("opened sqlite database", "path", path)
+
+	return &storage{
+		users:         sqlite.NewUserRepository(db),
+		teams:         sqlite.NewTeamRepository(db),
+		prs:           sqlite.NewPullRequestRepository(db),
+		stats:         sqlite.NewStatsRepository(db),
+		deliveries:    sqlite.NewWebhookDeliveryRepository(db),
+		notifications: sqlite.NewNotificationRepository(db),
+		outbox:        sqlite.NewOutboxRepository(db),
+		codeOwners:    sqlite.NewCodeOwnerRepository(db),
+		repositories:  sqlite.NewRepositoryRepository(db),
+		txManager:     sqlite.NewTxManager(db),
+		locker:        inmemory.NewLocker(),
+		close:         func() { _ = db.Close() },
+	}, nil
+}
+
+func newMemoryStorage() *storage {
+	store := inmemory.NewStore()
+	return &storage{
+		users:         inmemory.NewUserRepository(store),
+		teams:         inmemory.NewTeamRepository(store),
+		prs:           inmemory.NewPullRequestRepository(store),
+		stats:         inmemory.NewStatsRepository(store),
+		deliveries:    inmemory.NewWebhookDeliveryRepository(store),
+		notifications: inmemory.NewNotificationRepository(store),
+		outbox:        inmemory.NewOutboxRepository(store),
+		codeOwners:    inmemory.NewCodeOwnerRepository(store),
+		repositories:  inmemory.NewRepositoryRepository(store),
+		txManager:     inmemory.NewTxManager(store),
+		locker:        inmemory.NewLocker(),
+		close:         func() {},
+	}
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/app/handlers/requests"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/services"
+)
+
+const maxCodeOwnersSize = 1 << 20
+
+type CodeOwnerHandler struct {
+	codeOwnerService services.CodeOwnerServiceInterface
+	log              *slog.Logger
+}
+
+func NewCodeOwnerHandler(
+	codeOwnerService services.CodeOwnerServiceInterface,
+	log *slog.Logger,
+) *CodeOwnerHandler {
+	return &CodeOwnerHandler{
+		codeOwnerService: codeOwnerService,
+		log:              log,
+	}
+}
+
+// GET /codeOwners/rules
+func (h *CodeOwnerHandler) GetRules(c *gin.Context) {
+	repositoryID := c.Query("repository_id")
+	rules, err := h.codeOwnerService.GetRules(c.Request.Context(), repositoryID)
+	if err != nil {
+		writeErrorResponse(c, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"repository_id": repositoryID,
+		"rules":         rules,
+	})
+}
+
+// POST /codeOwners/rules
+func (h *CodeOwnerHandler) SetRules(c *gin.Context) {
+	var req requests.SetCodeOwnersReq
+	ctx := c.Request.Context()
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.WarnContext(ctx, "failed to validate or decode request", "err", err)
+		writeErrorResponse(c, http.StatusBadRequest, ErrCodeInvalidFormat, "Invalid request payload")
+		return
+	}
+
+	rules := make([]models.CodeOwnerRule, 0, len(req.Rules))
+	for _, rule := range req.Rules {
+		rules = append(rules, models.CodeOwnerRule{
+			Pattern: rule.Pattern,
+			Users:   rule.Users,
+			Teams:   rule.Teams,
+		})
+	}
+
+	saved, err := h.codeOwnerService.SetRules(ctx, req.RepositoryID, rules)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidCodeOwners) {
+			writeErrorResponse(c, http.StatusBadRequest, ErrCodeInvalidFormat, "Invalid code owner pattern")
+		} else {
+			writeErrorResponse(c, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"repository_id": req.RepositoryID,
+		"rules":         saved,
+	})
+}
+
+// POST /codeOwners/import
+func (h *CodeOwnerHandler) Import(c *gin.Context) {
+	repositoryID := c.Query("repository_id")
+	ctx := c.Request.Context()
+
+	if repositoryID == "" {
+		writeErrorResponse(c, http.StatusBadRequest, ErrCodeInvalidFormat, "repository_id is required")
+		return
+	}
+	dryRun := false
+	if value := c.Query("dry_run"); value != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(value); err != nil {
+			writeErrorResponse(c, http.StatusBadRequest, ErrCodeInvalidFormat, "Invalid dry_run value")
+			return
+		}
+	}
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxCodeOwnersSize)
+
+	result, err := h.codeOwnerService.ImportCodeOwners(ctx, repositoryID, body, dryRun)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidCodeOwners) {
+			writeErrorResponse(c, http.StatusBadRequest, ErrCodeInvalidFormat, "Invalid CODEOWNERS file")
+		} else {
+			writeErrorResponse(c, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -15,6 +15,18 @@ const (
 	ErrCodeNotAssigned   = "NOT_ASSIGNED"
 	ErrCodeNoCandidate   = "NO_CANDIDATE"
 	ErrCodeStatsError    = "STATS_ERROR"
+
+	ErrCodeNotEnoughReviewers = "NOT_ENOUGH_REVIEWERS"
+	ErrCodeNotApproved        = "NOT_APPROVED"
+	ErrCodeInvalidStatus      = "INVALID_STATUS"
+	ErrCodeInvalidSignature   = "INVALID_SIGNATURE"
+	ErrCodeInvalidEventType   = "INVALID_EVENT_TYPE"
+	ErrCodeRepositoryExists   = "REPOSITORY_EXISTS"
+	ErrCodeAlreadyAssigned    = "ALREADY_ASSIGNED"
+	ErrCodeReviewerInactive   = "REVIEWER_INACTIVE"
+	ErrCodeAuthorReviewer     = "AUTHOR_REVIEWER"
+	ErrCodeNotEligible        = "NOT_ELIGIBLE"
+	ErrCodePRChanged          = "PR_CHANGED"
 )
 
 type ErrorResponse struct {
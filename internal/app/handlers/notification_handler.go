@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/app/handlers/requests"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/services"
+)
+
+type NotificationHandler struct {
+	notificationService services.NotificationServiceInterface
+	log                 *slog.Logger
+}
+
+func NewNotificationHandler(
+	notificationService services.NotificationServiceInterface,
+	log *slog.Logger,
+) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+		log:                 log,
+	}
+}
+
+// POST /notifications/subscriptions
+func (h *NotificationHandler) CreateSubscription(c *gin.Context) {
+	var req requests.CreateSubscriptionReq
+	ctx := c.Request.Context()
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.WarnContext(ctx, "failed to validate or decode request", "err", err)
+		writeErrorResponse(c, http.StatusBadRequest, ErrCodeInvalidFormat, "Invalid request payload")
+		return
+	}
+
+	sub, err := h.notificationService.CreateSubscription(ctx, &models.Subscription{
+		URL:        req.URL,
+		Secret:     req.Secret,
+		EventTypes: req.EventTypes,
+	})
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidEventType) {
+			writeErrorResponse(c, http.StatusBadRequest, ErrCodeInvalidEventType, "Unknown event type")
+		} else {
+			writeErrorResponse(c, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"subscription": sub})
+}
+
+// GET /notifications/subscriptions
+func (h *NotificationHandler) ListSubscriptions(c *gin.Context) {
+	subs, err := h.notificationService.ListSubscriptions(c.Request.Context())
+	if err != nil {
+		writeErrorResponse(c, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"subscriptions": subs})
+}
+
+// POST /notifications/subscriptions/delete
+func (h *NotificationHandler) DeleteSubscription(c *gin.Context) {
+	var req requests.DeleteSubscriptionReq
+	ctx := c.Request.Context()
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.WarnContext(ctx, "failed to validate or decode request", "err", err)
+		writeErrorResponse(c, http.StatusBadRequest, ErrCodeInvalidFormat, "Invalid request payload")
+		return
+	}
+
+	if err := h.notificationService.DeleteSubscription(ctx, req.ID); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			writeErrorResponse(c, http.StatusNotFound, ErrCodeNotFound, "Subscription not found")
+		} else {
+			writeErrorResponse(c, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"subscription_id": req.ID})
+}
+
+// GET /notifications/deadLetters
+func (h *NotificationHandler) ListDeadLetters(c *gin.Context) {
+	deliveries, err := h.notificationService.ListDeadLetters(c.Request.Context())
+	if err != nil {
+		writeErrorResponse(c, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"dead_letters": deliveries})
+}
+
+// POST /notifications/deadLetters/replay
+func (h *NotificationHandler) ReplayDeadLetter(c *gin.Context) {
+	var req requests.ReplayDeadLetterReq
+	ctx := c.Request.Context()
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.WarnContext(ctx, "failed to validate or decode request", "err", err)
+		writeErrorResponse(c, http.StatusBadRequest, ErrCodeInvalidFormat, "Invalid request payload")
+		return
+	}
+
+	if err := h.notificationService.ReplayDeadLetter(ctx, req.ID); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			writeErrorResponse(c, http.StatusNotFound, ErrCodeNotFound, "Dead letter not found")
+		} else {
+			writeErrorResponse(c, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"delivery_id": req.ID, "status": models.DeliveryPending})
+}
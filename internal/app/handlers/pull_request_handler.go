@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"errors"
 	"log/slog"
 	"net/http"
@@ -37,12 +38,16 @@ func (h *PullRequestHandler) CreatePullRequest(c *gin.Context) {
 		return
 	}
 
-	pr, err := h.pullRequestService.CreatePullRequest(ctx, req.ID, req.Name, req.AuthorID)
+	pr, err := h.pullRequestService.CreatePullRequest(
+		ctx, req.ID, req.Name, req.AuthorID, req.RepositoryID, req.Draft, req.Files,
+	)
 	if err != nil {
 		if errors.Is(err, models.ErrAlreadyExists) {
 			writeErrorResponse(c, http.StatusConflict, ErrCodePRExists, "PR id already exists")
 		} else if errors.Is(err, models.ErrNotFound) {
 			writeErrorResponse(c, http.StatusNotFound, ErrCodeNotFound, "Author not found")
+		} else if errors.Is(err, models.ErrNotEnoughReviewers) {
+			writeErrorResponse(c, http.StatusConflict, ErrCodeNotEnoughReviewers, "not enough active reviewers in team")
 		} else {
 			writeErrorResponse(c, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
 		}
@@ -66,6 +71,10 @@ func (h *PullRequestHandler) MergePullRequest(c *gin.Context) {
 	if err != nil {
 		if errors.Is(err, models.ErrNotFound) {
 			writeErrorResponse(c, http.StatusNotFound, ErrCodeNotFound, "PR not found")
+		} else if errors.Is(err, models.ErrNotApproved) {
+			writeErrorResponse(c, http.StatusConflict, ErrCodeNotApproved, "PR does not have enough approvals")
+		} else if errors.Is(err, models.ErrInvalidStatus) {
+			writeErrorResponse(c, http.StatusConflict, ErrCodeInvalidStatus, "only OPEN PR can be merged")
 		} else {
 			writeErrorResponse(c, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
 		}
@@ -86,16 +95,28 @@ func (h *PullRequestHandler) ReassignPR(c *gin.Context) {
 		return
 	}
 
-	pr, newReviewerID, err := h.pullRequestService.ReassignReviewer(ctx, req.ID, req.OldUserID)
+	pr, newReviewerID, err := h.pullRequestService.ReassignReviewer(ctx, req.ID, req.OldUserID, req.NewUserID)
 	if err != nil {
 		if errors.Is(err, models.ErrNotFound) {
 			writeErrorResponse(c, http.StatusNotFound, ErrCodeNotFound, "PR or User not found")
 		} else if errors.Is(err, models.ErrPRMerged) {
 			writeErrorResponse(c, http.StatusConflict, ErrCodePRMerged, "cannot reassign on merged PR")
+		} else if errors.Is(err, models.ErrInvalidStatus) {
+			writeErrorResponse(c, http.StatusConflict, ErrCodeInvalidStatus, "only OPEN PR can be reassigned")
 		} else if errors.Is(err, models.ErrNotAssigned) {
 			writeErrorResponse(c, http.StatusConflict, ErrCodeNotAssigned, "reviewer is not assigned to this PR")
 		} else if errors.Is(err, models.ErrNoCandidates) {
 			writeErrorResponse(c, http.StatusConflict, ErrCodeNoCandidate, "no active replacement candidate in team")
+		} else if errors.Is(err, models.ErrAuthorReviewer) {
+			writeErrorResponse(c, http.StatusConflict, ErrCodeAuthorReviewer, "author cannot review own PR")
+		} else if errors.Is(err, models.ErrAlreadyAssigned) {
+			writeErrorResponse(c, http.StatusConflict, ErrCodeAlreadyAssigned, "reviewer is already assigned to this PR")
+		} else if errors.Is(err, models.ErrReviewerInactive) {
+			writeErrorResponse(c, http.StatusConflict, ErrCodeReviewerInactive, "reviewer is not active")
+		} else if errors.Is(err, models.ErrNotEligible) {
+			writeErrorResponse(c, http.StatusConflict, ErrCodeNotEligible, "user is not a replacement candidate for this PR")
+		} else if errors.Is(err, models.ErrPRChanged) {
+			writeErrorResponse(c, http.StatusConflict, ErrCodePRChanged, "PR is being changed concurrently, try again")
 		} else {
 			writeErrorResponse(c, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
 		}
@@ -107,3 +128,186 @@ func (h *PullRequestHandler) ReassignPR(c *gin.Context) {
 		"replaced_by": newReviewerID,
 	})
 }
+
+// POST /pullRequest/reviewers/add
+func (h *PullRequestHandler) AddReviewer(c *gin.Context) {
+	var req requests.PRReviewerReq
+	ctx := c.Request.Context()
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.WarnContext(ctx, "failed to validate request", "err", err)
+		writeErrorResponse(c, http.StatusBadRequest, ErrCodeInvalidFormat, "Invalid request payload")
+		return
+	}
+
+	pr, err := h.pullRequestService.AddReviewer(ctx, req.ID, req.UserID)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			writeErrorResponse(c, http.StatusNotFound, ErrCodeNotFound, "PR or User not found")
+		} else if errors.Is(err, models.ErrPRMerged) {
+			writeErrorResponse(c, http.StatusConflict, ErrCodePRMerged, "cannot change reviewers on merged PR")
+		} else if errors.Is(err, models.ErrInvalidStatus) {
+			writeErrorResponse(c, http.StatusConflict, ErrCodeInvalidStatus, "cannot change reviewers on closed PR")
+		} else if errors.Is(err, models.ErrAuthorReviewer) {
+			writeErrorResponse(c, http.StatusConflict, ErrCodeAuthorReviewer, "author cannot review own PR")
+		} else if errors.Is(err, models.ErrAlreadyAssigned) {
+			writeErrorResponse(c, http.StatusConflict, ErrCodeAlreadyAssigned, "reviewer is already assigned to this PR")
+		} else if errors.Is(err, models.ErrReviewerInactive) {
+			writeErrorResponse(c, http.StatusConflict, ErrCodeReviewerInactive, "reviewer is not active")
+		} else if errors.Is(err, models.ErrNotEligible) {
+			writeErrorResponse(c, http.StatusConflict, ErrCodeNotEligible, "reviewer is absent")
+		} else {
+			writeErrorResponse(c, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"pr": pr})
+}
+
+// POST /pullRequest/reviewers/remove
+func (h *PullRequestHandler) RemoveReviewer(c *gin.Context) {
+	var req requests.PRReviewerReq
+	ctx := c.Request.Context()
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.WarnContext(ctx, "failed to validate request", "err", err)
+		writeErrorResponse(c, http.StatusBadRequest, ErrCodeInvalidFormat, "Invalid request payload")
+		return
+	}
+
+	pr, err := h.pullRequestService.RemoveReviewer(ctx, req.ID, req.UserID)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			writeErrorResponse(c, http.StatusNotFound, ErrCodeNotFound, "PR not found")
+		} else if errors.Is(err, models.ErrPRMerged) {
+			writeErrorResponse(c, http.StatusConflict, ErrCodePRMerged, "cannot change reviewers on merged PR")
+		} else if errors.Is(err, models.ErrInvalidStatus) {
+			writeErrorResponse(c, http.StatusConflict, ErrCodeInvalidStatus, "cannot change reviewers on closed PR")
+		} else if errors.Is(err, models.ErrNotAssigned) {
+			writeErrorResponse(c, http.StatusConflict, ErrCodeNotAssigned, "reviewer is not assigned to this PR")
+		} else {
+			writeErrorResponse(c, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"pr": pr})
+}
+
+// POST /pullRequest/review
+func (h *PullRequestHandler) SubmitReview(c *gin.Context) {
+	var req requests.SubmitReviewReq
+	ctx := c.Request.Context()
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.WarnContext(ctx, "failed to validate or decode request", "err", err)
+		writeErrorResponse(c, http.StatusBadRequest, ErrCodeInvalidFormat, "Invalid request payload")
+		return
+	}
+
+	review, err := h.pullRequestService.SubmitReview(ctx, req.ID, req.ReviewerID, req.Verdict)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			writeErrorResponse(c, http.StatusNotFound, ErrCodeNotFound, "PR not found")
+		} else if errors.Is(err, models.ErrInvalidVerdict) {
+			writeErrorResponse(c, http.StatusBadRequest, ErrCodeInvalidFormat, "Invalid verdict")
+		} else if errors.Is(err, models.ErrPRMerged) {
+			writeErrorResponse(c, http.StatusConflict, ErrCodePRMerged, "cannot review merged PR")
+		} else if errors.Is(err, models.ErrInvalidStatus) {
+			writeErrorResponse(c, http.StatusConflict, ErrCodeInvalidStatus, "only OPEN PR can be reviewed")
+		} else if errors.Is(err, models.ErrNotAssigned) {
+			writeErrorResponse(c, http.StatusConflict, ErrCodeNotAssigned, "reviewer is not assigned to this PR")
+		} else {
+			writeErrorResponse(c, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"review": review})
+}
+
+// GET /pullRequest/reviews
+func (h *PullRequestHandler) GetReviews(c *gin.Context) {
+	prID := c.Query("pull_request_id")
+	ctx := c.Request.Context()
+
+	if prID == "" {
+		h.log.WarnContext(ctx, "missing pull_request_id query param")
+		writeErrorResponse(c, http.StatusBadRequest, ErrCodeInvalidFormat, "pull_request_id query parameter is required")
+		return
+	}
+
+	reviews, err := h.pullRequestService.GetReviews(ctx, prID)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			writeErrorResponse(c, http.StatusNotFound, ErrCodeNotFound, "PR not found")
+		} else {
+			writeErrorResponse(c, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"pull_request_id": prID,
+		"reviews":         reviews,
+	})
+}
+
+// GET /pullRequest/overdue
+func (h *PullRequestHandler) GetOverdue(c *gin.Context) {
+	teamName := c.Query("team_name")
+
+	overdue, err := h.pullRequestService.GetOverdueReviews(c.Request.Context(), teamName)
+	if err != nil {
+		writeErrorResponse(c, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"overdue": overdue})
+}
+
+// POST /pullRequest/close
+func (h *PullRequestHandler) ClosePullRequest(c *gin.Context) {
+	h.changeStatus(c, h.pullRequestService.ClosePullRequest)
+}
+
+// POST /pullRequest/reopen
+func (h *PullRequestHandler) ReopenPullRequest(c *gin.Context) {
+	h.changeStatus(c, h.pullRequestService.ReopenPullRequest)
+}
+
+// POST /pullRequest/ready
+func (h *PullRequestHandler) MarkReady(c *gin.Context) {
+	h.changeStatus(c, h.pullRequestService.MarkReady)
+}
+
+func (h *PullRequestHandler) changeStatus(
+	c *gin.Context,
+	change func(ctx context.Context, id string) (*models.PullRequest, error),
+) {
+	var req requests.ChangePRStatusReq
+	ctx := c.Request.Context()
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.WarnContext(ctx, "failed to validate or decode request", "err", err)
+		writeErrorResponse(c, http.StatusBadRequest, ErrCodeInvalidFormat, "Invalid request payload")
+		return
+	}
+
+	pr, err := change(ctx, req.ID)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			writeErrorResponse(c, http.StatusNotFound, ErrCodeNotFound, "PR not found")
+		} else if errors.Is(err, models.ErrPRMerged) {
+			writeErrorResponse(c, http.StatusConflict, ErrCodePRMerged, "cannot change merged PR")
+		} else if errors.Is(err, models.ErrInvalidStatus) {
+			writeErrorResponse(c, http.StatusConflict, ErrCodeInvalidStatus, "status transition is not allowed")
+		} else if errors.Is(err, models.ErrNotEnoughReviewers) {
+			writeErrorResponse(c, http.StatusConflict, ErrCodeNotEnoughReviewers, "not enough active reviewers in team")
+		} else {
+			writeErrorResponse(c, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"pr": pr})
+}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/app/handlers/requests"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/services"
+)
+
+type RepositoryHandler struct {
+	repositoryService services.RepositoryServiceInterface
+	log               *slog.Logger
+}
+
+func NewRepositoryHandler(
+	repositoryService services.RepositoryServiceInterface,
+	log *slog.Logger,
+) *RepositoryHandler {
+	return &RepositoryHandler{
+		repositoryService: repositoryService,
+		log:               log,
+	}
+}
+
+// POST /repositories/add
+func (h *RepositoryHandler) AddRepository(c *gin.Context) {
+	repo, ok := h.bindRepository(c)
+	if !ok {
+		return
+	}
+
+	created, err := h.repositoryService.CreateRepository(c.Request.Context(), repo)
+	if err != nil {
+		if errors.Is(err, models.ErrAlreadyExists) {
+			writeErrorResponse(c, http.StatusConflict, ErrCodeRepositoryExists, "repository_id already exists")
+		} else {
+			h.writeRepositoryError(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"repository": created})
+}
+
+// POST /repositories/update
+func (h *RepositoryHandler) UpdateRepository(c *gin.Context) {
+	repo, ok := h.bindRepository(c)
+	if !ok {
+		return
+	}
+
+	updated, err := h.repositoryService.UpdateRepository(c.Request.Context(), repo)
+	if err != nil {
+		h.writeRepositoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"repository": updated})
+}
+
+// GET /repositories/get
+func (h *RepositoryHandler) GetRepository(c *gin.Context) {
+	repositoryID := c.Query("repository_id")
+	ctx := c.Request.Context()
+
+	if repositoryID == "" {
+		writeErrorResponse(c, http.StatusBadRequest, ErrCodeInvalidFormat, "repository_id is required")
+		return
+	}
+
+	repo, err := h.repositoryService.GetRepository(ctx, repositoryID)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			writeErrorResponse(c, http.StatusNotFound, ErrCodeNotFound, "Repository not found")
+		} else {
+			writeErrorResponse(c, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, repo)
+}
+
+// GET /repositories/list
+func (h *RepositoryHandler) ListRepositories(c *gin.Context) {
+	teamName := c.Query("team_name")
+	ctx := c.Request.Context()
+
+	if teamName == "" {
+		writeErrorResponse(c, http.StatusBadRequest, ErrCodeInvalidFormat, "team_name is required")
+		return
+	}
+
+	repos, err := h.repositoryService.ListRepositories(ctx, teamName)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			writeErrorResponse(c, http.StatusNotFound, ErrCodeNotFound, "Team not found")
+		} else {
+			writeErrorResponse(c, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"team_name":    teamName,
+		"repositories": repos,
+	})
+}
+
+func (h *RepositoryHandler) bindRepository(c *gin.Context) (*models.Repository, bool) {
+	var req requests.RepositoryReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.WarnContext(c.Request.Context(), "failed to validate or decode request", "err", err)
+		writeErrorResponse(c, http.StatusBadRequest, ErrCodeInvalidFormat, "Invalid request payload")
+		return nil, false
+	}
+
+	repo := &models.Repository{
+		ID:        req.ID,
+		TeamName:  req.TeamName,
+		Reviewers: req.Reviewers,
+	}
+	if req.Settings != nil {
+		repo.Settings = &models.RepositorySettings{
+			ReviewersCount:    req.Settings.ReviewersCount,
+			MinReviewers:      req.Settings.MinReviewers,
+			RequiredApprovals: req.Settings.RequiredApprovals,
+		}
+	}
+	return repo, true
+}
+
+func (h *RepositoryHandler) writeRepositoryError(c *gin.Context, err error) {
+	if errors.Is(err, models.ErrNotFound) {
+		writeErrorResponse(c, http.StatusNotFound, ErrCodeNotFound, "Repository or team not found")
+	} else if errors.Is(err, models.ErrInvalidSettings) {
+		writeErrorResponse(c, http.StatusBadRequest, ErrCodeInvalidFormat, "Invalid repository settings")
+	} else if errors.Is(err, models.ErrInvalidReviewerPool) {
+		writeErrorResponse(c, http.StatusBadRequest, ErrCodeInvalidFormat, "Reviewers must be members of the team")
+	} else {
+		writeErrorResponse(c, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+	}
+}
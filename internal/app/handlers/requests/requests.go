@@ -1,5 +1,7 @@
 package requests
 
+import "time"
+
 type CreateTeamReq struct {
 	Name    string          `json:"team_name" binding:"required"`
 	Members []TeamMemberReq `json:"members"   binding:"required,dive"`
@@ -11,26 +13,126 @@ type TeamMemberReq struct {
 	IsActive bool   `json:"is_active"`
 }
 
+type TeamSettingsReq struct {
+	Name              string `json:"team_name"          binding:"required"`
+	ReviewersCount    int    `json:"reviewers_count"    binding:"required,min=1"`
+	MinReviewers      int    `json:"min_reviewers"      binding:"min=0,ltefield=ReviewersCount"`
+	RequiredApprovals int    `json:"required_approvals" binding:"min=0,ltefield=ReviewersCount"`
+	ChatWebhookURL    string `json:"chat_webhook_url"   binding:"omitempty,url"`
+	ReviewSLAMinutes  int    `json:"review_sla_minutes" binding:"min=0"`
+	SLAAutoReassign   bool   `json:"sla_auto_reassign"`
+	// FallbackTeams are asked in order when the team runs out of reviewers.
+	FallbackTeams []string `json:"fallback_teams" binding:"omitempty,dive,required"`
+}
+
+type RepositoryReq struct {
+	ID        string                 `json:"repository_id" binding:"required"`
+	TeamName  string                 `json:"team_name"     binding:"required"`
+	Reviewers []string               `json:"reviewers"     binding:"omitempty,dive,required"`
+	Settings  *RepositorySettingsReq `json:"settings"`
+}
+
+type RepositorySettingsReq struct {
+	ReviewersCount    int `json:"reviewers_count"    binding:"required,min=1"`
+	MinReviewers      int `json:"min_reviewers"      binding:"min=0,ltefield=ReviewersCount"`
+	RequiredApprovals int `json:"required_approvals" binding:"min=0,ltefield=ReviewersCount"`
+}
+
 type SetActiveReq struct {
 	UserID   string `json:"user_id"   binding:"required"`
 	IsActive bool   `json:"is_active"`
 }
 
+type LinkIdentityReq struct {
+	UserID   string `json:"user_id"  binding:"required"`
+	Provider string `json:"provider" binding:"required,oneof=github gitlab gitlab_id email"`
+	Login    string `json:"login"    binding:"required"`
+}
+
+type SetQuietHoursReq struct {
+	UserID   string `json:"user_id"  binding:"required"`
+	From     string `json:"from"     binding:"required_with=To"`
+	To       string `json:"to"       binding:"required_with=From"`
+	Timezone string `json:"timezone"`
+}
+
+type CreateAbsenceReq struct {
+	UserID   string    `json:"user_id"   binding:"required"`
+	StartsAt time.Time `json:"starts_at" binding:"required"`
+	EndsAt   time.Time `json:"ends_at"   binding:"required"`
+	Reason   string    `json:"reason"`
+}
+
+type CancelAbsenceReq struct {
+	ID int64 `json:"absence_id" binding:"required"`
+}
+
+type SetChatHandleReq struct {
+	UserID     string `json:"user_id"     binding:"required"`
+	ChatHandle string `json:"chat_handle"`
+}
+
 type CreatePRReq struct {
-	ID       string `json:"pull_request_id"   binding:"required"`
-	Name     string `json:"pull_request_name" binding:"required"`
-	AuthorID string `json:"author_id"         binding:"required"`
+	ID       string   `json:"pull_request_id"   binding:"required"`
+	Name     string   `json:"pull_request_name" binding:"required"`
+	AuthorID string   `json:"author_id"         binding:"required"`
+	Draft    bool     `json:"draft"`
+	Files    []string `json:"files"             binding:"omitempty,dive,required"`
+	// RepositoryID selects the repository's code owner rules, such as "acme/api".
+	RepositoryID string `json:"repository_id"`
 }
 
 type MergePRReq struct {
 	ID string `json:"pull_request_id" binding:"required"`
 }
 
+type ChangePRStatusReq struct {
+	ID string `json:"pull_request_id" binding:"required"`
+}
+
 type ReassignPRReq struct {
 	ID        string `json:"pull_request_id" binding:"required"`
 	OldUserID string `json:"old_user_id"     binding:"required"`
+	// NewUserID picks the replacement instead of a random candidate.
+	NewUserID string `json:"new_user_id"`
+}
+
+type PRReviewerReq struct {
+	ID     string `json:"pull_request_id" binding:"required"`
+	UserID string `json:"user_id"         binding:"required"`
+}
+
+type SubmitReviewReq struct {
+	ID         string `json:"pull_request_id" binding:"required"`
+	ReviewerID string `json:"reviewer_id"     binding:"required"`
+	Verdict    string `json:"verdict"         binding:"required,oneof=APPROVED CHANGES_REQUESTED COMMENTED"`
 }
 
 type DeactivateRequest struct {
 	UserIDs []string `json:"user_ids" binding:"required,min=1"`
 }
+
+type CreateSubscriptionReq struct {
+	URL        string   `json:"url"         binding:"required,url"`
+	Secret     string   `json:"secret"`
+	EventTypes []string `json:"event_types"`
+}
+
+type DeleteSubscriptionReq struct {
+	ID int64 `json:"subscription_id" binding:"required"`
+}
+
+type ReplayDeadLetterReq struct {
+	ID int64 `json:"delivery_id" binding:"required"`
+}
+
+type SetCodeOwnersReq struct {
+	RepositoryID string             `json:"repository_id"`
+	Rules        []CodeOwnerRuleReq `json:"rules"         binding:"dive"`
+}
+
+type CodeOwnerRuleReq struct {
+	Pattern string   `json:"pattern" binding:"required"`
+	Users   []string `json:"users"`
+	Teams   []string `json:"teams"`
+}
@@ -88,3 +88,39 @@ func (h *TeamHandler) AddTeam(c *gin.Context) {
 	c.JSON(http.StatusCreated, gin.H{"team": createdTeam})
 
 }
+
+// POST /team/settings
+func (h *TeamHandler) SetSettings(c *gin.Context) {
+	var req requests.TeamSettingsReq
+	ctx := c.Request.Context()
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.WarnContext(ctx, "failed to validate or decode request", "err", err)
+		writeErrorResponse(c, http.StatusBadRequest, ErrCodeInvalidFormat, "Invalid request payload")
+		return
+	}
+
+	settings, err := h.teamService.SetSettings(ctx, req.Name, &models.TeamSettings{
+		ReviewersCount:    req.ReviewersCount,
+		MinReviewers:      req.MinReviewers,
+		RequiredApprovals: req.RequiredApprovals,
+		ChatWebhookURL:    req.ChatWebhookURL,
+		ReviewSLAMinutes:  req.ReviewSLAMinutes,
+		SLAAutoReassign:   req.SLAAutoReassign,
+		FallbackTeams:     req.FallbackTeams,
+	})
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			writeErrorResponse(c, http.StatusNotFound, ErrCodeNotFound, "Team not found")
+		} else if errors.Is(err, models.ErrInvalidSettings) {
+			writeErrorResponse(c, http.StatusBadRequest, ErrCodeInvalidFormat, "Invalid team settings")
+		} else {
+			writeErrorResponse(c, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"team_name": req.Name,
+		"settings":  settings,
+	})
+}
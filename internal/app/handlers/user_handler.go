@@ -12,6 +12,8 @@ import (
 	"github.com/yohnnn/pr_reviewer_assignment_service/internal/services"
 )
 
+const maxCalendarSize = 5 << 20
+
 type UserHandler struct {
 	userService services.UserServiceInterface
 	prService   services.PullRequestServiceInterface
@@ -94,3 +96,179 @@ func (h *UserHandler) DeactivateUsers(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, gin.H{"status": "Users deactivated"})
 }
+
+// POST /users/linkIdentity
+func (h *UserHandler) LinkIdentity(c *gin.Context) {
+	var req requests.LinkIdentityReq
+	ctx := c.Request.Context()
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.WarnContext(ctx, "failed to validate or decode request", "err", err)
+		writeErrorResponse(c, http.StatusBadRequest, ErrCodeInvalidFormat, "Invalid request payload")
+		return
+	}
+
+	identity := &models.UserIdentity{
+		Provider: req.Provider,
+		Login:    req.Login,
+		UserID:   req.UserID,
+	}
+	if err := h.userService.LinkIdentity(ctx, identity); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			writeErrorResponse(c, http.StatusNotFound, ErrCodeNotFound, "User not found")
+		} else {
+			writeErrorResponse(c, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"identity": identity})
+}
+
+// POST /users/setChatHandle
+func (h *UserHandler) SetChatHandle(c *gin.Context) {
+	var req requests.SetChatHandleReq
+	ctx := c.Request.Context()
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.WarnContext(ctx, "failed to validate or decode request", "err", err)
+		writeErrorResponse(c, http.StatusBadRequest, ErrCodeInvalidFormat, "Invalid request payload")
+		return
+	}
+
+	if err := h.userService.SetChatHandle(ctx, req.UserID, req.ChatHandle); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			writeErrorResponse(c, http.StatusNotFound, ErrCodeNotFound, "User not found")
+		} else {
+			writeErrorResponse(c, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user_id":     req.UserID,
+		"chat_handle": req.ChatHandle,
+	})
+}
+
+// POST /users/setQuietHours
+func (h *UserHandler) SetQuietHours(c *gin.Context) {
+	var req requests.SetQuietHoursReq
+	ctx := c.Request.Context()
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.WarnContext(ctx, "failed to validate or decode request", "err", err)
+		writeErrorResponse(c, http.StatusBadRequest, ErrCodeInvalidFormat, "Invalid request payload")
+		return
+	}
+
+	quiet := models.QuietHours{From: req.From, To: req.To, Timezone: req.Timezone}
+	if err := h.userService.SetQuietHours(ctx, req.UserID, quiet); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			writeErrorResponse(c, http.StatusNotFound, ErrCodeNotFound, "User not found")
+		} else if errors.Is(err, models.ErrInvalidQuietHours) {
+			writeErrorResponse(c, http.StatusBadRequest, ErrCodeInvalidFormat, "Invalid quiet hours")
+		} else {
+			writeErrorResponse(c, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user_id":     req.UserID,
+		"quiet_hours": quiet,
+	})
+}
+
+// POST /users/absences
+func (h *UserHandler) CreateAbsence(c *gin.Context) {
+	var req requests.CreateAbsenceReq
+	ctx := c.Request.Context()
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.WarnContext(ctx, "failed to validate or decode request", "err", err)
+		writeErrorResponse(c, http.StatusBadRequest, ErrCodeInvalidFormat, "Invalid request payload")
+		return
+	}
+
+	absence, err := h.userService.CreateAbsence(ctx, &models.Absence{
+		UserID:   req.UserID,
+		StartsAt: req.StartsAt,
+		EndsAt:   req.EndsAt,
+		Reason:   req.Reason,
+	})
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			writeErrorResponse(c, http.StatusNotFound, ErrCodeNotFound, "User not found")
+		} else if errors.Is(err, models.ErrInvalidAbsence) {
+			writeErrorResponse(c, http.StatusBadRequest, ErrCodeInvalidFormat, "Invalid absence period")
+		} else {
+			writeErrorResponse(c, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"absence": absence})
+}
+
+// GET /users/absences
+func (h *UserHandler) ListAbsences(c *gin.Context) {
+	userID := c.Query("user_id")
+	ctx := c.Request.Context()
+
+	if userID == "" {
+		writeErrorResponse(c, http.StatusBadRequest, ErrCodeInvalidFormat, "user_id is required")
+		return
+	}
+
+	absences, err := h.userService.ListAbsences(ctx, userID)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			writeErrorResponse(c, http.StatusNotFound, ErrCodeNotFound, "User not found")
+		} else {
+			writeErrorResponse(c, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user_id":  userID,
+		"absences": absences,
+	})
+}
+
+// POST /users/absences/cancel
+func (h *UserHandler) CancelAbsence(c *gin.Context) {
+	var req requests.CancelAbsenceReq
+	ctx := c.Request.Context()
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.WarnContext(ctx, "failed to validate or decode request", "err", err)
+		writeErrorResponse(c, http.StatusBadRequest, ErrCodeInvalidFormat, "Invalid request payload")
+		return
+	}
+
+	if err := h.userService.CancelAbsence(ctx, req.ID); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			writeErrorResponse(c, http.StatusNotFound, ErrCodeNotFound, "Absence not found")
+		} else {
+			writeErrorResponse(c, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"absence_id": req.ID})
+}
+
+// POST /users/absences/import
+func (h *UserHandler) ImportAbsences(c *gin.Context) {
+	ctx := c.Request.Context()
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxCalendarSize)
+
+	result, err := h.userService.ImportAbsences(ctx, body)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidCalendar) {
+			writeErrorResponse(c, http.StatusBadRequest, ErrCodeInvalidFormat, "Invalid iCalendar feed")
+		} else {
+			writeErrorResponse(c, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/services"
+)
+
+type WebhookHandler struct {
+	githubService services.GitHubWebhookServiceInterface
+	gitlabService services.GitLabWebhookServiceInterface
+	log           *slog.Logger
+}
+
+func NewWebhookHandler(
+	githubService services.GitHubWebhookServiceInterface,
+	gitlabService services.GitLabWebhookServiceInterface,
+	log *slog.Logger,
+) *WebhookHandler {
+	return &WebhookHandler{
+		githubService: githubService,
+		gitlabService: gitlabService,
+		log:           log,
+	}
+}
+
+// POST /webhooks/github
+func (h *WebhookHandler) GitHub(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	payload, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		h.log.WarnContext(ctx, "failed to read webhook body", "err", err)
+		writeErrorResponse(c, http.StatusBadRequest, ErrCodeInvalidFormat, "Invalid request payload")
+		return
+	}
+
+	if !h.githubService.VerifySignature(payload, c.GetHeader("X-Hub-Signature-256")) {
+		h.log.WarnContext(ctx, "invalid github signature", "delivery", c.GetHeader("X-GitHub-Delivery"))
+		writeErrorResponse(c, http.StatusUnauthorized, ErrCodeInvalidSignature, "Invalid signature")
+		return
+	}
+
+	handled, err := h.githubService.HandleEvent(ctx, c.GetHeader("X-GitHub-Event"), payload)
+	if err != nil {
+		writeWebhookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"handled": handled})
+}
+
+// POST /webhooks/gitlab
+func (h *WebhookHandler) GitLab(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	if !h.gitlabService.VerifyToken(c.GetHeader("X-Gitlab-Token")) {
+		h.log.WarnContext(ctx, "invalid gitlab token", "event_uuid", c.GetHeader("X-Gitlab-Event-UUID"))
+		writeErrorResponse(c, http.StatusUnauthorized, ErrCodeInvalidSignature, "Invalid token")
+		return
+	}
+
+	payload, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		h.log.WarnContext(ctx, "failed to read webhook body", "err", err)
+		writeErrorResponse(c, http.StatusBadRequest, ErrCodeInvalidFormat, "Invalid request payload")
+		return
+	}
+
+	handled, err := h.gitlabService.HandleEvent(ctx,
+		c.GetHeader("X-Gitlab-Event"), c.GetHeader("X-Gitlab-Event-UUID"), payload)
+	if err != nil {
+		writeWebhookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"handled": handled})
+}
+
+func writeWebhookError(c *gin.Context, err error) {
+	if errors.Is(err, models.ErrInvalidPayload) {
+		writeErrorResponse(c, http.StatusBadRequest, ErrCodeInvalidFormat, "Invalid webhook payload")
+	} else if errors.Is(err, models.ErrNotFound) {
+		writeErrorResponse(c, http.StatusNotFound, ErrCodeNotFound, "PR or author not found")
+	} else if errors.Is(err, models.ErrPRMerged) {
+		writeErrorResponse(c, http.StatusConflict, ErrCodePRMerged, "cannot change merged PR")
+	} else if errors.Is(err, models.ErrNotEnoughReviewers) {
+		writeErrorResponse(c, http.StatusConflict, ErrCodeNotEnoughReviewers, "not enough active reviewers in team")
+	} else if errors.Is(err, models.ErrNotApproved) {
+		writeErrorResponse(c, http.StatusConflict, ErrCodeNotApproved, "PR does not have enough approvals")
+	} else {
+		writeErrorResponse(c, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+	}
+}
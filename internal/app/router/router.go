@@ -8,10 +8,14 @@ import (
 )
 
 type Router struct {
-	userHandler  *handlers.UserHandler
-	teamHandler  *handlers.TeamHandler
-	prHandler    *handlers.PullRequestHandler
-	statsHandler *handlers.StatsHandler
+	userHandler         *handlers.UserHandler
+	teamHandler         *handlers.TeamHandler
+	prHandler           *handlers.PullRequestHandler
+	statsHandler        *handlers.StatsHandler
+	webhookHandler      *handlers.WebhookHandler
+	notificationHandler *handlers.NotificationHandler
+	codeOwnerHandler    *handlers.CodeOwnerHandler
+	repositoryHandler   *handlers.RepositoryHandler
 }
 
 func NewRouter(
@@ -19,12 +23,20 @@ func NewRouter(
 	teamHandler *handlers.TeamHandler,
 	prHandler *handlers.PullRequestHandler,
 	statsHandler *handlers.StatsHandler,
+	webhookHandler *handlers.WebhookHandler,
+	notificationHandler *handlers.NotificationHandler,
+	codeOwnerHandler *handlers.CodeOwnerHandler,
+	repositoryHandler *handlers.RepositoryHandler,
 ) *Router {
 	return &Router{
-		userHandler:  userHandler,
-		teamHandler:  teamHandler,
-		prHandler:    prHandler,
-		statsHandler: statsHandler,
+		userHandler:         userHandler,
+		teamHandler:         teamHandler,
+		prHandler:           prHandler,
+		statsHandler:        statsHandler,
+		webhookHandler:      webhookHandler,
+		notificationHandler: notificationHandler,
+		codeOwnerHandler:    codeOwnerHandler,
+		repositoryHandler:   repositoryHandler,
 	}
 }
 
@@ -43,6 +55,20 @@ func (r *Router) InitRoutes() *gin.Engine {
 			users.GET("/getReview", r.userHandler.GetReview)
 
 			users.POST("/deactivate", r.userHandler.DeactivateUsers)
+
+			users.POST("/linkIdentity", r.userHandler.LinkIdentity)
+
+			users.POST("/setChatHandle", r.userHandler.SetChatHandle)
+
+			users.POST("/setQuietHours", r.userHandler.SetQuietHours)
+
+			users.POST("/absences", r.userHandler.CreateAbsence)
+
+			users.GET("/absences", r.userHandler.ListAbsences)
+
+			users.POST("/absences/cancel", r.userHandler.CancelAbsence)
+
+			users.POST("/absences/import", r.userHandler.ImportAbsences)
 		}
 
 		teams := api.Group("/team")
@@ -50,6 +76,19 @@ func (r *Router) InitRoutes() *gin.Engine {
 			teams.POST("/add", r.teamHandler.AddTeam)
 
 			teams.GET("/get", r.teamHandler.GetTeam)
+
+			teams.POST("/settings", r.teamHandler.SetSettings)
+		}
+
+		repositories := api.Group("/repositories")
+		{
+			repositories.POST("/add", r.repositoryHandler.AddRepository)
+
+			repositories.POST("/update", r.repositoryHandler.UpdateRepository)
+
+			repositories.GET("/get", r.repositoryHandler.GetRepository)
+
+			repositories.GET("/list", r.repositoryHandler.ListRepositories)
 		}
 
 		prs := api.Group("/pullRequest")
@@ -58,7 +97,52 @@ func (r *Router) InitRoutes() *gin.Engine {
 
 			prs.POST("/reassign", r.prHandler.ReassignPR)
 
+			prs.POST("/reviewers/add", r.prHandler.AddReviewer)
+
+			prs.POST("/reviewers/remove", r.prHandler.RemoveReviewer)
+
 			prs.POST("/merge", r.prHandler.MergePullRequest)
+
+			prs.POST("/close", r.prHandler.ClosePullRequest)
+
+			prs.POST("/reopen", r.prHandler.ReopenPullRequest)
+
+			prs.POST("/ready", r.prHandler.MarkReady)
+
+			prs.POST("/review", r.prHandler.SubmitReview)
+
+			prs.GET("/reviews", r.prHandler.GetReviews)
+
+			prs.GET("/overdue", r.prHandler.GetOverdue)
+		}
+
+		webhooks := api.Group("/webhooks")
+		{
+			webhooks.POST("/github", r.webhookHandler.GitHub)
+
+			webhooks.POST("/gitlab", r.webhookHandler.GitLab)
+		}
+
+		notifications := api.Group("/notifications")
+		{
+			notifications.POST("/subscriptions", r.notificationHandler.CreateSubscription)
+
+			notifications.GET("/subscriptions", r.notificationHandler.ListSubscriptions)
+
+			notifications.POST("/subscriptions/delete", r.notificationHandler.DeleteSubscription)
+
+			notifications.GET("/deadLetters", r.notificationHandler.ListDeadLetters)
+
+			notifications.POST("/deadLetters/replay", r.notificationHandler.ReplayDeadLetter)
+		}
+
+		codeOwners := api.Group("/codeOwners")
+		{
+			codeOwners.GET("/rules", r.codeOwnerHandler.GetRules)
+
+			codeOwners.POST("/rules", r.codeOwnerHandler.SetRules)
+
+			codeOwners.POST("/import", r.codeOwnerHandler.Import)
 		}
 
 		router.GET("/stats", r.statsHandler.GetStats)
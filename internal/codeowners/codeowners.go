@@ -0,0 +1,109 @@
+// Package codeowners resolves owners of file paths from CODEOWNERS-style rules:
+// the last rule matching a path wins.
+package codeowners
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+)
+
+// Compile turns a gitignore-style pattern into a regexp over slash-separated paths.
+// A pattern with a leading or inner "/" is anchored at the repository root, one
+// without matches at any depth; a trailing "/" only matches directories. "*" and
+// "?" do not cross "/", "**" does. A pattern whose last segment is a plain name
+// also matches everything below it, one with a wildcard there only matches at
+// that level, so "docs/*" owns docs/a.md but not docs/a/b.md.
+func Compile(pattern string) (*regexp.Regexp, error) {
+	p := strings.TrimSpace(pattern)
+	if p == "" || p == "/" {
+		return nil, fmt.Errorf("%w: empty pattern", models.ErrInvalidCodeOwners)
+	}
+
+	dirOnly := strings.HasSuffix(p, "/")
+	p = strings.TrimSuffix(p, "/")
+	anchored := strings.Contains(p, "/")
+	p = strings.TrimPrefix(p, "/")
+	last := p[strings.LastIndex(p, "/")+1:]
+
+	var b strings.Builder
+	if anchored {
+		b.WriteString("^")
+	} else {
+		b.WriteString("^(?:.*/)?")
+	}
+	for i := 0; i < len(p); i++ {
+		switch {
+		case strings.HasPrefix(p[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(p[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case p[i] == '*':
+			b.WriteString("[^/]*")
+		case p[i] == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(p[i : i+1]))
+		}
+	}
+	switch {
+	case dirOnly:
+		b.WriteString("/.*$")
+	case strings.ContainsAny(last, "*?"):
+		b.WriteString("$")
+	default:
+		b.WriteString("(?:/.*)?$")
+	}
+
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q: %v", models.ErrInvalidCodeOwners, pattern, err)
+	}
+	return re, nil
+}
+
+// Owners returns users and teams owning any of paths, in rule order without duplicates.
+func Owners(rules []models.CodeOwnerRule, paths []string) (users, teams []string, err error) {
+	compiled := make([]*regexp.Regexp, len(rules))
+	for i, rule := range rules {
+		if compiled[i], err = Compile(rule.Pattern); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	owning := make(map[int]bool)
+	for _, path := range paths {
+		path = strings.TrimPrefix(path, "/")
+		for i := len(rules) - 1; i >= 0; i-- {
+			if compiled[i].MatchString(path) {
+				owning[i] = true
+				break
+			}
+		}
+	}
+
+	seenUsers := make(map[string]bool)
+	seenTeams := make(map[string]bool)
+	for i, rule := range rules {
+		if !owning[i] {
+			continue
+		}
+		for _, u := range rule.Users {
+			if !seenUsers[u] {
+				seenUsers[u] = true
+				users = append(users, u)
+			}
+		}
+		for _, t := range rule.Teams {
+			if !seenTeams[t] {
+				seenTeams[t] = true
+				teams = append(teams, t)
+			}
+		}
+	}
+	return users, teams, nil
+}
@@ -0,0 +1,108 @@
+package codeowners
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+)
+
+func TestCompile_Simple(t *testing.T) {
+	cases := []struct {
+		pattern string
+		path    string
+		match   bool
+	}{
+		{"*.go", "main.go", true},
+		{"*.go", "internal/app/main.go", true},
+		{"*.go", "main.go.txt", false},
+		{"/docs/", "docs/readme.md", true},
+		{"/docs/", "api/docs/readme.md", false},
+		{"docs/", "docs", false},
+		{"docs", "api/docs/readme.md", true},
+		{"internal/*.go", "internal/a.go", true},
+		{"internal/*.go", "internal/app/a.go", false},
+		{"docs/*", "docs/a.md", true},
+		{"docs/*", "docs/a/b.md", false},
+		{"/build/log?", "build/log1/out.txt", false},
+		{"src/app", "src/app/main.go", true},
+		{"internal/**/*.sql", "internal/a/b/c.sql", true},
+		{"internal/**/*.sql", "internal/c.sql", true},
+		{"/migrations/**", "migrations/001.sql", true},
+		{"file?.txt", "file1.txt", true},
+		{"file?.txt", "file10.txt", false},
+	}
+	for _, tc := range cases {
+		re, err := Compile(tc.pattern)
+		if err != nil {
+			t.Fatalf("Unexpected error for %q: %v", tc.pattern, err)
+		}
+		if got := re.MatchString(tc.path); got != tc.match {
+			t.Errorf("%q against %q: expected %v, got %v", tc.pattern, tc.path, tc.match, got)
+		}
+	}
+
+	if _, err := Compile(" "); !errors.Is(err, models.ErrInvalidCodeOwners) {
+		t.Errorf("Expected ErrInvalidCodeOwners, got %v", err)
+	}
+}
+
+func TestOwners_Simple(t *testing.T) {
+	rules := []models.CodeOwnerRule{
+		{Pattern: "*", Teams: []string{"backend"}},
+		{Pattern: "*.sql", Users: []string{"dba"}},
+		{Pattern: "/web/", Teams: []string{"frontend"}, Users: []string{"lead"}},
+		{Pattern: "/web/vendor/"},
+	}
+
+	t.Run("Last match wins", func(t *testing.T) {
+		users, teams, _ := Owners(rules, []string{"web/app.js", "migrations/1.sql"})
+		if !slices.Equal(users, []string{"dba", "lead"}) || !slices.Equal(teams, []string{"frontend"}) {
+			t.Errorf("Unexpected owners: %v %v", users, teams)
+		}
+	})
+
+	t.Run("Rule without owners unowns paths", func(t *testing.T) {
+		users, teams, _ := Owners(rules, []string{"web/vendor/lib.js"})
+		if len(users) != 0 || len(teams) != 0 {
+			t.Errorf("Expected no owners, got %v %v", users, teams)
+		}
+	})
+}
+
+func TestParse_Simple(t *testing.T) {
+	file := `# Global owners
+*       @acme/backend
+
+[Database][2] @dba-lead
+*.sql   @alice bob@example.com # migrations
+/docs/My\ Guide.md @writer
+/db/
+^[Optional]
+/web/   @acme/frontend
+/vendor/
+`
+	entries, err := Parse(strings.NewReader(file))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(entries) != 6 {
+		t.Fatalf("Expected 6 entries, got %+v", entries)
+	}
+
+	sql := entries[1]
+	if sql.Line != 5 || sql.Pattern != "*.sql" || !slices.Equal(sql.Owners, []string{"@alice", "bob@example.com"}) {
+		t.Errorf("Unexpected entry: %+v", sql)
+	}
+	if entries[2].Pattern != "/docs/My Guide.md" {
+		t.Errorf("Expected escaped space in pattern, got %q", entries[2].Pattern)
+	}
+	if db := entries[3]; db.Pattern != "/db/" || !slices.Equal(db.Owners, []string{"@dba-lead"}) {
+		t.Errorf("Expected section default owners, got %+v", db)
+	}
+	if last := entries[5]; last.Pattern != "/vendor/" || len(last.Owners) != 0 {
+		t.Errorf("Unexpected entry: %+v", last)
+	}
+}
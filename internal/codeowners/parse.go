@@ -0,0 +1,100 @@
+package codeowners
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Entry is one rule line of a CODEOWNERS file with owners as written:
+// "@login", "@org/team" or an email address.
+type Entry struct {
+	Line    int
+	Pattern string
+	Owners  []string
+}
+
+// Parse reads GitHub and GitLab CODEOWNERS files. Default owners of a GitLab
+// section header ("[Section]", "^[Section][2] @owner") are given to the section's
+// entries without owners of their own. Rules of all sections are evaluated
+// together with last match wins.
+func Parse(r io.Reader) ([]Entry, error) {
+	var (
+		entries  []Entry
+		defaults []string
+	)
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = line[:i]
+		}
+		if isSection(line) {
+			defaults = sectionOwners(line)
+			continue
+		}
+
+		fields := splitFields(line)
+		owners := fields[1:]
+		if len(owners) == 0 {
+			owners = defaults
+		}
+		entries = append(entries, Entry{
+			Line:    n,
+			Pattern: strings.ReplaceAll(fields[0], `\#`, "#"),
+			Owners:  owners,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read CODEOWNERS: %w", err)
+	}
+	return entries, nil
+}
+
+func isSection(line string) bool {
+	line = strings.TrimPrefix(line, "^")
+	return strings.HasPrefix(line, "[") && strings.Contains(line, "]")
+}
+
+// sectionOwners returns the default owners written after a section header's name
+// and optional approval count.
+func sectionOwners(line string) []string {
+	rest := strings.TrimPrefix(line, "^")
+	rest = rest[strings.Index(rest, "]")+1:]
+	if strings.HasPrefix(rest, "[") {
+		if i := strings.Index(rest, "]"); i >= 0 {
+			rest = rest[i+1:]
+		}
+	}
+	return splitFields(rest)
+}
+
+// splitFields splits on whitespace, keeping "\ " escaped spaces inside the pattern.
+func splitFields(line string) []string {
+	var (
+		fields  []string
+		current strings.Builder
+	)
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == ' ':
+			current.WriteByte(' ')
+			i++
+		case line[i] == ' ' || line[i] == '\t':
+			if current.Len() > 0 {
+				fields = append(fields, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteByte(line[i])
+		}
+	}
+	if current.Len() > 0 {
+		fields = append(fields, current.String())
+	}
+	return fields
+}
@@ -4,6 +4,14 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	StoragePostgres = "postgres"
+	StorageMemory   = "memory"
+	StorageSQLite   = "sqlite"
 )
 
 type (
@@ -12,6 +20,13 @@ type (
 		LogLevel string
 	}
 
+	StorageConfig struct {
+		// Driver is "postgres", "sqlite" or "memory"; the memory store loses all data on exit.
+		Driver string
+		// SQLitePath is the database file of the sqlite driver.
+		SQLitePath string
+	}
+
 	PostgresConfig struct {
 		Host     string
 		Port     string
@@ -20,9 +35,36 @@ type (
 		DB       string
 	}
 
+	ReviewerConfig struct {
+		Strategy       string
+		TeamStrategies string
+	}
+
+	WebhookConfig struct {
+		GitHubSecret string
+		GitLabToken  string
+	}
+
+	NotifierConfig struct {
+		MaxAttempts  int
+		BaseBackoff  time.Duration
+		PollInterval time.Duration
+	}
+
+	SchedulerConfig struct {
+		SLAInterval     time.Duration
+		DigestInterval  time.Duration
+		AbsenceInterval time.Duration
+	}
+
 	Config struct {
-		Server   ServerConfig
-		Postgres PostgresConfig
+		Server    ServerConfig
+		Storage   StorageConfig
+		Postgres  PostgresConfig
+		Reviewer  ReviewerConfig
+		Webhook   WebhookConfig
+		Notifier  NotifierConfig
+		Scheduler SchedulerConfig
 	}
 )
 
@@ -38,17 +80,80 @@ func Load() (*Config, error) {
 		return val
 	}
 
-	cfg := &Config{
-		Server: ServerConfig{
-			Port:     required("SERVER_PORT"),
-			LogLevel: os.Getenv("LOG_LEVEL"),
-		},
-		Postgres: PostgresConfig{
+	intOr := func(key string, def int) int {
+		val := os.Getenv(key)
+		if val == "" {
+			return def
+		}
+		n, err := strconv.Atoi(val)
+		if err != nil || n <= 0 {
+			errs = append(errs, fmt.Errorf("environment variable %q must be a positive integer", key))
+		}
+		return n
+	}
+
+	durationOr := func(key string, def time.Duration) time.Duration {
+		val := os.Getenv(key)
+		if val == "" {
+			return def
+		}
+		d, err := time.ParseDuration(val)
+		if err != nil || d <= 0 {
+			errs = append(errs, fmt.Errorf("environment variable %q must be a positive duration", key))
+		}
+		return d
+	}
+
+	storage := StorageConfig{Driver: os.Getenv("STORAGE")}
+	if storage.Driver == "" {
+		storage.Driver = StoragePostgres
+	}
+
+	var postgres PostgresConfig
+	switch storage.Driver {
+	case StoragePostgres:
+		postgres = PostgresConfig{
 			Host:     required("POSTGRES_HOST"),
 			Port:     required("POSTGRES_PORT"),
 			User:     required("POSTGRES_USER"),
 			Password: required("POSTGRES_PASSWORD"),
 			DB:       required("POSTGRES_DB"),
+		}
+	case StorageSQLite:
+		storage.SQLitePath = os.Getenv("SQLITE_PATH")
+		if storage.SQLitePath == "" {
+			storage.SQLitePath = "reviewer.db"
+		}
+	case StorageMemory:
+	default:
+		errs = append(errs, fmt.Errorf("environment variable %q must be %q, %q or %q",
+			"STORAGE", StoragePostgres, StorageSQLite, StorageMemory))
+	}
+
+	cfg := &Config{
+		Server: ServerConfig{
+			Port:     required("SERVER_PORT"),
+			LogLevel: os.Getenv("LOG_LEVEL"),
+		},
+		Storage:  storage,
+		Postgres: postgres,
+		Reviewer: ReviewerConfig{
+			Strategy:       os.Getenv("REVIEWER_STRATEGY"),
+			TeamStrategies: os.Getenv("REVIEWER_TEAM_STRATEGIES"),
+		},
+		Webhook: WebhookConfig{
+			GitHubSecret: os.Getenv("GITHUB_WEBHOOK_SECRET"),
+			GitLabToken:  os.Getenv("GITLAB_WEBHOOK_TOKEN"),
+		},
+		Notifier: NotifierConfig{
+			MaxAttempts:  intOr("NOTIFIER_MAX_ATTEMPTS", 5),
+			BaseBackoff:  durationOr("NOTIFIER_BASE_BACKOFF", 10*time.Second),
+			PollInterval: durationOr("NOTIFIER_POLL_INTERVAL", time.Second),
+		},
+		Scheduler: SchedulerConfig{
+			SLAInterval:     durationOr("SCHEDULER_SLA_INTERVAL", time.Minute),
+			DigestInterval:  durationOr("SCHEDULER_DIGEST_INTERVAL", 15*time.Minute),
+			AbsenceInterval: durationOr("SCHEDULER_ABSENCE_INTERVAL", time.Minute),
 		},
 	}
 
@@ -56,6 +161,10 @@ func Load() (*Config, error) {
 		cfg.Server.LogLevel = "info"
 	}
 
+	if cfg.Reviewer.Strategy == "" {
+		cfg.Reviewer.Strategy = "random"
+	}
+
 	if len(errs) > 0 {
 		return nil, errors.Join(errs...)
 	}
@@ -0,0 +1,349 @@
+// Package ical reads the VEVENT entries of an iCalendar (RFC 5545) feed. Only
+// the properties needed to import out-of-office periods are parsed.
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidCalendar = errors.New("invalid iCalendar feed")
+
+type Event struct {
+	UID       string
+	Summary   string
+	Status    string
+	Start     time.Time
+	End       time.Time
+	AllDay    bool
+	Organizer string
+	Attendees []string
+	// Err tells why the event could not be read. Such an event holds only the
+	// properties parsed before the error.
+	Err error
+
+	duration string
+}
+
+func (e Event) Cancelled() bool {
+	return strings.EqualFold(e.Status, "CANCELLED")
+}
+
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// Parse returns the events of the feed. A malformed feed is an error, while an
+// event with a bad or missing property is returned with Err set, so one broken
+// entry doesn't hide the rest of the calendar.
+func Parse(r io.Reader) ([]Event, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		events   []Event
+		current  *Event
+		depth    int
+		calendar bool
+	)
+	for n, line := range lines {
+		if line == "" {
+			continue
+		}
+		prop, err := parseLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidCalendar, n+1, err)
+		}
+
+		switch prop.name {
+		case "BEGIN":
+			if strings.EqualFold(prop.value, "VCALENDAR") {
+				calendar = true
+			} else if strings.EqualFold(prop.value, "VEVENT") && current == nil {
+				current = &Event{}
+				depth = 0
+			} else if current != nil {
+				depth++
+			}
+			continue
+		case "END":
+			if current == nil {
+				continue
+			}
+			if depth > 0 {
+				depth--
+				continue
+			}
+			if strings.EqualFold(prop.value, "VEVENT") {
+				if current.Err == nil {
+					current.Err = finish(current)
+				}
+				events = append(events, *current)
+				current = nil
+			}
+			continue
+		}
+
+		if current == nil || depth > 0 || current.Err != nil {
+			continue
+		}
+		if err := apply(current, prop); err != nil {
+			current.Err = fmt.Errorf("line %d: %v", n+1, err)
+		}
+	}
+
+	if !calendar {
+		return nil, fmt.Errorf("%w: no VCALENDAR", ErrInvalidCalendar)
+	}
+	if current != nil {
+		return nil, fmt.Errorf("%w: unterminated VEVENT", ErrInvalidCalendar)
+	}
+	return events, nil
+}
+
+// unfold joins continuation lines, which start with a space or a tab.
+func unfold(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read calendar: %w", err)
+	}
+	return lines, nil
+}
+
+// parseLine splits "NAME;PARAM=VALUE:value", honouring quoted parameter values.
+func parseLine(line string) (property, error) {
+	colon := -1
+	quoted := false
+	for i, c := range line {
+		if c == '"' {
+			quoted = !quoted
+		} else if c == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return property{}, errors.New("missing ':'")
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	prop := property{
+		name:   strings.ToUpper(parts[0]),
+		params: make(map[string]string),
+		value:  line[colon+1:],
+	}
+	for _, p := range parts[1:] {
+		key, value, _ := strings.Cut(p, "=")
+		prop.params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+	}
+	return prop, nil
+}
+
+func apply(e *Event, prop property) error {
+	var err error
+	switch prop.name {
+	case "UID":
+		e.UID = prop.value
+	case "SUMMARY":
+		e.Summary = unescape(prop.value)
+	case "STATUS":
+		e.Status = strings.ToUpper(prop.value)
+	case "DTSTART":
+		e.Start, e.AllDay, err = parseTime(prop)
+	case "DTEND":
+		e.End, _, err = parseTime(prop)
+	case "DURATION":
+		e.duration = prop.value
+	case "ORGANIZER":
+		e.Organizer = address(prop.value)
+	case "ATTENDEE":
+		e.Attendees = append(e.Attendees, address(prop.value))
+	}
+	return err
+}
+
+func finish(e *Event) error {
+	if e.UID == "" {
+		return errors.New("missing UID")
+	}
+	if e.Start.IsZero() {
+		return errors.New("missing DTSTART")
+	}
+	if e.End.IsZero() {
+		switch {
+		case e.duration != "":
+			end, err := addDuration(e.Start, e.duration)
+			if err != nil {
+				return err
+			}
+			e.End = end
+		case e.AllDay:
+			e.End = e.Start.AddDate(0, 0, 1)
+		default:
+			return errors.New("missing DTEND")
+		}
+	}
+	return nil
+}
+
+// addDuration adds an RFC 5545 duration such as P1W, P2DT4H or PT30M to start.
+// Weeks and days are nominal, so they keep the wall clock across DST changes.
+func addDuration(start time.Time, value string) (time.Time, error) {
+	invalid := fmt.Errorf("invalid DURATION %q", value)
+
+	rest := strings.TrimPrefix(value, "+")
+	sign := 1
+	if after, ok := strings.CutPrefix(rest, "-"); ok {
+		sign, rest = -1, after
+	}
+	rest, ok := strings.CutPrefix(rest, "P")
+	if !ok || rest == "" {
+		return time.Time{}, invalid
+	}
+
+	var (
+		days   int
+		clock  time.Duration
+		inTime bool
+	)
+	for rest != "" {
+		if rest[0] == 'T' && !inTime {
+			inTime, rest = true, rest[1:]
+			continue
+		}
+		i := 0
+		for i < len(rest) && rest[i] >= '0' && rest[i] <= '9' {
+			i++
+		}
+		if i == 0 || i == len(rest) {
+			return time.Time{}, invalid
+		}
+		n, err := strconv.Atoi(rest[:i])
+		if err != nil {
+			return time.Time{}, invalid
+		}
+		switch unit := rest[i]; {
+		case !inTime && unit == 'W':
+			days += 7 * n
+		case !inTime && unit == 'D':
+			days += n
+		case inTime && unit == 'H':
+			clock += time.Duration(n) * time.Hour
+		case inTime && unit == 'M':
+			clock += time.Duration(n) * time.Minute
+		case inTime && unit == 'S':
+			clock += time.Duration(n) * time.Second
+		default:
+			return time.Time{}, invalid
+		}
+		rest = rest[i+1:]
+	}
+	return start.AddDate(0, 0, sign*days).Add(time.Duration(sign) * clock), nil
+}
+
+func parseTime(prop property) (time.Time, bool, error) {
+	loc := time.UTC
+	if tzid := prop.params["TZID"]; tzid != "" {
+		var err error
+		if loc, err = location(tzid); err != nil {
+			return time.Time{}, false, err
+		}
+	}
+
+	value := prop.value
+	switch {
+	case prop.params["VALUE"] == "DATE" || len(value) == len("20060102"):
+		t, err := time.ParseInLocation("20060102", value, loc)
+		return t, true, err
+	case strings.HasSuffix(value, "Z"):
+		t, err := time.Parse("20060102T150405Z", value)
+		return t, false, err
+	default:
+		t, err := time.ParseInLocation("20060102T150405", value, loc)
+		return t, false, err
+	}
+}
+
+// location resolves a TZID given as an IANA name or as a Windows time zone name,
+// which Outlook and Exchange use.
+func location(tzid string) (*time.Location, error) {
+	if loc, err := time.LoadLocation(tzid); err == nil {
+		return loc, nil
+	}
+	if name, ok := windowsZones[tzid]; ok {
+		return time.LoadLocation(name)
+	}
+	return nil, fmt.Errorf("unknown TZID %q", tzid)
+}
+
+// windowsZones maps common Windows time zone names to IANA names, following the
+// CLDR windowsZones table.
+var windowsZones = map[string]string{
+	"UTC":                            "UTC",
+	"GMT Standard Time":              "Europe/London",
+	"Greenwich Standard Time":        "Atlantic/Reykjavik",
+	"W. Europe Standard Time":        "Europe/Berlin",
+	"Central Europe Standard Time":   "Europe/Budapest",
+	"Central European Standard Time": "Europe/Warsaw",
+	"Romance Standard Time":          "Europe/Paris",
+	"E. Europe Standard Time":        "Europe/Chisinau",
+	"FLE Standard Time":              "Europe/Kiev",
+	"GTB Standard Time":              "Europe/Bucharest",
+	"Kaliningrad Standard Time":      "Europe/Kaliningrad",
+	"Belarus Standard Time":          "Europe/Minsk",
+	"Russian Standard Time":          "Europe/Moscow",
+	"Russia Time Zone 3":             "Europe/Samara",
+	"Turkey Standard Time":           "Europe/Istanbul",
+	"Israel Standard Time":           "Asia/Jerusalem",
+	"Egypt Standard Time":            "Africa/Cairo",
+	"South Africa Standard Time":     "Africa/Johannesburg",
+	"Arabian Standard Time":          "Asia/Dubai",
+	"Ekaterinburg Standard Time":     "Asia/Yekaterinburg",
+	"India Standard Time":            "Asia/Calcutta",
+	"N. Central Asia Standard Time":  "Asia/Novosibirsk",
+	"China Standard Time":            "Asia/Shanghai",
+	"Singapore Standard Time":        "Asia/Singapore",
+	"Tokyo Standard Time":            "Asia/Tokyo",
+	"Korea Standard Time":            "Asia/Seoul",
+	"AUS Eastern Standard Time":      "Australia/Sydney",
+	"New Zealand Standard Time":      "Pacific/Auckland",
+	"E. South America Standard Time": "America/Sao_Paulo",
+	"Eastern Standard Time":          "America/New_York",
+	"Central Standard Time":          "America/Chicago",
+	"Mountain Standard Time":         "America/Denver",
+	"US Mountain Standard Time":      "America/Phoenix",
+	"Pacific Standard Time":          "America/Los_Angeles",
+	"Alaskan Standard Time":          "America/Anchorage",
+	"Hawaiian Standard Time":         "Pacific/Honolulu",
+}
+
+// address strips the mailto: scheme of a calendar user address.
+func address(value string) string {
+	if len(value) >= len("mailto:") && strings.EqualFold(value[:len("mailto:")], "mailto:") {
+		value = value[len("mailto:"):]
+	}
+	return strings.TrimSpace(value)
+}
+
+func unescape(value string) string {
+	return strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(value)
+}
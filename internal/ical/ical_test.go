@@ -0,0 +1,129 @@
+package ical
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const feed = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:vac-1@hr\r\n" +
+	"SUMMARY:Vacation\\, Bob\r\n" +
+	"DTSTART;VALUE=DATE:20250701\r\n" +
+	"DTEND;VALUE=DATE:20250715\r\n" +
+	"ATTENDEE;CN=\"Bob: backend\":mailto:Bob@Example.com\r\n" +
+	"BEGIN:VALARM\r\n" +
+	"DTSTART:20250630T090000Z\r\n" +
+	"END:VALARM\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:sick-2@hr\r\n" +
+	"DTSTART;TZID=Europe/Moscow:20250801T090000\r\n" +
+	"DTEND:20250801T\r\n" +
+	" 180000Z\r\n" +
+	"ORGANIZER:u3\r\n" +
+	"STATUS:CANCELLED\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestParse_Simple(t *testing.T) {
+	t.Run("Events", func(t *testing.T) {
+		events, err := Parse(strings.NewReader(feed))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(events) != 2 {
+			t.Fatalf("Expected 2 events, got %d", len(events))
+		}
+
+		vac := events[0]
+		if vac.UID != "vac-1@hr" || vac.Summary != "Vacation, Bob" || !vac.AllDay {
+			t.Errorf("Unexpected event: %+v", vac)
+		}
+		if !vac.Start.Equal(time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)) ||
+			!vac.End.Equal(time.Date(2025, 7, 15, 0, 0, 0, 0, time.UTC)) {
+			t.Errorf("Unexpected period: %v - %v", vac.Start, vac.End)
+		}
+		if len(vac.Attendees) != 1 || vac.Attendees[0] != "Bob@Example.com" {
+			t.Errorf("Unexpected attendees: %v", vac.Attendees)
+		}
+
+		sick := events[1]
+		if !sick.Start.Equal(time.Date(2025, 8, 1, 6, 0, 0, 0, time.UTC)) ||
+			!sick.End.Equal(time.Date(2025, 8, 1, 18, 0, 0, 0, time.UTC)) {
+			t.Errorf("Unexpected period: %v - %v", sick.Start, sick.End)
+		}
+		if sick.Organizer != "u3" || !sick.Cancelled() {
+			t.Errorf("Unexpected event: %+v", sick)
+		}
+	})
+
+	t.Run("Durations and Windows time zones", func(t *testing.T) {
+		input := "BEGIN:VCALENDAR\n" +
+			"BEGIN:VEVENT\nUID:1\nDTSTART:20250101T090000Z\nDURATION:P1DT2H30M\nEND:VEVENT\n" +
+			"BEGIN:VEVENT\nUID:2\nDTSTART;VALUE=DATE:20250101\nDURATION:P2W\nEND:VEVENT\n" +
+			"BEGIN:VEVENT\nUID:3\nDTSTART;TZID=\"Russian Standard Time\":20250801T090000\nDTEND;TZID=\"Russian Standard Time\":20250801T180000\nEND:VEVENT\n" +
+			"END:VCALENDAR\n"
+		events, err := Parse(strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(events) != 3 {
+			t.Fatalf("Expected 3 events, got %d", len(events))
+		}
+		for _, e := range events {
+			if e.Err != nil {
+				t.Errorf("Unexpected error for %s: %v", e.UID, e.Err)
+			}
+		}
+		if want := time.Date(2025, 1, 2, 11, 30, 0, 0, time.UTC); !events[0].End.Equal(want) {
+			t.Errorf("Expected end %v, got %v", want, events[0].End)
+		}
+		if want := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC); !events[1].End.Equal(want) {
+			t.Errorf("Expected end %v, got %v", want, events[1].End)
+		}
+		if want := time.Date(2025, 8, 1, 6, 0, 0, 0, time.UTC); !events[2].Start.Equal(want) {
+			t.Errorf("Expected start %v, got %v", want, events[2].Start)
+		}
+	})
+
+	t.Run("Broken events are reported without failing the feed", func(t *testing.T) {
+		input := "BEGIN:VCALENDAR\n" +
+			"BEGIN:VEVENT\nDTSTART:20250101T000000Z\nDTEND:20250102T000000Z\nEND:VEVENT\n" +
+			"BEGIN:VEVENT\nUID:2\nDTSTART:tomorrow\nEND:VEVENT\n" +
+			"BEGIN:VEVENT\nUID:3\nDTSTART;TZID=Mars/Olympus:20250101T090000\nDURATION:PT1H\nEND:VEVENT\n" +
+			"BEGIN:VEVENT\nUID:4\nDTSTART:20250101T090000Z\nDURATION:1 hour\nEND:VEVENT\n" +
+			"BEGIN:VEVENT\nUID:5\nDTSTART:20250101T090000Z\nDTEND:20250101T100000Z\nEND:VEVENT\n" +
+			"END:VCALENDAR\n"
+		events, err := Parse(strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(events) != 5 {
+			t.Fatalf("Expected 5 events, got %d", len(events))
+		}
+		for _, e := range events[:4] {
+			if e.Err == nil {
+				t.Errorf("Expected an error for event %q, got %+v", e.UID, e)
+			}
+		}
+		if events[4].Err != nil {
+			t.Errorf("Unexpected error for the valid event: %v", events[4].Err)
+		}
+	})
+
+	t.Run("Invalid feeds", func(t *testing.T) {
+		for _, input := range []string{
+			"hello",
+			"BEGIN:VCALENDAR\nBEGIN:VEVENT\nUID:1\n",
+			"BEGIN:VCALENDAR\nBEGIN:VEVENT\nUID\nEND:VEVENT\nEND:VCALENDAR\n",
+		} {
+			if _, err := Parse(strings.NewReader(input)); !errors.Is(err, ErrInvalidCalendar) {
+				t.Errorf("Expected ErrInvalidCalendar for %q, got %v", input, err)
+			}
+		}
+	})
+}
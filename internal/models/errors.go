@@ -12,4 +12,31 @@ var (
 	ErrPRMerged     = errors.New("cannot edit merged PR")
 	ErrNoCandidates = errors.New("no candidates available")
 	ErrNotAssigned  = errors.New("reviewer is not assigned to this PR")
+	ErrPRChanged    = errors.New("PR was changed concurrently")
+
+	ErrAlreadyAssigned  = errors.New("reviewer is already assigned to this PR")
+	ErrReviewerInactive = errors.New("reviewer is not active")
+	ErrAuthorReviewer   = errors.New("author cannot review own PR")
+	ErrNotEligible      = errors.New("user cannot review this PR")
+
+	ErrInvalidSettings    = errors.New("invalid team settings")
+	ErrNotEnoughReviewers = errors.New("not enough reviewers available")
+
+	ErrInvalidVerdict = errors.New("invalid review verdict")
+	ErrNotApproved    = errors.New("PR does not have enough approvals")
+
+	ErrInvalidStatus = errors.New("operation is not allowed in current PR status")
+
+	ErrInvalidPayload = errors.New("invalid webhook payload")
+
+	ErrInvalidEventType = errors.New("unknown event type")
+
+	ErrInvalidQuietHours = errors.New("invalid quiet hours")
+
+	ErrInvalidAbsence  = errors.New("invalid absence period")
+	ErrInvalidCalendar = errors.New("invalid calendar")
+
+	ErrInvalidCodeOwners = errors.New("invalid code owner rules")
+
+	ErrInvalidReviewerPool = errors.New("reviewer pool must consist of team members")
 )
@@ -1,10 +1,65 @@
 package models
 
-import "time"
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"slices"
+	"time"
+)
 
 type Team struct {
-	Name    string       `json:"team_name"`
-	Members []TeamMember `json:"members"`
+	Name     string        `json:"team_name"`
+	Members  []TeamMember  `json:"members"`
+	Settings *TeamSettings `json:"settings,omitempty"`
+}
+
+type TeamSettings struct {
+	ReviewersCount    int `json:"reviewers_count"`
+	MinReviewers      int `json:"min_reviewers"`
+	RequiredApprovals int `json:"required_approvals"`
+	// ChatWebhookURL is a Slack-compatible incoming webhook; empty disables chat notifications.
+	ChatWebhookURL string `json:"chat_webhook_url"`
+	// ReviewSLAMinutes is the time a reviewer has for the first verdict; 0 disables SLA tracking.
+	ReviewSLAMinutes int  `json:"review_sla_minutes"`
+	SLAAutoReassign  bool `json:"sla_auto_reassign"`
+	// FallbackTeams are asked in order when the team can't fill all reviewer seats.
+	FallbackTeams []string `json:"fallback_teams"`
+}
+
+func DefaultTeamSettings() *TeamSettings {
+	return &TeamSettings{
+		ReviewersCount:    2,
+		MinReviewers:      0,
+		RequiredApprovals: 0,
+		FallbackTeams:     []string{},
+	}
+}
+
+// Repository is a service owned by a team. PRs in it draw reviewers from
+// Reviewers, or from the whole team when it's empty.
+type Repository struct {
+	ID        string   `json:"repository_id"`
+	TeamName  string   `json:"team_name"`
+	Reviewers []string `json:"reviewers"`
+	// Settings override the team's reviewer counts; nil keeps the team ones.
+	Settings *RepositorySettings `json:"settings,omitempty"`
+}
+
+type RepositorySettings struct {
+	ReviewersCount    int `json:"reviewers_count"`
+	MinReviewers      int `json:"min_reviewers"`
+	RequiredApprovals int `json:"required_approvals"`
+}
+
+// Apply returns a copy of the team settings with the repository's reviewer counts.
+func (s *RepositorySettings) Apply(team *TeamSettings) *TeamSettings {
+	merged := *team
+	if s != nil {
+		merged.ReviewersCount = s.ReviewersCount
+		merged.MinReviewers = s.MinReviewers
+		merged.RequiredApprovals = s.RequiredApprovals
+	}
+	return &merged
 }
 
 type TeamMember struct {
@@ -20,21 +75,115 @@ type User struct {
 	IsActive bool   `json:"is_active"`
 }
 
+const (
+	ProviderGitHub = "github"
+	ProviderGitLab = "gitlab"
+	// ProviderGitLabID links a user to a numeric GitLab user id; merge request
+	// hooks name the author only by id.
+	ProviderGitLabID = "gitlab_id"
+	// ProviderEmail links a user to an email address, used to match calendar imports.
+	ProviderEmail = "email"
+)
+
+type UserIdentity struct {
+	Provider string `json:"provider"`
+	Login    string `json:"login"`
+	UserID   string `json:"user_id"`
+}
+
+type PRStatus string
+
+const (
+	PRStatusDraft  PRStatus = "DRAFT"
+	PRStatusOpen   PRStatus = "OPEN"
+	PRStatusClosed PRStatus = "CLOSED"
+	PRStatusMerged PRStatus = "MERGED"
+)
+
+// ReviewersEditable returns an error unless reviewers of a PR in status s may be
+// changed by hand: it is OPEN or DRAFT.
+func (s PRStatus) ReviewersEditable() error {
+	switch s {
+	case PRStatusOpen, PRStatusDraft:
+		return nil
+	case PRStatusMerged:
+		return ErrPRMerged
+	default:
+		return ErrInvalidStatus
+	}
+}
+
+// TransitionFrom returns an error unless a PR in status s may change its status:
+// it isn't MERGED and s is one of from.
+func (s PRStatus) TransitionFrom(from []PRStatus) error {
+	if s == PRStatusMerged {
+		return ErrPRMerged
+	}
+	if !slices.Contains(from, s) {
+		return ErrInvalidStatus
+	}
+	return nil
+}
+
 type PullRequest struct {
 	ID        string     `json:"pull_request_id"`
 	Name      string     `json:"pull_request_name"`
 	AuthorID  string     `json:"author_id"`
-	Status    string     `json:"status"`
+	Status    PRStatus   `json:"status"`
 	Reviewers []string   `json:"assigned_reviewers"`
 	CreatedAt time.Time  `json:"createdAt"`
 	MergedAt  *time.Time `json:"mergedAt"`
+	Files     []string   `json:"files,omitempty"`
+	// RepositoryID names the repository, such as "acme/api"; empty if unknown.
+	RepositoryID string `json:"repository_id,omitempty"`
+	// FallbackReviewers are the reviewers drawn from fallback teams.
+	FallbackReviewers []string `json:"fallback_reviewers,omitempty"`
+}
+
+// CodeOwnerRule assigns paths matching a CODEOWNERS-style Pattern to users and
+// whole teams. A rule without owners leaves the matching paths unowned.
+type CodeOwnerRule struct {
+	Pattern string   `json:"pattern"`
+	Users   []string `json:"users"`
+	Teams   []string `json:"teams"`
+}
+
+// CodeOwnersImport is the outcome of importing a CODEOWNERS file. Rules keep only
+// the owners that were resolved; everything else is listed in Problems.
+type CodeOwnersImport struct {
+	RepositoryID string              `json:"repository_id"`
+	DryRun       bool                `json:"dry_run"`
+	Rules        []CodeOwnerRule     `json:"rules"`
+	Problems     []CodeOwnersProblem `json:"problems"`
+}
+
+type CodeOwnersProblem struct {
+	Line    int    `json:"line"`
+	Owner   string `json:"owner,omitempty"`
+	Message string `json:"message"`
 }
 
 type PullRequestShort struct {
-	ID       string `json:"pull_request_id"`
-	Name     string `json:"pull_request_name"`
-	AuthorID string `json:"author_id"`
-	Status   string `json:"status"`
+	ID         string     `json:"pull_request_id"`
+	Name       string     `json:"pull_request_name"`
+	AuthorID   string     `json:"author_id"`
+	Status     PRStatus   `json:"status"`
+	AssignedAt *time.Time `json:"assignedAt,omitempty"`
+	// FromFallback is set when the user was assigned as a fallback team member.
+	FromFallback bool `json:"from_fallback,omitempty"`
+}
+
+const (
+	VerdictApproved         = "APPROVED"
+	VerdictChangesRequested = "CHANGES_REQUESTED"
+	VerdictCommented        = "COMMENTED"
+)
+
+type Review struct {
+	PRID        string    `json:"pull_request_id"`
+	ReviewerID  string    `json:"reviewer_id"`
+	Verdict     string    `json:"verdict"`
+	SubmittedAt time.Time `json:"submittedAt"`
 }
 
 type ReviewerStat struct {
@@ -43,9 +192,159 @@ type ReviewerStat struct {
 	ReviewCount int    `json:"review_count"`
 }
 
+// OverdueReview is a reviewer assignment that missed the team review SLA.
+type OverdueReview struct {
+	PRID       string    `json:"pull_request_id"`
+	PRName     string    `json:"pull_request_name"`
+	ReviewerID string    `json:"reviewer_id"`
+	AuthorID   string    `json:"author_id"`
+	TeamName   string    `json:"team_name"`
+	AssignedAt time.Time `json:"assignedAt"`
+	DueAt      time.Time `json:"dueAt"`
+	OverdueAt  time.Time `json:"overdueAt"`
+}
+
 type ReviewToUpdate struct {
 	PRID          string
 	OldReviewerID string
 	AuthorID      string
 	TeamName      string
+	RepositoryID  string
+	// Reviewers are the reviewers of the PR when the replacement is picked,
+	// OldReviewerID included.
+	Reviewers []string
+}
+
+// ReassignedPayload describes handing the review over to newReviewerID.
+func (r ReviewToUpdate) ReassignedPayload(newReviewerID string, fromFallback bool) EventPayload {
+	payload := EventPayload{
+		PRID:          r.PRID,
+		AuthorID:      r.AuthorID,
+		OldReviewerID: r.OldReviewerID,
+		NewReviewerID: newReviewerID,
+	}
+	if fromFallback {
+		payload.FallbackReviewerIDs = []string{newReviewerID}
+	}
+	return payload
+}
+
+const (
+	EventPRCreated          = "pr.created"
+	EventReviewerAssigned   = "reviewer.assigned"
+	EventReviewerReassigned = "reviewer.reassigned"
+	EventReviewerRemoved    = "reviewer.removed"
+	EventPRMerged           = "pr.merged"
+	EventUserDeactivated    = "user.deactivated"
+	EventReviewDigest       = "review.digest"
+)
+
+type Event struct {
+	ID         string       `json:"id"`
+	Type       string       `json:"type"`
+	OccurredAt time.Time    `json:"occurred_at"`
+	Payload    EventPayload `json:"payload"`
+}
+
+// NewEvent stamps a payload with a fresh event ID and the current time.
+func NewEvent(eventType string, payload EventPayload) Event {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return Event{
+		ID:         hex.EncodeToString(id),
+		Type:       eventType,
+		OccurredAt: time.Now().UTC(),
+		Payload:    payload,
+	}
+}
+
+type EventPayload struct {
+	PRID        string   `json:"pull_request_id,omitempty"`
+	PRName      string   `json:"pull_request_name,omitempty"`
+	AuthorID    string   `json:"author_id,omitempty"`
+	ReviewerIDs []string `json:"reviewer_ids,omitempty"`
+	// FallbackReviewerIDs lists ReviewerIDs or NewReviewerID drawn from fallback teams.
+	FallbackReviewerIDs []string        `json:"fallback_reviewer_ids,omitempty"`
+	OldReviewerID       string          `json:"old_reviewer_id,omitempty"`
+	NewReviewerID       string          `json:"new_reviewer_id,omitempty"`
+	UserIDs             []string        `json:"user_ids,omitempty"`
+	ReviewerID          string          `json:"reviewer_id,omitempty"`
+	Pending             []PendingReview `json:"pending,omitempty"`
+}
+
+// PendingReview is an OPEN assignment listed in a review digest.
+type PendingReview struct {
+	PRID           string    `json:"pull_request_id"`
+	PRName         string    `json:"pull_request_name"`
+	AuthorID       string    `json:"author_id"`
+	AssignedAt     time.Time `json:"assignedAt"`
+	WaitingMinutes int       `json:"waiting_minutes"`
+}
+
+// QuietHours is a daily "HH:MM" window in Timezone when a user gets no digests.
+// Empty From and To disable it; the window may wrap over midnight.
+type QuietHours struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Timezone string `json:"timezone"`
+}
+
+// Absence is a period when the user is not picked as a reviewer. Reviews still
+// held when it starts are handed over to other team members.
+type Absence struct {
+	ID       int64     `json:"absence_id"`
+	UserID   string    `json:"user_id"`
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+	Reason   string    `json:"reason"`
+	// UID is the iCalendar event UID for imported absences.
+	UID       string    `json:"uid,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type AbsenceImportResult struct {
+	Created   int              `json:"created"`
+	Updated   int              `json:"updated"`
+	Cancelled int              `json:"cancelled"`
+	Skipped   []SkippedAbsence `json:"skipped"`
+}
+
+type SkippedAbsence struct {
+	UID    string `json:"uid"`
+	Reason string `json:"reason"`
+}
+
+type DigestRecipient struct {
+	UserID       string
+	TeamName     string
+	QuietHours   QuietHours
+	LastDigestAt *time.Time
+}
+
+type Subscription struct {
+	ID         int64     `json:"id"`
+	URL        string    `json:"url"`
+	Secret     string    `json:"-"`
+	EventTypes []string  `json:"event_types"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+const (
+	DeliveryPending   = "PENDING"
+	DeliveryDelivered = "DELIVERED"
+	DeliveryDead      = "DEAD"
+)
+
+type Delivery struct {
+	ID             int64      `json:"id"`
+	SubscriptionID int64      `json:"subscription_id"`
+	URL            string     `json:"url"`
+	Secret         string     `json:"-"`
+	Event          Event      `json:"event"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  time.Time  `json:"nextAttemptAt"`
+	LastError      string     `json:"last_error"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	DeliveredAt    *time.Time `json:"deliveredAt"`
 }
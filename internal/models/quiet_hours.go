@@ -0,0 +1,62 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+func (q QuietHours) Enabled() bool {
+	return q.From != "" || q.To != ""
+}
+
+func (q QuietHours) Validate() error {
+	if !q.Enabled() {
+		return nil
+	}
+	if _, err := parseClock(q.From); err != nil {
+		return err
+	}
+	if _, err := parseClock(q.To); err != nil {
+		return err
+	}
+	if _, err := time.LoadLocation(q.Timezone); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidQuietHours, err)
+	}
+	return nil
+}
+
+// Location returns the user's timezone, UTC if it is unset or unknown.
+func (q QuietHours) Location() *time.Location {
+	loc, err := time.LoadLocation(q.Timezone)
+	if err != nil || q.Timezone == "" {
+		return time.UTC
+	}
+	return loc
+}
+
+// Contains reports whether t falls into the quiet window.
+func (q QuietHours) Contains(t time.Time) bool {
+	if !q.Enabled() {
+		return false
+	}
+	from, errFrom := parseClock(q.From)
+	to, errTo := parseClock(q.To)
+	if errFrom != nil || errTo != nil || from == to {
+		return false
+	}
+
+	local := t.In(q.Location())
+	now := local.Hour()*60 + local.Minute()
+	if from < to {
+		return now >= from && now < to
+	}
+	return now >= from || now < to
+}
+
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q is not HH:MM", ErrInvalidQuietHours, s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
@@ -0,0 +1,164 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/repository"
+)
+
+// ChatNotifier posts assignment and merge events to the incoming webhook of the
+// author's team, and review digests to the reviewer's team. Teams without a
+// chat_webhook_url are skipped. Posting is best-effort: a failed post is logged
+// and dropped, so a broken team webhook can't hold up the outbox.
+type ChatNotifier struct {
+	client   *http.Client
+	userRepo repository.UserRepositoryInterface
+	teamRepo repository.TeamRepositoryInterface
+	log      *slog.Logger
+}
+
+func NewChatNotifier(
+	client *http.Client,
+	userRepo repository.UserRepositoryInterface,
+	teamRepo repository.TeamRepositoryInterface,
+	log *slog.Logger,
+) *ChatNotifier {
+	return &ChatNotifier{
+		client:   client,
+		userRepo: userRepo,
+		teamRepo: teamRepo,
+		log:      log,
+	}
+}
+
+type chatMessage struct {
+	Text string `json:"text"`
+}
+
+func (n *ChatNotifier) Publish(ctx context.Context, event models.Event) error {
+	var recipients []string
+	teamMember := event.Payload.AuthorID
+	switch event.Type {
+	case models.EventReviewerAssigned, models.EventPRMerged:
+		recipients = event.Payload.ReviewerIDs
+	case models.EventReviewerReassigned:
+		recipients = []string{event.Payload.NewReviewerID}
+	case models.EventReviewDigest:
+		recipients = []string{event.Payload.ReviewerID}
+		teamMember = event.Payload.ReviewerID
+	default:
+		return nil
+	}
+	if len(recipients) == 0 || teamMember == "" {
+		return nil
+	}
+
+	webhookURL, err := n.teamWebhook(ctx, teamMember)
+	if err != nil || webhookURL == "" {
+		return err
+	}
+
+	handles, err := n.userRepo.GetChatHandles(ctx, recipients)
+	if err != nil {
+		return err
+	}
+
+	text := chatText(event, mentions(recipients, handles))
+	if err := n.post(ctx, webhookURL, text); err != nil {
+		n.log.WarnContext(ctx, "failed to post chat notification", "event_id", event.ID, "err", err)
+	}
+	return nil
+}
+
+func (n *ChatNotifier) teamWebhook(ctx context.Context, userID string) (string, error) {
+	user, err := n.userRepo.GetUser(ctx, userID)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	settings, err := n.teamRepo.GetSettings(ctx, user.TeamName)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return "", nil
+		}
+		return "", err
+	}
+	return settings.ChatWebhookURL, nil
+}
+
+func (n *ChatNotifier) post(ctx context.Context, url, text string) error {
+	body, err := json.Marshal(chatMessage{Text: text})
+	if err != nil {
+		return fmt.Errorf("failed to encode chat message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// mentions renders users as Slack mentions, falling back to the plain ID.
+func mentions(userIDs []string, handles map[string]string) string {
+	parts := make([]string, 0, len(userIDs))
+	for _, id := range userIDs {
+		if handle, ok := handles[id]; ok {
+			parts = append(parts, "<@"+handle+">")
+		} else {
+			parts = append(parts, id)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func chatText(event models.Event, who string) string {
+	pr := event.Payload.PRID
+	if event.Payload.PRName != "" {
+		pr = fmt.Sprintf("*%s* (%s)", event.Payload.PRName, event.Payload.PRID)
+	}
+
+	switch event.Type {
+	case models.EventReviewerAssigned:
+		return fmt.Sprintf("%s: you were assigned to review %s", who, pr)
+	case models.EventReviewerReassigned:
+		return fmt.Sprintf("%s: you were assigned to review %s instead of %s", who, pr, event.Payload.OldReviewerID)
+	case models.EventReviewDigest:
+		lines := []string{fmt.Sprintf("%s: %d pull requests are waiting for your review", who, len(event.Payload.Pending))}
+		for _, p := range event.Payload.Pending {
+			name := p.PRID
+			if p.PRName != "" {
+				name = fmt.Sprintf("*%s* (%s)", p.PRName, p.PRID)
+			}
+			lines = append(lines, fmt.Sprintf("• %s, waiting %s", name, time.Duration(p.WaitingMinutes)*time.Minute))
+		}
+		return strings.Join(lines, "\n")
+	default:
+		return fmt.Sprintf("%s: PR %s you reviewed was merged", who, pr)
+	}
+}
@@ -0,0 +1,117 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/repository/inmemory"
+)
+
+func TestChatNotifier_Simple(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	var messages []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg chatMessage
+		_ = json.NewDecoder(r.Body).Decode(&msg)
+		messages = append(messages, msg.Text)
+	}))
+	defer srv.Close()
+
+	userRepo := inmemory.NewTestUserRepo()
+	userRepo.Users["u1"] = &models.User{ID: "u1", TeamName: "backend", IsActive: true}
+	userRepo.Users["u2"] = &models.User{ID: "u2", TeamName: "backend", IsActive: true}
+	userRepo.Users["u3"] = &models.User{ID: "u3", TeamName: "backend", IsActive: true}
+	userRepo.Users["f1"] = &models.User{ID: "f1", TeamName: "frontend", IsActive: true}
+	userRepo.ChatHandles["u2"] = "U0BOB"
+
+	teamRepo := inmemory.NewTestTeamRepo()
+	teamRepo.Teams["backend"] = &models.Team{Name: "backend"}
+	teamRepo.Teams["frontend"] = &models.Team{Name: "frontend"}
+	teamRepo.Settings["backend"] = &models.TeamSettings{ReviewersCount: 2, ChatWebhookURL: srv.URL}
+
+	chat := NewChatNotifier(srv.Client(), userRepo, teamRepo, logger)
+
+	t.Run("Assigned reviewers are mentioned", func(t *testing.T) {
+		event := models.NewEvent(models.EventReviewerAssigned, models.EventPayload{
+			PRID: "pr-1", PRName: "Fix bug", AuthorID: "u1", ReviewerIDs: []string{"u2", "u3"},
+		})
+		if err := chat.Publish(ctx, event); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(messages) != 1 {
+			t.Fatalf("Expected 1 message, got %d", len(messages))
+		}
+		if !strings.Contains(messages[0], "<@U0BOB>") || !strings.Contains(messages[0], "u3") ||
+			!strings.Contains(messages[0], "Fix bug") {
+			t.Errorf("Unexpected message: %s", messages[0])
+		}
+	})
+
+	t.Run("Reassigned and merged", func(t *testing.T) {
+		_ = chat.Publish(ctx, models.NewEvent(models.EventReviewerReassigned, models.EventPayload{
+			PRID: "pr-1", AuthorID: "u1", OldReviewerID: "u3", NewReviewerID: "u2",
+		}))
+		_ = chat.Publish(ctx, models.NewEvent(models.EventPRMerged, models.EventPayload{
+			PRID: "pr-1", PRName: "Fix bug", AuthorID: "u1", ReviewerIDs: []string{"u2"},
+		}))
+		if len(messages) != 3 {
+			t.Fatalf("Expected 3 messages, got %d", len(messages))
+		}
+		if !strings.Contains(messages[1], "instead of u3") {
+			t.Errorf("Unexpected message: %s", messages[1])
+		}
+		if want := "<@U0BOB>: PR *Fix bug* (pr-1) you reviewed was merged"; messages[2] != want {
+			t.Errorf("Expected %q, got %q", want, messages[2])
+		}
+	})
+
+	t.Run("Digest goes to the reviewer's team", func(t *testing.T) {
+		_ = chat.Publish(ctx, models.NewEvent(models.EventReviewDigest, models.EventPayload{
+			ReviewerID: "u2",
+			Pending:    []models.PendingReview{{PRID: "pr-1", PRName: "Fix bug", WaitingMinutes: 90}},
+		}))
+		if len(messages) != 4 {
+			t.Fatalf("Expected 4 messages, got %d", len(messages))
+		}
+		if !strings.Contains(messages[3], "<@U0BOB>") || !strings.Contains(messages[3], "waiting 1h30m0s") {
+			t.Errorf("Unexpected digest: %s", messages[3])
+		}
+		messages = messages[:3]
+	})
+
+	t.Run("Disabled team and other events are skipped", func(t *testing.T) {
+		_ = chat.Publish(ctx, models.NewEvent(models.EventReviewerAssigned, models.EventPayload{
+			PRID: "pr-2", AuthorID: "f1", ReviewerIDs: []string{"u2"},
+		}))
+		_ = chat.Publish(ctx, models.NewEvent(models.EventPRCreated, models.EventPayload{
+			PRID: "pr-3", AuthorID: "u1", ReviewerIDs: []string{"u2"},
+		}))
+		if len(messages) != 3 {
+			t.Errorf("Expected no new messages, got %v", messages[3:])
+		}
+	})
+
+	t.Run("Failed post is dropped", func(t *testing.T) {
+		broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer broken.Close()
+		teamRepo.Settings["frontend"] = &models.TeamSettings{ReviewersCount: 2, ChatWebhookURL: broken.URL}
+
+		err := chat.Publish(ctx, models.NewEvent(models.EventReviewerAssigned, models.EventPayload{
+			PRID: "pr-2", AuthorID: "f1", ReviewerIDs: []string{"u2"},
+		}))
+		if err != nil {
+			t.Errorf("Expected a failed post not to be an error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,150 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/repository"
+)
+
+const (
+	batchSize  = 50
+	maxBackoff = time.Hour
+)
+
+type Config struct {
+	MaxAttempts  int
+	BaseBackoff  time.Duration
+	PollInterval time.Duration
+}
+
+// Worker delivers pending outbound webhooks. Failed deliveries are retried with
+// exponential backoff and become dead letters after MaxAttempts.
+type Worker struct {
+	repo   repository.NotificationRepositoryInterface
+	client *http.Client
+	cfg    Config
+	now    func() time.Time
+	log    *slog.Logger
+}
+
+func NewWorker(repo repository.NotificationRepositoryInterface, client *http.Client, cfg Config, log *slog.Logger) *Worker {
+	return &Worker{
+		repo:   repo,
+		client: client,
+		cfg:    cfg,
+		now:    time.Now,
+		log:    log,
+	}
+}
+
+// Run polls for due deliveries until ctx is cancelled.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.DeliverDue(ctx); err != nil && ctx.Err() == nil {
+			w.log.ErrorContext(ctx, "failed to deliver notifications", "err", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeliverDue makes one delivery attempt for every due delivery.
+func (w *Worker) DeliverDue(ctx context.Context) error {
+	// The lease outlives the HTTP timeout so another worker never picks a delivery in flight.
+	lease := w.client.Timeout*2 + time.Minute
+	deliveries, err := w.repo.ClaimDueDeliveries(ctx, w.now(), lease, batchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, d := range deliveries {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		w.attempt(ctx, d)
+	}
+	return nil
+}
+
+func (w *Worker) attempt(ctx context.Context, d models.Delivery) {
+	err := w.send(ctx, d)
+	if err == nil {
+		if err := w.repo.MarkDelivered(ctx, d.ID, w.now()); err != nil {
+			w.log.ErrorContext(ctx, "failed to mark delivery", "delivery_id", d.ID, "err", err)
+		}
+		return
+	}
+
+	attempts := d.Attempts + 1
+	dead := attempts >= w.cfg.MaxAttempts
+	w.log.WarnContext(ctx, "notification delivery failed", "delivery_id", d.ID,
+		"url", d.URL, "attempt", attempts, "dead", dead, "err", err)
+
+	if err := w.repo.MarkFailed(ctx, d.ID, err.Error(), w.now().Add(w.backoff(attempts)), dead); err != nil {
+		w.log.ErrorContext(ctx, "failed to mark delivery", "delivery_id", d.ID, "err", err)
+	}
+}
+
+func (w *Worker) send(ctx context.Context, d models.Delivery) error {
+	body, err := json.Marshal(d.Event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-Type", d.Event.Type)
+	req.Header.Set("X-Event-ID", d.Event.ID)
+	if d.Secret != "" {
+		req.Header.Set("X-Signature-256", Sign([]byte(d.Secret), body))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// backoff returns BaseBackoff doubled for every previous attempt, capped at an hour.
+func (w *Worker) backoff(attempts int) time.Duration {
+	d := w.cfg.BaseBackoff
+	for i := 1; i < attempts && d < maxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, maxBackoff)
+}
+
+// Sign returns the X-Signature-256 header value for body.
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,87 @@
+package notifier
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/repository/inmemory"
+)
+
+func TestWorker_Simple(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	var (
+		failing   bool
+		signature string
+		body      []byte
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature = r.Header.Get("X-Signature-256")
+		body, _ = io.ReadAll(r.Body)
+		if failing {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	repo := inmemory.NewTestNotificationRepo()
+	_ = repo.CreateSubscription(ctx, &models.Subscription{URL: srv.URL, Secret: "s3cret"})
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	worker := NewWorker(repo, srv.Client(), Config{MaxAttempts: 3, BaseBackoff: time.Second}, logger)
+	worker.now = func() time.Time { return now }
+
+	event := models.Event{ID: "e1", Type: models.EventPRCreated, OccurredAt: now, Payload: models.EventPayload{PRID: "pr-1"}}
+
+	t.Run("Signed delivery", func(t *testing.T) {
+		_ = repo.EnqueueDeliveries(ctx, event)
+		if err := worker.DeliverDue(ctx); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		d := repo.Deliveries[0]
+		if d.Status != models.DeliveryDelivered {
+			t.Fatalf("Expected DELIVERED, got %s", d.Status)
+		}
+		if signature != Sign([]byte("s3cret"), body) {
+			t.Errorf("Signature mismatch: %s", signature)
+		}
+	})
+
+	t.Run("Retries with backoff then dead letter", func(t *testing.T) {
+		failing = true
+		event.ID = "e2"
+		_ = repo.EnqueueDeliveries(ctx, event)
+		d := repo.Deliveries[1]
+
+		_ = worker.DeliverDue(ctx)
+		if d.Attempts != 1 || !d.NextAttemptAt.Equal(now.Add(time.Second)) {
+			t.Fatalf("Expected retry in 1s, got %+v", d)
+		}
+
+		_ = worker.DeliverDue(ctx)
+		if d.Attempts != 1 {
+			t.Error("Delivery should not be retried before backoff")
+		}
+
+		now = now.Add(time.Second)
+		_ = worker.DeliverDue(ctx)
+		if !d.NextAttemptAt.Equal(now.Add(2 * time.Second)) {
+			t.Errorf("Expected backoff to double, next attempt %v", d.NextAttemptAt)
+		}
+
+		now = now.Add(2 * time.Second)
+		_ = worker.DeliverDue(ctx)
+		if d.Status != models.DeliveryDead || d.Attempts != 3 {
+			t.Errorf("Expected DEAD after 3 attempts, got %s after %d", d.Status, d.Attempts)
+		}
+	})
+}
@@ -0,0 +1,71 @@
+package outbox
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/repository"
+)
+
+const batchSize = 100
+
+type Publisher interface {
+	Publish(ctx context.Context, event models.Event) error
+}
+
+// Dispatcher moves committed outbox events to the publisher. An event is marked
+// dispatched only after Publish succeeds, so it is delivered at least once.
+type Dispatcher struct {
+	repo      repository.OutboxRepositoryInterface
+	publisher Publisher
+	interval  time.Duration
+	log       *slog.Logger
+}
+
+func NewDispatcher(
+	repo repository.OutboxRepositoryInterface,
+	publisher Publisher,
+	interval time.Duration,
+	log *slog.Logger,
+) *Dispatcher {
+	return &Dispatcher{
+		repo:      repo,
+		publisher: publisher,
+		interval:  interval,
+		log:       log,
+	}
+}
+
+// Run dispatches events until ctx is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := d.DispatchAll(ctx); err != nil && ctx.Err() == nil {
+			d.log.ErrorContext(ctx, "failed to dispatch outbox", "err", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchAll drains the outbox batch by batch.
+func (d *Dispatcher) DispatchAll(ctx context.Context) error {
+	for ctx.Err() == nil {
+		n, err := d.repo.Dispatch(ctx, batchSize, d.publisher.Publish)
+		if err != nil {
+			return err
+		}
+		if n < batchSize {
+			return nil
+		}
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,72 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/repository/inmemory"
+)
+
+type flakyPublisher struct {
+	failOn    string
+	published []string
+}
+
+func (p *flakyPublisher) Publish(ctx context.Context, event models.Event) error {
+	if event.ID == p.failOn {
+		p.failOn = ""
+		return errors.New("broker unavailable")
+	}
+	p.published = append(p.published, event.ID)
+	return nil
+}
+
+func TestDispatcher_Simple(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	repo := inmemory.NewTestOutboxRepo()
+	for _, id := range []string{"e1", "e2", "e3"} {
+		repo.Events = append(repo.Events, models.Event{ID: id, Type: models.EventPRCreated})
+	}
+
+	publisher := &flakyPublisher{failOn: "e2"}
+	dispatcher := NewDispatcher(repo, publisher, time.Millisecond, logger)
+
+	t.Run("Failed event is retried", func(t *testing.T) {
+		if err := dispatcher.DispatchAll(ctx); err == nil {
+			t.Fatal("Expected publish error")
+		}
+		if repo.Dispatched["e2"] || repo.Dispatched["e3"] {
+			t.Error("Events after the failure must stay in the outbox")
+		}
+
+		if err := dispatcher.DispatchAll(ctx); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(publisher.published) != 3 {
+			t.Errorf("Expected all events published once, got %v", publisher.published)
+		}
+	})
+
+	t.Run("Run stops on cancel", func(t *testing.T) {
+		runCtx, cancel := context.WithCancel(ctx)
+		done := make(chan struct{})
+		go func() {
+			dispatcher.Run(runCtx)
+			close(done)
+		}()
+		cancel()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("Dispatcher did not stop")
+		}
+	})
+}
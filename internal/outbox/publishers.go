@@ -0,0 +1,20 @@
+package outbox
+
+import (
+	"context"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+)
+
+// Publishers fans an event out to every backend in order and stops at the first error,
+// so the event stays in the outbox and is retried for all of them.
+type Publishers []Publisher
+
+func (p Publishers) Publish(ctx context.Context, event models.Event) error {
+	for _, publisher := range p {
+		if err := publisher.Publish(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package inmemory
+
+import (
+	"context"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+)
+
+type TestCodeOwnerRepo struct {
+	Rules map[string][]models.CodeOwnerRule
+}
+
+func NewTestCodeOwnerRepo() *TestCodeOwnerRepo {
+	return &TestCodeOwnerRepo{Rules: make(map[string][]models.CodeOwnerRule)}
+}
+
+func (r *TestCodeOwnerRepo) GetRules(ctx context.Context, repositoryID string) ([]models.CodeOwnerRule, error) {
+	return r.Rules[repositoryID], nil
+}
+
+func (r *TestCodeOwnerRepo) ReplaceRules(ctx context.Context, repositoryID string, rules []models.CodeOwnerRule) error {
+	r.Rules[repositoryID] = rules
+	return nil
+}
@@ -0,0 +1,35 @@
+package inmemory
+
+import (
+	"context"
+	"sync"
+)
+
+// Locker holds named locks within the process, which is enough for a single
+// replica running on the in-memory store.
+type Locker struct {
+	mu   sync.Mutex
+	held map[string]bool
+}
+
+func NewLocker() *Locker {
+	return &Locker{held: make(map[string]bool)}
+}
+
+func (l *Locker) TryWithLock(ctx context.Context, name string, fn func(ctx context.Context) error) (bool, error) {
+	l.mu.Lock()
+	if l.held[name] {
+		l.mu.Unlock()
+		return false, nil
+	}
+	l.held[name] = true
+	l.mu.Unlock()
+
+	defer func() {
+		l.mu.Lock()
+		delete(l.held, name)
+		l.mu.Unlock()
+	}()
+
+	return true, fn(ctx)
+}
@@ -0,0 +1,181 @@
+package inmemory
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+)
+
+type TestNotificationRepo struct {
+	mu            sync.Mutex
+	Subscriptions []models.Subscription
+	Deliveries    []*models.Delivery
+	nextID        int64
+}
+
+func NewTestNotificationRepo() *TestNotificationRepo {
+	return &TestNotificationRepo{}
+}
+
+func (r *TestNotificationRepo) CreateSubscription(ctx context.Context, sub *models.Subscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	sub.ID = r.nextID
+	sub.CreatedAt = time.Now()
+	r.Subscriptions = append(r.Subscriptions, *sub)
+	return nil
+}
+
+func (r *TestNotificationRepo) ListSubscriptions(ctx context.Context) ([]models.Subscription, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return slices.Clone(r.Subscriptions), nil
+}
+
+func (r *TestNotificationRepo) DeleteSubscription(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, sub := range r.Subscriptions {
+		if sub.ID == id {
+			r.Subscriptions = slices.Delete(r.Subscriptions, i, i+1)
+			r.Deliveries = slices.DeleteFunc(r.Deliveries, func(d *models.Delivery) bool {
+				return d.SubscriptionID == id
+			})
+			return nil
+		}
+	}
+	return models.ErrNotFound
+}
+
+func (r *TestNotificationRepo) EnqueueDeliveries(ctx context.Context, event models.Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, sub := range r.Subscriptions {
+		if len(sub.EventTypes) > 0 && !slices.Contains(sub.EventTypes, event.Type) {
+			continue
+		}
+		duplicate := slices.ContainsFunc(r.Deliveries, func(d *models.Delivery) bool {
+			return d.SubscriptionID == sub.ID && d.Event.ID == event.ID
+		})
+		if duplicate {
+			continue
+		}
+		r.nextID++
+		r.Deliveries = append(r.Deliveries, &models.Delivery{
+			ID:             r.nextID,
+			SubscriptionID: sub.ID,
+			URL:            sub.URL,
+			Secret:         sub.Secret,
+			Event:          event,
+			Status:         models.DeliveryPending,
+			NextAttemptAt:  event.OccurredAt,
+			CreatedAt:      time.Now(),
+		})
+	}
+	return nil
+}
+
+func (r *TestNotificationRepo) ClaimDueDeliveries(
+	ctx context.Context,
+	now time.Time,
+	lease time.Duration,
+	limit int,
+) ([]models.Delivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var claimed []models.Delivery
+	for _, d := range r.Deliveries {
+		if len(claimed) == limit {
+			break
+		}
+		if d.Status != models.DeliveryPending || d.NextAttemptAt.After(now) {
+			continue
+		}
+		d.NextAttemptAt = now.Add(lease)
+		claimed = append(claimed, *d)
+	}
+	return claimed, nil
+}
+
+func (r *TestNotificationRepo) MarkDelivered(ctx context.Context, id int64, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	d := r.find(id)
+	if d == nil {
+		return models.ErrNotFound
+	}
+	d.Status = models.DeliveryDelivered
+	d.Attempts++
+	d.DeliveredAt = &at
+	d.LastError = ""
+	return nil
+}
+
+func (r *TestNotificationRepo) MarkFailed(
+	ctx context.Context,
+	id int64,
+	lastError string,
+	nextAttemptAt time.Time,
+	dead bool,
+) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	d := r.find(id)
+	if d == nil {
+		return models.ErrNotFound
+	}
+	d.Attempts++
+	d.LastError = lastError
+	d.NextAttemptAt = nextAttemptAt
+	if dead {
+		d.Status = models.DeliveryDead
+	}
+	return nil
+}
+
+func (r *TestNotificationRepo) ListDeadLetters(ctx context.Context) ([]models.Delivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var dead []models.Delivery
+	for _, d := range r.Deliveries {
+		if d.Status == models.DeliveryDead {
+			dead = append(dead, *d)
+		}
+	}
+	return dead, nil
+}
+
+func (r *TestNotificationRepo) ReplayDeadLetter(ctx context.Context, id int64, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	d := r.find(id)
+	if d == nil || d.Status != models.DeliveryDead {
+		return models.ErrNotFound
+	}
+	d.Status = models.DeliveryPending
+	d.Attempts = 0
+	d.NextAttemptAt = at
+	return nil
+}
+
+func (r *TestNotificationRepo) find(id int64) *models.Delivery {
+	for _, d := range r.Deliveries {
+		if d.ID == id {
+			return d
+		}
+	}
+	return nil
+}
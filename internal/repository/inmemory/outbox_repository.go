@@ -0,0 +1,40 @@
+package inmemory
+
+import (
+	"context"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+)
+
+type TestOutboxRepo struct {
+	Events     []models.Event
+	Dispatched map[string]bool
+}
+
+func NewTestOutboxRepo() *TestOutboxRepo {
+	return &TestOutboxRepo{
+		Dispatched: make(map[string]bool),
+	}
+}
+
+func (r *TestOutboxRepo) Dispatch(
+	ctx context.Context,
+	limit int,
+	handle func(ctx context.Context, event models.Event) error,
+) (int, error) {
+	count := 0
+	for _, event := range r.Events {
+		if count == limit {
+			break
+		}
+		if r.Dispatched[event.ID] {
+			continue
+		}
+		if err := handle(ctx, event); err != nil {
+			return count, err
+		}
+		r.Dispatched[event.ID] = true
+		count++
+	}
+	return count, nil
+}
@@ -2,62 +2,223 @@ package inmemory
 
 import (
 	"context"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/repository"
 )
 
 type TestPRRepo struct {
-	Prs map[string]*models.PullRequest
+	Prs     map[string]*models.PullRequest
+	Reviews map[string][]models.Review
+	Events  []models.Event
+	// Overdue is returned by MarkOverdue: entries with a zero OverdueAt get flagged.
+	Overdue []models.OverdueReview
 }
 
 func NewTestPRRepo() *TestPRRepo {
 	return &TestPRRepo{
-		Prs: make(map[string]*models.PullRequest),
+		Prs:     make(map[string]*models.PullRequest),
+		Reviews: make(map[string][]models.Review),
 	}
 }
 
-func (r *TestPRRepo) CreatePR(ctx context.Context, pr *models.PullRequest) error {
+func (r *TestPRRepo) CreatePR(ctx context.Context, pr *models.PullRequest, events ...models.Event) error {
 	if _, ok := r.Prs[pr.ID]; ok {
 		return models.ErrAlreadyExists
 	}
 	r.Prs[pr.ID] = pr
+	r.Events = append(r.Events, events...)
 	return nil
 }
 
-func (r *TestPRRepo) MergePR(ctx context.Context, id string) (*models.PullRequest, error) {
+func (r *TestPRRepo) MergePR(
+	ctx context.Context,
+	id string,
+	guard repository.MergeGuard,
+	events ...models.Event,
+) (*models.PullRequest, error) {
 	pr, ok := r.Prs[id]
 	if !ok {
 		return nil, models.ErrNotFound
 	}
-	pr.Status = "MERGED"
-	now := time.Now()
-	pr.MergedAt = &now
+	if guard != nil {
+		current, _ := r.GetByID(ctx, id)
+		if err := guard(ctx, current); err != nil {
+			return nil, err
+		}
+	}
+	if pr.Status != models.PRStatusMerged {
+		r.Events = append(r.Events, events...)
+	}
+	pr.Status = models.PRStatusMerged
+	if pr.MergedAt == nil {
+		now := time.Now()
+		pr.MergedAt = &now
+	}
 	return pr, nil
 }
 
+// GetByID returns a copy, so callers can't change the stored PR without the repository.
 func (r *TestPRRepo) GetByID(ctx context.Context, id string) (*models.PullRequest, error) {
 	pr, ok := r.Prs[id]
 	if !ok {
 		return nil, models.ErrNotFound
 	}
-	return pr, nil
+	copied := *pr
+	copied.Reviewers = slices.Clone(pr.Reviewers)
+	copied.FallbackReviewers = slices.Clone(pr.FallbackReviewers)
+	return &copied, nil
 }
 
-func (r *TestPRRepo) ReassignReviewer(ctx context.Context, id, oldID, newID string) error {
+func (r *TestPRRepo) ReassignReviewer(
+	ctx context.Context,
+	snapshot *models.PullRequest,
+	oldID, newID string,
+	fromFallback bool,
+	events ...models.Event,
+) error {
+	id := snapshot.ID
 	pr, ok := r.Prs[id]
 	if !ok {
-		return nil
+		return models.ErrNotFound
+	}
+	if pr.Status != snapshot.Status || !slices.Equal(pr.Reviewers, snapshot.Reviewers) {
+		return models.ErrPRChanged
 	}
 	for i, rev := range pr.Reviewers {
 		if rev == oldID {
 			pr.Reviewers[i] = newID
+			pr.FallbackReviewers = slices.DeleteFunc(pr.FallbackReviewers, func(f string) bool { return f == oldID })
+			if fromFallback {
+				pr.FallbackReviewers = append(pr.FallbackReviewers, newID)
+			}
+			r.Overdue = slices.DeleteFunc(r.Overdue, func(o models.OverdueReview) bool {
+				return o.PRID == id && o.ReviewerID == oldID
+			})
+			r.Events = append(r.Events, events...)
 			return nil
 		}
 	}
+	return models.ErrNotAssigned
+}
+
+func (r *TestPRRepo) AddReviewer(ctx context.Context, id, userID string, events ...models.Event) error {
+	pr, ok := r.Prs[id]
+	if !ok {
+		return models.ErrNotFound
+	}
+	if err := pr.Status.ReviewersEditable(); err != nil {
+		return err
+	}
+	if slices.Contains(pr.Reviewers, userID) {
+		return models.ErrAlreadyAssigned
+	}
+	pr.Reviewers = append(pr.Reviewers, userID)
+	r.Events = append(r.Events, events...)
+	return nil
+}
+
+func (r *TestPRRepo) RemoveReviewer(ctx context.Context, id, userID string, events ...models.Event) error {
+	pr, ok := r.Prs[id]
+	if !ok {
+		return models.ErrNotFound
+	}
+	if err := pr.Status.ReviewersEditable(); err != nil {
+		return err
+	}
+	if !slices.Contains(pr.Reviewers, userID) {
+		return models.ErrNotAssigned
+	}
+	isUser := func(reviewerID string) bool { return reviewerID == userID }
+	pr.Reviewers = slices.DeleteFunc(pr.Reviewers, isUser)
+	pr.FallbackReviewers = slices.DeleteFunc(pr.FallbackReviewers, isUser)
+	r.Events = append(r.Events, events...)
 	return nil
 }
 
-func (r *TestPRRepo) GetByReviewerID(_ context.Context, _ string) ([]*models.PullRequestShort, error) {
-	return nil, nil
+func (r *TestPRRepo) GetByReviewerID(_ context.Context, userID string) ([]*models.PullRequestShort, error) {
+	var result []*models.PullRequestShort
+	for _, pr := range r.Prs {
+		if pr.Status == models.PRStatusClosed || !slices.Contains(pr.Reviewers, userID) {
+			continue
+		}
+		assignedAt := pr.CreatedAt
+		result = append(result, &models.PullRequestShort{
+			ID:           pr.ID,
+			Name:         pr.Name,
+			AuthorID:     pr.AuthorID,
+			Status:       pr.Status,
+			AssignedAt:   &assignedAt,
+			FromFallback: slices.Contains(pr.FallbackReviewers, userID),
+		})
+	}
+	slices.SortFunc(result, func(a, b *models.PullRequestShort) int { return strings.Compare(a.ID, b.ID) })
+	return result, nil
+}
+
+func (r *TestPRRepo) UpdateStatus(
+	ctx context.Context,
+	id string,
+	status models.PRStatus,
+	from []models.PRStatus,
+	addReviewers, fallbackReviewers []string,
+	events ...models.Event,
+) (*models.PullRequest, error) {
+	pr, ok := r.Prs[id]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	if err := pr.Status.TransitionFrom(from); err != nil {
+		return nil, err
+	}
+	pr.Status = status
+	for _, reviewerID := range addReviewers {
+		if !slices.Contains(pr.Reviewers, reviewerID) {
+			pr.Reviewers = append(pr.Reviewers, reviewerID)
+			if slices.Contains(fallbackReviewers, reviewerID) {
+				pr.FallbackReviewers = append(pr.FallbackReviewers, reviewerID)
+			}
+		}
+	}
+	r.Events = append(r.Events, events...)
+	return pr, nil
+}
+
+func (r *TestPRRepo) AddReview(ctx context.Context, review *models.Review) error {
+	if _, ok := r.Prs[review.PRID]; !ok {
+		return models.ErrNotFound
+	}
+	r.Reviews[review.PRID] = append(r.Reviews[review.PRID], *review)
+	r.Overdue = slices.DeleteFunc(r.Overdue, func(o models.OverdueReview) bool {
+		return o.PRID == review.PRID && o.ReviewerID == review.ReviewerID
+	})
+	return nil
+}
+
+func (r *TestPRRepo) GetReviews(ctx context.Context, prID string) ([]models.Review, error) {
+	return r.Reviews[prID], nil
+}
+
+func (r *TestPRRepo) MarkOverdue(ctx context.Context, now time.Time) ([]models.OverdueReview, error) {
+	var marked []models.OverdueReview
+	for i := range r.Overdue {
+		if r.Overdue[i].OverdueAt.IsZero() && !r.Overdue[i].DueAt.After(now) {
+			r.Overdue[i].OverdueAt = now
+			marked = append(marked, r.Overdue[i])
+		}
+	}
+	return marked, nil
+}
+
+func (r *TestPRRepo) GetOverdue(ctx context.Context, teamName string) ([]models.OverdueReview, error) {
+	var result []models.OverdueReview
+	for _, o := range r.Overdue {
+		if !o.OverdueAt.IsZero() && (teamName == "" || o.TeamName == teamName) {
+			result = append(result, o)
+		}
+	}
+	return result, nil
 }
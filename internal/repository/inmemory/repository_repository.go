@@ -0,0 +1,64 @@
+package inmemory
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+)
+
+type TestRepositoryRepo struct {
+	Repositories map[string]*models.Repository
+}
+
+func NewTestRepositoryRepo() *TestRepositoryRepo {
+	return &TestRepositoryRepo{
+		Repositories: make(map[string]*models.Repository),
+	}
+}
+
+func (r *TestRepositoryRepo) CreateRepository(ctx context.Context, repo *models.Repository) error {
+	if _, ok := r.Repositories[repo.ID]; ok {
+		return models.ErrAlreadyExists
+	}
+	r.Repositories[repo.ID] = copyRepository(repo)
+	return nil
+}
+
+func (r *TestRepositoryRepo) UpdateRepository(ctx context.Context, repo *models.Repository) error {
+	if _, ok := r.Repositories[repo.ID]; !ok {
+		return models.ErrNotFound
+	}
+	r.Repositories[repo.ID] = copyRepository(repo)
+	return nil
+}
+
+func (r *TestRepositoryRepo) GetRepository(ctx context.Context, id string) (*models.Repository, error) {
+	repo, ok := r.Repositories[id]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	return copyRepository(repo), nil
+}
+
+func (r *TestRepositoryRepo) ListRepositories(ctx context.Context, teamName string) ([]models.Repository, error) {
+	var result []models.Repository
+	for _, repo := range r.Repositories {
+		if repo.TeamName == teamName {
+			result = append(result, *copyRepository(repo))
+		}
+	}
+	slices.SortFunc(result, func(a, b models.Repository) int { return strings.Compare(a.ID, b.ID) })
+	return result, nil
+}
+
+func copyRepository(repo *models.Repository) *models.Repository {
+	copied := *repo
+	copied.Reviewers = slices.Clone(repo.Reviewers)
+	if repo.Settings != nil {
+		settings := *repo.Settings
+		copied.Settings = &settings
+	}
+	return &copied
+}
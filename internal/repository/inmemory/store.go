@@ -0,0 +1,186 @@
+package inmemory
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+)
+
+// Store keeps the state of all in-memory repositories behind one lock, so it can
+// replace Postgres for demos and end-to-end tests. Repositories built on the same
+// Store see each other's changes the way tables of one database do.
+type Store struct {
+	mu    sync.RWMutex
+	state *state
+	// inFlight holds outbox events being handled outside of the lock.
+	inFlight map[int64]bool
+}
+
+func NewStore() *Store {
+	return &Store{state: newState(), inFlight: make(map[int64]bool)}
+}
+
+type userRow struct {
+	models.User
+	chatHandle   string
+	quietHours   models.QuietHours
+	lastDigestAt *time.Time
+}
+
+type absenceRow struct {
+	models.Absence
+	handledAt *time.Time
+}
+
+type assignment struct {
+	reviewerID   string
+	fromFallback bool
+	assignedAt   time.Time
+	overdueAt    *time.Time
+}
+
+type reviewRow struct {
+	id int64
+	models.Review
+}
+
+type outboxRow struct {
+	id         int64
+	event      models.Event
+	dispatched bool
+}
+
+// state holds rows by value; slices inside rows are replaced rather than changed
+// in place, so a shallow copy of every table is a consistent snapshot.
+type state struct {
+	teams         map[string]*models.TeamSettings
+	users         map[string]userRow
+	identities    map[string]string
+	absences      map[int64]absenceRow
+	prs           map[string]models.PullRequest
+	reviewers     map[string][]assignment
+	reviews       []reviewRow
+	repositories  map[string]models.Repository
+	codeOwners    map[string][]models.CodeOwnerRule
+	webhooks      map[string]bool
+	subscriptions []models.Subscription
+	deliveries    []models.Delivery
+	outbox        []outboxRow
+	outboxIDs     map[string]bool
+	lastID        int64
+}
+
+func newState() *state {
+	return &state{
+		teams:        make(map[string]*models.TeamSettings),
+		users:        make(map[string]userRow),
+		identities:   make(map[string]string),
+		absences:     make(map[int64]absenceRow),
+		prs:          make(map[string]models.PullRequest),
+		reviewers:    make(map[string][]assignment),
+		repositories: make(map[string]models.Repository),
+		codeOwners:   make(map[string][]models.CodeOwnerRule),
+		webhooks:     make(map[string]bool),
+		outboxIDs:    make(map[string]bool),
+	}
+}
+
+func (s *state) clone() *state {
+	reviewers := make(map[string][]assignment, len(s.reviewers))
+	for prID, assignments := range s.reviewers {
+		reviewers[prID] = slices.Clone(assignments)
+	}
+	return &state{
+		teams:         maps.Clone(s.teams),
+		users:         maps.Clone(s.users),
+		identities:    maps.Clone(s.identities),
+		absences:      maps.Clone(s.absences),
+		prs:           maps.Clone(s.prs),
+		reviewers:     reviewers,
+		reviews:       slices.Clone(s.reviews),
+		repositories:  maps.Clone(s.repositories),
+		codeOwners:    maps.Clone(s.codeOwners),
+		webhooks:      maps.Clone(s.webhooks),
+		subscriptions: slices.Clone(s.subscriptions),
+		deliveries:    slices.Clone(s.deliveries),
+		outbox:        slices.Clone(s.outbox),
+		outboxIDs:     maps.Clone(s.outboxIDs),
+		lastID:        s.lastID,
+	}
+}
+
+// nextID plays the role of a shared sequence.
+func (s *state) nextID() int64 {
+	s.lastID++
+	return s.lastID
+}
+
+func (s *state) appendOutbox(events []models.Event) {
+	for _, event := range events {
+		if !s.outboxIDs[event.ID] {
+			s.outboxIDs[event.ID] = true
+			s.outbox = append(s.outbox, outboxRow{id: s.nextID(), event: event})
+		}
+	}
+}
+
+type lockKey struct{}
+
+// lock takes the write lock unless ctx comes from a holder of it, and returns the
+// context to pass to callbacks so that they can use the store too.
+func (s *Store) lock(ctx context.Context) (context.Context, func()) {
+	if s.holds(ctx) {
+		return ctx, func() {}
+	}
+	s.mu.Lock()
+	return context.WithValue(ctx, lockKey{}, s), s.mu.Unlock
+}
+
+func (s *Store) rlock(ctx context.Context) func() {
+	if s.holds(ctx) {
+		return func() {}
+	}
+	s.mu.RLock()
+	return s.mu.RUnlock
+}
+
+func (s *Store) holds(ctx context.Context) bool {
+	held, _ := ctx.Value(lockKey{}).(*Store)
+	return held == s
+}
+
+// savepoint returns a function that brings the state back to this moment.
+func (s *Store) savepoint() func() {
+	saved := s.state.clone()
+	return func() {
+		s.state = saved
+	}
+}
+
+// TxManager holds the store lock for the whole transaction, so transactions run
+// one at a time. fn must not pass its context to other goroutines.
+type TxManager struct {
+	store *Store
+}
+
+func NewTxManager(store *Store) *TxManager {
+	return &TxManager{store: store}
+}
+
+// WithinTx undoes every change made by fn if it fails. Nested calls undo only
+// their own changes, like savepoints.
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	ctx, unlock := m.store.lock(ctx)
+	defer unlock()
+
+	restore := m.store.savepoint()
+	if err := fn(ctx); err != nil {
+		restore()
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,294 @@
+package inmemory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+)
+
+type OutboxRepository struct {
+	store *Store
+}
+
+func NewOutboxRepository(store *Store) *OutboxRepository {
+	return &OutboxRepository{store: store}
+}
+
+// Dispatch runs handle without the store lock, as handlers may call slow
+// endpoints. Claimed events are skipped by concurrent dispatchers meanwhile.
+func (r *OutboxRepository) Dispatch(
+	ctx context.Context,
+	limit int,
+	handle func(ctx context.Context, event models.Event) error,
+) (int, error) {
+	pending := r.claim(ctx, limit)
+	defer r.release(ctx, pending)
+
+	var (
+		dispatched []int64
+		handleErr  error
+	)
+	for _, row := range pending {
+		if handleErr = handle(ctx, row.event); handleErr != nil {
+			break
+		}
+		dispatched = append(dispatched, row.id)
+	}
+
+	if len(dispatched) > 0 {
+		_, unlock := r.store.lock(ctx)
+		for i, row := range r.store.state.outbox {
+			if slices.Contains(dispatched, row.id) {
+				r.store.state.outbox[i].dispatched = true
+			}
+		}
+		unlock()
+	}
+
+	return len(dispatched), handleErr
+}
+
+func (r *OutboxRepository) claim(ctx context.Context, limit int) []outboxRow {
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	var pending []outboxRow
+	for _, row := range r.store.state.outbox {
+		if len(pending) == limit {
+			break
+		}
+		if !row.dispatched && !r.store.inFlight[row.id] {
+			r.store.inFlight[row.id] = true
+			pending = append(pending, row)
+		}
+	}
+	return pending
+}
+
+func (r *OutboxRepository) release(ctx context.Context, rows []outboxRow) {
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	for _, row := range rows {
+		delete(r.store.inFlight, row.id)
+	}
+}
+
+type NotificationRepository struct {
+	store *Store
+}
+
+func NewNotificationRepository(store *Store) *NotificationRepository {
+	return &NotificationRepository{store: store}
+}
+
+func (r *NotificationRepository) CreateSubscription(ctx context.Context, sub *models.Subscription) error {
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+	st := r.store.state
+
+	sub.ID = st.nextID()
+	sub.CreatedAt = time.Now()
+	stored := *sub
+	stored.EventTypes = slices.Clone(sub.EventTypes)
+	if stored.EventTypes == nil {
+		stored.EventTypes = []string{}
+	}
+	st.subscriptions = append(st.subscriptions, stored)
+	return nil
+}
+
+func (r *NotificationRepository) ListSubscriptions(ctx context.Context) ([]models.Subscription, error) {
+	defer r.store.rlock(ctx)()
+
+	subs := make([]models.Subscription, 0, len(r.store.state.subscriptions))
+	for _, sub := range r.store.state.subscriptions {
+		sub.EventTypes = slices.Clone(sub.EventTypes)
+		subs = append(subs, sub)
+	}
+	return subs, nil
+}
+
+func (r *NotificationRepository) DeleteSubscription(ctx context.Context, id int64) error {
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+	st := r.store.state
+
+	i := slices.IndexFunc(st.subscriptions, func(sub models.Subscription) bool { return sub.ID == id })
+	if i < 0 {
+		return models.ErrNotFound
+	}
+	st.subscriptions = slices.Delete(st.subscriptions, i, i+1)
+	st.deliveries = slices.DeleteFunc(st.deliveries, func(d models.Delivery) bool { return d.SubscriptionID == id })
+	return nil
+}
+
+func (r *NotificationRepository) EnqueueDeliveries(ctx context.Context, event models.Event) error {
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+	st := r.store.state
+
+	for _, sub := range st.subscriptions {
+		if len(sub.EventTypes) > 0 && !slices.Contains(sub.EventTypes, event.Type) {
+			continue
+		}
+		duplicate := slices.ContainsFunc(st.deliveries, func(d models.Delivery) bool {
+			return d.SubscriptionID == sub.ID && d.Event.ID == event.ID
+		})
+		if duplicate {
+			continue
+		}
+		st.deliveries = append(st.deliveries, models.Delivery{
+			ID:             st.nextID(),
+			SubscriptionID: sub.ID,
+			Event:          event,
+			Status:         models.DeliveryPending,
+			NextAttemptAt:  event.OccurredAt,
+			CreatedAt:      time.Now(),
+		})
+	}
+	return nil
+}
+
+func (r *NotificationRepository) ClaimDueDeliveries(
+	ctx context.Context,
+	now time.Time,
+	lease time.Duration,
+	limit int,
+) ([]models.Delivery, error) {
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+	st := r.store.state
+
+	var due []int
+	for i, d := range st.deliveries {
+		if d.Status == models.DeliveryPending && !d.NextAttemptAt.After(now) {
+			due = append(due, i)
+		}
+	}
+	slices.SortStableFunc(due, func(a, b int) int {
+		return st.deliveries[a].NextAttemptAt.Compare(st.deliveries[b].NextAttemptAt)
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	claimed := make([]models.Delivery, 0, len(due))
+	for _, i := range due {
+		st.deliveries[i].NextAttemptAt = now.Add(lease)
+		claimed = append(claimed, st.withSubscription(st.deliveries[i]))
+	}
+	return claimed, nil
+}
+
+func (r *NotificationRepository) MarkDelivered(ctx context.Context, id int64, at time.Time) error {
+	r.updateDelivery(ctx, id, func(d *models.Delivery) {
+		d.Status = models.DeliveryDelivered
+		d.Attempts++
+		d.DeliveredAt = &at
+		d.LastError = ""
+	})
+	return nil
+}
+
+func (r *NotificationRepository) MarkFailed(
+	ctx context.Context,
+	id int64,
+	lastError string,
+	nextAttemptAt time.Time,
+	dead bool,
+) error {
+	r.updateDelivery(ctx, id, func(d *models.Delivery) {
+		d.Status = models.DeliveryPending
+		if dead {
+			d.Status = models.DeliveryDead
+		}
+		d.Attempts++
+		d.LastError = lastError
+		d.NextAttemptAt = nextAttemptAt
+	})
+	return nil
+}
+
+func (r *NotificationRepository) ListDeadLetters(ctx context.Context) ([]models.Delivery, error) {
+	defer r.store.rlock(ctx)()
+	st := r.store.state
+
+	dead := []models.Delivery{}
+	for _, d := range st.deliveries {
+		if d.Status == models.DeliveryDead {
+			dead = append(dead, st.withSubscription(d))
+		}
+	}
+	slices.SortFunc(dead, func(a, b models.Delivery) int { return cmp.Compare(a.ID, b.ID) })
+	return dead, nil
+}
+
+func (r *NotificationRepository) ReplayDeadLetter(ctx context.Context, id int64, at time.Time) error {
+	replayed := false
+	r.updateDelivery(ctx, id, func(d *models.Delivery) {
+		if d.Status == models.DeliveryDead {
+			d.Status = models.DeliveryPending
+			d.Attempts = 0
+			d.NextAttemptAt = at
+			replayed = true
+		}
+	})
+	if !replayed {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+func (r *NotificationRepository) updateDelivery(ctx context.Context, id int64, change func(d *models.Delivery)) {
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	for i := range r.store.state.deliveries {
+		if r.store.state.deliveries[i].ID == id {
+			change(&r.store.state.deliveries[i])
+			return
+		}
+	}
+}
+
+// withSubscription fills in the subscription fields the way a join would.
+func (s *state) withSubscription(d models.Delivery) models.Delivery {
+	i := slices.IndexFunc(s.subscriptions, func(sub models.Subscription) bool { return sub.ID == d.SubscriptionID })
+	if i >= 0 {
+		d.URL = s.subscriptions[i].URL
+		d.Secret = s.subscriptions[i].Secret
+	}
+	return d
+}
+
+type WebhookDeliveryRepository struct {
+	store *Store
+}
+
+func NewWebhookDeliveryRepository(store *Store) *WebhookDeliveryRepository {
+	return &WebhookDeliveryRepository{store: store}
+}
+
+func (r *WebhookDeliveryRepository) MarkReceived(ctx context.Context, provider, deliveryID string) (bool, error) {
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	key := provider + "/" + deliveryID
+	if r.store.state.webhooks[key] {
+		return false, nil
+	}
+	r.store.state.webhooks[key] = true
+	return true, nil
+}
+
+func (r *WebhookDeliveryRepository) Forget(ctx context.Context, provider, deliveryID string) error {
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	delete(r.store.state.webhooks, provider+"/"+deliveryID)
+	return nil
+}
@@ -0,0 +1,459 @@
+package inmemory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/repository"
+)
+
+type PullRequestRepository struct {
+	store *Store
+}
+
+func NewPullRequestRepository(store *Store) *PullRequestRepository {
+	return &PullRequestRepository{store: store}
+}
+
+func (r *PullRequestRepository) CreatePR(ctx context.Context, pr *models.PullRequest, events ...models.Event) error {
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+	st := r.store.state
+
+	if _, ok := st.prs[pr.ID]; ok {
+		return fmt.Errorf("pr exists: %w", models.ErrAlreadyExists)
+	}
+	if _, ok := st.users[pr.AuthorID]; !ok {
+		return fmt.Errorf("author %s: %w", pr.AuthorID, models.ErrNotFound)
+	}
+
+	now := time.Now()
+	var assignments []assignment
+	for _, reviewerID := range pr.Reviewers {
+		if _, ok := st.users[reviewerID]; !ok {
+			return fmt.Errorf("reviewer %s: %w", reviewerID, models.ErrNotFound)
+		}
+		if slices.ContainsFunc(assignments, func(a assignment) bool { return a.reviewerID == reviewerID }) {
+			return fmt.Errorf("reviewer %s: %w", reviewerID, models.ErrAlreadyAssigned)
+		}
+		assignments = append(assignments, assignment{
+			reviewerID:   reviewerID,
+			fromFallback: slices.Contains(pr.FallbackReviewers, reviewerID),
+			assignedAt:   now,
+		})
+	}
+
+	stored := *pr
+	stored.Files = slices.Clone(pr.Files)
+	if stored.Files == nil {
+		stored.Files = []string{}
+	}
+	stored.Reviewers = nil
+	stored.FallbackReviewers = nil
+	st.prs[pr.ID] = stored
+	st.reviewers[pr.ID] = assignments
+	st.appendOutbox(events)
+	return nil
+}
+
+// MergePR calls guard under the store lock, so reviews can't change while it decides.
+func (r *PullRequestRepository) MergePR(
+	ctx context.Context,
+	id string,
+	guard repository.MergeGuard,
+	events ...models.Event,
+) (*models.PullRequest, error) {
+	ctx, unlock := r.store.lock(ctx)
+	defer unlock()
+	st := r.store.state
+
+	pr, ok := st.prs[id]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	if guard != nil {
+		if err := guard(ctx, st.pullRequest(id)); err != nil {
+			return nil, err
+		}
+	}
+	if pr.Status != models.PRStatusMerged {
+		st.appendOutbox(events)
+	}
+	pr.Status = models.PRStatusMerged
+	if pr.MergedAt == nil {
+		now := time.Now()
+		pr.MergedAt = &now
+	}
+	st.prs[id] = pr
+	return st.pullRequest(id), nil
+}
+
+// ReassignReviewer compares the snapshot and writes the change under the store
+// lock, so merges and other reassignments of the PR can't slip in between.
+func (r *PullRequestRepository) ReassignReviewer(
+	ctx context.Context,
+	snapshot *models.PullRequest,
+	oldUserID, newUserID string,
+	fromFallback bool,
+	events ...models.Event,
+) error {
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+	st := r.store.state
+
+	if _, ok := st.prs[snapshot.ID]; !ok {
+		return models.ErrNotFound
+	}
+	if !sameReviewState(snapshot, st.pullRequest(snapshot.ID)) {
+		return models.ErrPRChanged
+	}
+
+	assignments := st.reviewers[snapshot.ID]
+	i := slices.IndexFunc(assignments, func(a assignment) bool { return a.reviewerID == oldUserID })
+	if i < 0 {
+		return models.ErrNotAssigned
+	}
+	if oldUserID != newUserID && st.assigned(snapshot.ID, newUserID) {
+		return fmt.Errorf("reviewer already assigned: %w", models.ErrAlreadyExists)
+	}
+	if _, ok := st.users[newUserID]; !ok {
+		return fmt.Errorf("reviewer %s: %w", newUserID, models.ErrNotFound)
+	}
+
+	assignments[i] = assignment{reviewerID: newUserID, fromFallback: fromFallback, assignedAt: time.Now()}
+	st.appendOutbox(events)
+	return nil
+}
+
+func (r *PullRequestRepository) AddReviewer(ctx context.Context, prID, userID string, events ...models.Event) error {
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+	st := r.store.state
+
+	pr, prFound := st.prs[prID]
+	_, userFound := st.users[userID]
+	if !prFound || !userFound {
+		return fmt.Errorf("pr %s or user %s: %w", prID, userID, models.ErrNotFound)
+	}
+	if err := pr.Status.ReviewersEditable(); err != nil {
+		return err
+	}
+	if st.assigned(prID, userID) {
+		return fmt.Errorf("reviewer %s: %w", userID, models.ErrAlreadyAssigned)
+	}
+
+	st.reviewers[prID] = append(st.reviewers[prID], assignment{reviewerID: userID, assignedAt: time.Now()})
+	st.appendOutbox(events)
+	return nil
+}
+
+func (r *PullRequestRepository) RemoveReviewer(ctx context.Context, prID, userID string, events ...models.Event) error {
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+	st := r.store.state
+
+	pr, ok := st.prs[prID]
+	if !ok {
+		return fmt.Errorf("pr %s: %w", prID, models.ErrNotFound)
+	}
+	if err := pr.Status.ReviewersEditable(); err != nil {
+		return err
+	}
+	if !st.assigned(prID, userID) {
+		return models.ErrNotAssigned
+	}
+	st.reviewers[prID] = slices.DeleteFunc(st.reviewers[prID], func(a assignment) bool {
+		return a.reviewerID == userID
+	})
+	st.appendOutbox(events)
+	return nil
+}
+
+func (r *PullRequestRepository) GetByReviewerID(ctx context.Context, userID string) ([]*models.PullRequestShort, error) {
+	defer r.store.rlock(ctx)()
+	st := r.store.state
+
+	result := []*models.PullRequestShort{}
+	for _, pr := range st.sortedPRs() {
+		if pr.Status == models.PRStatusClosed {
+			continue
+		}
+		for _, a := range st.reviewers[pr.ID] {
+			if a.reviewerID == userID {
+				assignedAt := a.assignedAt
+				result = append(result, &models.PullRequestShort{
+					ID:           pr.ID,
+					Name:         pr.Name,
+					AuthorID:     pr.AuthorID,
+					Status:       pr.Status,
+					AssignedAt:   &assignedAt,
+					FromFallback: a.fromFallback,
+				})
+			}
+		}
+	}
+	return result, nil
+}
+
+func (r *PullRequestRepository) GetByID(ctx context.Context, id string) (*models.PullRequest, error) {
+	defer r.store.rlock(ctx)()
+
+	if _, ok := r.store.state.prs[id]; !ok {
+		return nil, models.ErrNotFound
+	}
+	return r.store.state.pullRequest(id), nil
+}
+
+func (r *PullRequestRepository) UpdateStatus(
+	ctx context.Context,
+	id string,
+	status models.PRStatus,
+	from []models.PRStatus,
+	addReviewers, fallbackReviewers []string,
+	events ...models.Event,
+) (*models.PullRequest, error) {
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+	st := r.store.state
+
+	pr, ok := st.prs[id]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	if err := pr.Status.TransitionFrom(from); err != nil {
+		return nil, err
+	}
+	for _, reviewerID := range addReviewers {
+		if _, ok := st.users[reviewerID]; !ok {
+			return nil, fmt.Errorf("reviewer %s: %w", reviewerID, models.ErrNotFound)
+		}
+	}
+
+	pr.Status = status
+	st.prs[id] = pr
+
+	now := time.Now()
+	assignments := slices.Clone(st.reviewers[id])
+	if status == models.PRStatusOpen {
+		// The SLA clock restarts whenever the PR goes back to review.
+		for i := range assignments {
+			assignments[i].assignedAt = now
+			assignments[i].overdueAt = nil
+		}
+	}
+	for _, reviewerID := range addReviewers {
+		if !slices.ContainsFunc(assignments, func(a assignment) bool { return a.reviewerID == reviewerID }) {
+			assignments = append(assignments, assignment{
+				reviewerID:   reviewerID,
+				fromFallback: slices.Contains(fallbackReviewers, reviewerID),
+				assignedAt:   now,
+			})
+		}
+	}
+	st.reviewers[id] = assignments
+
+	st.appendOutbox(events)
+	return st.pullRequest(id), nil
+}
+
+func (r *PullRequestRepository) AddReview(ctx context.Context, review *models.Review) error {
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+	st := r.store.state
+
+	_, prFound := st.prs[review.PRID]
+	_, userFound := st.users[review.ReviewerID]
+	if !prFound || !userFound {
+		return fmt.Errorf("pr or reviewer: %w", models.ErrNotFound)
+	}
+	st.reviews = append(st.reviews, reviewRow{id: st.nextID(), Review: *review})
+	for i, a := range st.reviewers[review.PRID] {
+		if a.reviewerID == review.ReviewerID {
+			st.reviewers[review.PRID][i].overdueAt = nil
+		}
+	}
+	return nil
+}
+
+func (r *PullRequestRepository) GetReviews(ctx context.Context, prID string) ([]models.Review, error) {
+	defer r.store.rlock(ctx)()
+
+	var rows []reviewRow
+	for _, row := range r.store.state.reviews {
+		if row.PRID == prID {
+			rows = append(rows, row)
+		}
+	}
+	slices.SortFunc(rows, func(a, b reviewRow) int {
+		return cmp.Or(a.SubmittedAt.Compare(b.SubmittedAt), cmp.Compare(a.id, b.id))
+	})
+
+	reviews := make([]models.Review, 0, len(rows))
+	for _, row := range rows {
+		reviews = append(reviews, row.Review)
+	}
+	return reviews, nil
+}
+
+func (r *PullRequestRepository) MarkOverdue(ctx context.Context, now time.Time) ([]models.OverdueReview, error) {
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+	st := r.store.state
+
+	marked := []models.OverdueReview{}
+	for _, pr := range st.sortedPRs() {
+		if pr.Status != models.PRStatusOpen {
+			continue
+		}
+		teamName := st.scopeTeam(pr)
+		settings := st.teams[teamName]
+		if settings == nil || settings.ReviewSLAMinutes <= 0 {
+			continue
+		}
+		sla := time.Duration(settings.ReviewSLAMinutes) * time.Minute
+
+		assignments := st.reviewers[pr.ID]
+		for i, a := range assignments {
+			if a.overdueAt != nil || a.assignedAt.Add(sla).After(now) || st.reviewedSince(pr.ID, a) {
+				continue
+			}
+			overdueAt := now
+			assignments[i].overdueAt = &overdueAt
+			marked = append(marked, overdueReview(pr, assignments[i], teamName, sla))
+		}
+	}
+	return marked, nil
+}
+
+func (r *PullRequestRepository) GetOverdue(ctx context.Context, teamName string) ([]models.OverdueReview, error) {
+	defer r.store.rlock(ctx)()
+	st := r.store.state
+
+	overdue := []models.OverdueReview{}
+	for _, pr := range st.prs {
+		scopeTeam := st.scopeTeam(pr)
+		if pr.Status != models.PRStatusOpen || (teamName != "" && scopeTeam != teamName) {
+			continue
+		}
+		var sla time.Duration
+		if settings := st.teams[scopeTeam]; settings != nil {
+			sla = time.Duration(settings.ReviewSLAMinutes) * time.Minute
+		}
+		for _, a := range st.reviewers[pr.ID] {
+			if a.overdueAt != nil {
+				overdue = append(overdue, overdueReview(pr, a, scopeTeam, sla))
+			}
+		}
+	}
+	slices.SortFunc(overdue, func(a, b models.OverdueReview) int {
+		return cmp.Or(a.OverdueAt.Compare(b.OverdueAt), strings.Compare(a.PRID, b.PRID))
+	})
+	return overdue, nil
+}
+
+type StatsRepository struct {
+	store *Store
+}
+
+func NewStatsRepository(store *Store) *StatsRepository {
+	return &StatsRepository{store: store}
+}
+
+func (r *StatsRepository) GetTopReviewers(ctx context.Context) ([]*models.ReviewerStat, error) {
+	defer r.store.rlock(ctx)()
+	st := r.store.state
+
+	counts := make(map[string]int)
+	for _, assignments := range st.reviewers {
+		for _, a := range assignments {
+			counts[a.reviewerID]++
+		}
+	}
+
+	stats := []*models.ReviewerStat{}
+	for _, row := range st.sortedUsers() {
+		stats = append(stats, &models.ReviewerStat{UserID: row.ID, Username: row.Name, ReviewCount: counts[row.ID]})
+	}
+	slices.SortStableFunc(stats, func(a, b *models.ReviewerStat) int { return cmp.Compare(b.ReviewCount, a.ReviewCount) })
+	if len(stats) > 10 {
+		stats = stats[:10]
+	}
+	return stats, nil
+}
+
+// pullRequest returns a copy of the stored PR with its reviewers.
+func (s *state) pullRequest(id string) *models.PullRequest {
+	pr := s.prs[id]
+	pr.Files = slices.Clone(pr.Files)
+	pr.Reviewers = []string{}
+	for _, a := range s.reviewers[id] {
+		pr.Reviewers = append(pr.Reviewers, a.reviewerID)
+		if a.fromFallback {
+			pr.FallbackReviewers = append(pr.FallbackReviewers, a.reviewerID)
+		}
+	}
+	return &pr
+}
+
+// sortedPRs lists PRs newest first, like the SQL backend does for reviewers.
+func (s *state) sortedPRs() []models.PullRequest {
+	prs := make([]models.PullRequest, 0, len(s.prs))
+	for _, pr := range s.prs {
+		prs = append(prs, pr)
+	}
+	slices.SortFunc(prs, func(a, b models.PullRequest) int {
+		return cmp.Or(b.CreatedAt.Compare(a.CreatedAt), strings.Compare(a.ID, b.ID))
+	})
+	return prs
+}
+
+func (s *state) assigned(prID, userID string) bool {
+	return slices.ContainsFunc(s.reviewers[prID], func(a assignment) bool { return a.reviewerID == userID })
+}
+
+// scopeTeam is the team whose SLA applies to pr: the team of its registered
+// repository, or the author's team.
+func (s *state) scopeTeam(pr models.PullRequest) string {
+	if repo, ok := s.repositories[pr.RepositoryID]; ok {
+		return repo.TeamName
+	}
+	return s.users[pr.AuthorID].TeamName
+}
+
+// reviewedSince reports whether the reviewer gave a verdict after the assignment.
+func (s *state) reviewedSince(prID string, a assignment) bool {
+	return slices.ContainsFunc(s.reviews, func(row reviewRow) bool {
+		return row.PRID == prID && row.ReviewerID == a.reviewerID && !row.SubmittedAt.Before(a.assignedAt)
+	})
+}
+
+// sameReviewState reports whether a and b have the same status and reviewers.
+func sameReviewState(a, b *models.PullRequest) bool {
+	if a.Status != b.Status || len(a.Reviewers) != len(b.Reviewers) {
+		return false
+	}
+	for _, reviewerID := range a.Reviewers {
+		if !slices.Contains(b.Reviewers, reviewerID) {
+			return false
+		}
+	}
+	return true
+}
+
+func overdueReview(pr models.PullRequest, a assignment, teamName string, sla time.Duration) models.OverdueReview {
+	return models.OverdueReview{
+		PRID:       pr.ID,
+		PRName:     pr.Name,
+		ReviewerID: a.reviewerID,
+		AuthorID:   pr.AuthorID,
+		TeamName:   teamName,
+		AssignedAt: a.assignedAt,
+		DueAt:      a.assignedAt.Add(sla),
+		OverdueAt:  *a.overdueAt,
+	}
+}
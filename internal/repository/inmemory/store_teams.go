@@ -0,0 +1,216 @@
+package inmemory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+)
+
+type TeamRepository struct {
+	store *Store
+}
+
+func NewTeamRepository(store *Store) *TeamRepository {
+	return &TeamRepository{store: store}
+}
+
+func (r *TeamRepository) CreateTeam(ctx context.Context, team *models.Team) error {
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+	st := r.store.state
+
+	if _, ok := st.teams[team.Name]; ok {
+		return fmt.Errorf("team exists: %w", models.ErrAlreadyExists)
+	}
+	st.teams[team.Name] = nil
+
+	for _, member := range team.Members {
+		row, ok := st.users[member.UserID]
+		if !ok {
+			row.quietHours.Timezone = "UTC"
+		}
+		row.User = models.User{
+			ID:       member.UserID,
+			Name:     member.UserName,
+			TeamName: team.Name,
+			IsActive: member.IsActive,
+		}
+		st.users[member.UserID] = row
+	}
+	return nil
+}
+
+func (r *TeamRepository) GetTeam(ctx context.Context, name string) (*models.Team, error) {
+	defer r.store.rlock(ctx)()
+	st := r.store.state
+
+	if _, ok := st.teams[name]; !ok {
+		return nil, models.ErrNotFound
+	}
+
+	members := []models.TeamMember{}
+	for _, row := range st.users {
+		if row.TeamName == name {
+			members = append(members, models.TeamMember{UserID: row.ID, UserName: row.Name, IsActive: row.IsActive})
+		}
+	}
+	slices.SortFunc(members, func(a, b models.TeamMember) int { return strings.Compare(a.UserID, b.UserID) })
+
+	return &models.Team{Name: name, Members: members}, nil
+}
+
+func (r *TeamRepository) GetSettings(ctx context.Context, teamName string) (*models.TeamSettings, error) {
+	defer r.store.rlock(ctx)()
+
+	settings, ok := r.store.state.teams[teamName]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	if settings == nil {
+		return models.DefaultTeamSettings(), nil
+	}
+	return copySettings(settings), nil
+}
+
+func (r *TeamRepository) UpsertSettings(ctx context.Context, teamName string, settings *models.TeamSettings) error {
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	if _, ok := r.store.state.teams[teamName]; !ok {
+		return fmt.Errorf("team %s: %w", teamName, models.ErrNotFound)
+	}
+	r.store.state.teams[teamName] = copySettings(settings)
+	return nil
+}
+
+func copySettings(settings *models.TeamSettings) *models.TeamSettings {
+	copied := *settings
+	copied.FallbackTeams = slices.Clone(settings.FallbackTeams)
+	if copied.FallbackTeams == nil {
+		copied.FallbackTeams = []string{}
+	}
+	return &copied
+}
+
+type RepositoryRepository struct {
+	store *Store
+}
+
+func NewRepositoryRepository(store *Store) *RepositoryRepository {
+	return &RepositoryRepository{store: store}
+}
+
+func (r *RepositoryRepository) CreateRepository(ctx context.Context, repo *models.Repository) error {
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+	st := r.store.state
+
+	if _, ok := st.repositories[repo.ID]; ok {
+		return fmt.Errorf("repository %s: %w", repo.ID, models.ErrAlreadyExists)
+	}
+	stored, err := st.checkRepository(repo)
+	if err != nil {
+		return err
+	}
+	st.repositories[repo.ID] = stored
+	return nil
+}
+
+func (r *RepositoryRepository) UpdateRepository(ctx context.Context, repo *models.Repository) error {
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+	st := r.store.state
+
+	stored, err := st.checkRepository(repo)
+	if err != nil {
+		return err
+	}
+	if _, ok := st.repositories[repo.ID]; !ok {
+		return models.ErrNotFound
+	}
+	st.repositories[repo.ID] = stored
+	return nil
+}
+
+func (r *RepositoryRepository) GetRepository(ctx context.Context, id string) (*models.Repository, error) {
+	defer r.store.rlock(ctx)()
+
+	repo, ok := r.store.state.repositories[id]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	return copyRepository(&repo), nil
+}
+
+func (r *RepositoryRepository) ListRepositories(ctx context.Context, teamName string) ([]models.Repository, error) {
+	defer r.store.rlock(ctx)()
+
+	result := []models.Repository{}
+	for _, repo := range r.store.state.repositories {
+		if repo.TeamName == teamName {
+			result = append(result, *copyRepository(&repo))
+		}
+	}
+	slices.SortFunc(result, func(a, b models.Repository) int { return strings.Compare(a.ID, b.ID) })
+	return result, nil
+}
+
+// checkRepository resolves the references of repo and returns the row to store,
+// with reviewers sorted like the SQL backend returns them.
+func (s *state) checkRepository(repo *models.Repository) (models.Repository, error) {
+	if _, ok := s.teams[repo.TeamName]; !ok {
+		return models.Repository{}, fmt.Errorf("team %s: %w", repo.TeamName, models.ErrNotFound)
+	}
+	for _, userID := range repo.Reviewers {
+		if _, ok := s.users[userID]; !ok {
+			return models.Repository{}, fmt.Errorf("user %s: %w", userID, models.ErrNotFound)
+		}
+	}
+
+	stored := *copyRepository(repo)
+	slices.Sort(stored.Reviewers)
+	stored.Reviewers = slices.Compact(stored.Reviewers)
+	if stored.Reviewers == nil {
+		stored.Reviewers = []string{}
+	}
+	return stored, nil
+}
+
+type CodeOwnerRepository struct {
+	store *Store
+}
+
+func NewCodeOwnerRepository(store *Store) *CodeOwnerRepository {
+	return &CodeOwnerRepository{store: store}
+}
+
+func (r *CodeOwnerRepository) GetRules(ctx context.Context, repositoryID string) ([]models.CodeOwnerRule, error) {
+	defer r.store.rlock(ctx)()
+
+	return copyRules(r.store.state.codeOwners[repositoryID]), nil
+}
+
+func (r *CodeOwnerRepository) ReplaceRules(
+	ctx context.Context,
+	repositoryID string,
+	rules []models.CodeOwnerRule,
+) error {
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	r.store.state.codeOwners[repositoryID] = copyRules(rules)
+	return nil
+}
+
+func copyRules(rules []models.CodeOwnerRule) []models.CodeOwnerRule {
+	copied := make([]models.CodeOwnerRule, 0, len(rules))
+	for _, rule := range rules {
+		rule.Users = slices.Clone(rule.Users)
+		rule.Teams = slices.Clone(rule.Teams)
+		copied = append(copied, rule)
+	}
+	return copied
+}
@@ -0,0 +1,183 @@
+package inmemory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/repository/repotest"
+)
+
+func TestStore_Simple(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore()
+	teams := NewTeamRepository(store)
+	users := NewUserRepository(store)
+	prs := NewPullRequestRepository(store)
+	txm := NewTxManager(store)
+
+	err := teams.CreateTeam(ctx, &models.Team{Name: "backend", Members: []models.TeamMember{
+		{UserID: "u1", UserName: "Author", IsActive: true},
+		{UserID: "u2", UserName: "Bob", IsActive: true},
+		{UserID: "u3", UserName: "Carol", IsActive: true},
+	}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	err = prs.CreatePR(ctx, &models.PullRequest{
+		ID: "pr1", Name: "Feature", AuthorID: "u1", Status: models.PRStatusOpen,
+		Reviewers: []string{"u2"}, CreatedAt: time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	t.Run("Failed transaction is rolled back", func(t *testing.T) {
+		failure := errors.New("boom")
+		err := txm.WithinTx(ctx, func(ctx context.Context) error {
+			if _, err := users.SetUserIsActive(ctx, "u3", false); err != nil {
+				return err
+			}
+			if err := prs.AddReviewer(ctx, "pr1", "u3"); err != nil {
+				return err
+			}
+			return failure
+		})
+		if !errors.Is(err, failure) {
+			t.Fatalf("Expected the fn error, got %v", err)
+		}
+
+		user, _ := users.GetUser(ctx, "u3")
+		if !user.IsActive {
+			t.Error("Expected deactivation to be rolled back")
+		}
+		pr, _ := prs.GetByID(ctx, "pr1")
+		if len(pr.Reviewers) != 1 {
+			t.Errorf("Expected reviewer addition to be rolled back, got %v", pr.Reviewers)
+		}
+	})
+
+	t.Run("Deactivation picks a replacement reading the store", func(t *testing.T) {
+		pick := func(ctx context.Context, review models.ReviewToUpdate) (string, bool, error) {
+			if _, err := users.GetOpenReviewCounts(ctx, review.TeamName); err != nil {
+				return "", false, err
+			}
+			return "u3", false, nil
+		}
+		if err := users.DeactivateUsers(ctx, []string{"u2"}, pick); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		pr, _ := prs.GetByID(ctx, "pr1")
+		if len(pr.Reviewers) != 1 || pr.Reviewers[0] != "u3" {
+			t.Errorf("Expected u2 to be replaced by u3, got %v", pr.Reviewers)
+		}
+	})
+
+	t.Run("Concurrent writes keep reviewers unique", func(t *testing.T) {
+		var members []models.TeamMember
+		for i := range 20 {
+			members = append(members, models.TeamMember{UserID: fmt.Sprintf("m%d", i), IsActive: true})
+		}
+		if err := teams.CreateTeam(ctx, &models.Team{Name: "many", Members: members}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		var wg sync.WaitGroup
+		for i := range 40 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_ = prs.AddReviewer(ctx, "pr1", members[i%len(members)].UserID)
+				_, _ = prs.GetByReviewerID(ctx, members[i%len(members)].UserID)
+			}()
+		}
+		wg.Wait()
+
+		pr, _ := prs.GetByID(ctx, "pr1")
+		if len(pr.Reviewers) != 21 {
+			t.Errorf("Expected 21 distinct reviewers, got %d", len(pr.Reviewers))
+		}
+	})
+
+	t.Run("Overdue reviews follow the repository team SLA", func(t *testing.T) {
+		err := teams.CreateTeam(ctx, &models.Team{Name: "platform", Members: []models.TeamMember{
+			{UserID: "p1", IsActive: true},
+		}})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		settings := models.DefaultTeamSettings()
+		settings.ReviewSLAMinutes = 30
+		if err := teams.UpsertSettings(ctx, "platform", settings); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if err := NewRepositoryRepository(store).CreateRepository(ctx, &models.Repository{ID: "acme/infra", TeamName: "platform"}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		err = prs.CreatePR(ctx, &models.PullRequest{
+			ID: "pr-infra", Name: "Infra", AuthorID: "u1", Status: models.PRStatusOpen, RepositoryID: "acme/infra",
+			Reviewers: []string{"p1"}, CreatedAt: time.Now(),
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		overdue, err := prs.MarkOverdue(ctx, time.Now().Add(time.Hour))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(overdue) != 1 || overdue[0].PRID != "pr-infra" || overdue[0].TeamName != "platform" {
+			t.Errorf("Expected pr-infra to be overdue for platform, got %+v", overdue)
+		}
+	})
+
+	t.Run("Concurrent merges write one event", func(t *testing.T) {
+		err := prs.CreatePR(ctx, &models.PullRequest{
+			ID: "pr2", Name: "Fix", AuthorID: "u1", Status: models.PRStatusOpen, CreatedAt: time.Now(),
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		var wg sync.WaitGroup
+		for range 10 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				event := models.NewEvent(models.EventPRMerged, models.EventPayload{PRID: "pr2"})
+				_, _ = prs.MergePR(ctx, "pr2", nil, event)
+			}()
+		}
+		wg.Wait()
+
+		merged := 0
+		_, err = NewOutboxRepository(store).Dispatch(ctx, 100, func(ctx context.Context, event models.Event) error {
+			if event.Type == models.EventPRMerged && event.Payload.PRID == "pr2" {
+				merged++
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if merged != 1 {
+			t.Errorf("Expected one pr.merged event, got %d", merged)
+		}
+	})
+}
+
+func TestStore_Contract(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Repositories {
+		store := NewStore()
+		return repotest.Repositories{
+			Teams: NewTeamRepository(store),
+			Users: NewUserRepository(store),
+			PRs:   NewPullRequestRepository(store),
+		}
+	})
+}
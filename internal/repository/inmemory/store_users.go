@@ -0,0 +1,392 @@
+package inmemory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/repository"
+)
+
+type UserRepository struct {
+	store *Store
+}
+
+func NewUserRepository(store *Store) *UserRepository {
+	return &UserRepository{store: store}
+}
+
+func (r *UserRepository) GetUser(ctx context.Context, id string) (*models.User, error) {
+	defer r.store.rlock(ctx)()
+
+	row, ok := r.store.state.users[id]
+	if !ok {
+		return nil, fmt.Errorf("user not found: %w", models.ErrNotFound)
+	}
+	user := row.User
+	return &user, nil
+}
+
+func (r *UserRepository) SetUserIsActive(ctx context.Context, id string, isActive bool) (*models.User, error) {
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	row, ok := r.store.state.users[id]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	row.IsActive = isActive
+	r.store.state.users[id] = row
+	user := row.User
+	return &user, nil
+}
+
+func (r *UserRepository) GetActiveUsersByTeam(
+	ctx context.Context,
+	teamName string,
+	from, to time.Time,
+) ([]models.User, error) {
+	defer r.store.rlock(ctx)()
+	st := r.store.state
+
+	users := []models.User{}
+	for _, row := range st.sortedUsers() {
+		if row.TeamName == teamName && row.IsActive && !st.absent(row.ID, from, to) {
+			users = append(users, row.User)
+		}
+	}
+	return users, nil
+}
+
+func (r *UserRepository) GetOpenReviewCounts(ctx context.Context, teamName string) (map[string]int, error) {
+	defer r.store.rlock(ctx)()
+	st := r.store.state
+
+	counts := make(map[string]int)
+	for _, row := range st.users {
+		if row.TeamName == teamName {
+			counts[row.ID] = 0
+		}
+	}
+	for prID, assignments := range st.reviewers {
+		if st.prs[prID].Status != models.PRStatusOpen {
+			continue
+		}
+		for _, a := range assignments {
+			if _, ok := counts[a.reviewerID]; ok {
+				counts[a.reviewerID]++
+			}
+		}
+	}
+	return counts, nil
+}
+
+func (r *UserRepository) LinkIdentity(ctx context.Context, identity *models.UserIdentity) error {
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	if _, ok := r.store.state.users[identity.UserID]; !ok {
+		return fmt.Errorf("user %s: %w", identity.UserID, models.ErrNotFound)
+	}
+	r.store.state.identities[identity.Provider+"/"+identity.Login] = identity.UserID
+	return nil
+}
+
+func (r *UserRepository) GetUserByIdentity(ctx context.Context, provider, login string) (*models.User, error) {
+	defer r.store.rlock(ctx)()
+
+	row, ok := r.store.state.users[r.store.state.identities[provider+"/"+login]]
+	if !ok {
+		return nil, fmt.Errorf("identity %s/%s: %w", provider, login, models.ErrNotFound)
+	}
+	user := row.User
+	return &user, nil
+}
+
+func (r *UserRepository) SetChatHandle(ctx context.Context, userID, handle string) error {
+	return r.update(ctx, userID, func(row *userRow) { row.chatHandle = handle })
+}
+
+func (r *UserRepository) GetChatHandles(ctx context.Context, userIDs []string) (map[string]string, error) {
+	defer r.store.rlock(ctx)()
+
+	handles := make(map[string]string)
+	for _, id := range userIDs {
+		if row, ok := r.store.state.users[id]; ok && row.chatHandle != "" {
+			handles[id] = row.chatHandle
+		}
+	}
+	return handles, nil
+}
+
+func (r *UserRepository) SetQuietHours(ctx context.Context, userID string, quiet models.QuietHours) error {
+	return r.update(ctx, userID, func(row *userRow) { row.quietHours = quiet })
+}
+
+func (r *UserRepository) GetDigestRecipients(ctx context.Context) ([]models.DigestRecipient, error) {
+	defer r.store.rlock(ctx)()
+
+	recipients := []models.DigestRecipient{}
+	for _, row := range r.store.state.sortedUsers() {
+		if row.IsActive {
+			recipients = append(recipients, models.DigestRecipient{
+				UserID:       row.ID,
+				TeamName:     row.TeamName,
+				QuietHours:   row.quietHours,
+				LastDigestAt: row.lastDigestAt,
+			})
+		}
+	}
+	return recipients, nil
+}
+
+func (r *UserRepository) RecordDigest(ctx context.Context, userID string, at time.Time, event models.Event) error {
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+	st := r.store.state
+
+	if row, ok := st.users[userID]; ok {
+		row.lastDigestAt = &at
+		st.users[userID] = row
+	}
+	st.appendOutbox([]models.Event{event})
+	return nil
+}
+
+func (r *UserRepository) CreateAbsence(ctx context.Context, absence *models.Absence) error {
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+	st := r.store.state
+
+	if _, ok := st.users[absence.UserID]; !ok {
+		return models.ErrNotFound
+	}
+	absence.ID = st.nextID()
+	absence.CreatedAt = time.Now()
+	stored := *absence
+	stored.UID = ""
+	st.absences[absence.ID] = absenceRow{Absence: stored}
+	return nil
+}
+
+func (r *UserRepository) ListAbsences(ctx context.Context, userID string) ([]models.Absence, error) {
+	defer r.store.rlock(ctx)()
+
+	absences := []models.Absence{}
+	for _, row := range r.store.state.absences {
+		if row.UserID == userID {
+			absences = append(absences, row.Absence)
+		}
+	}
+	sortAbsences(absences)
+	return absences, nil
+}
+
+func (r *UserRepository) DeleteAbsence(ctx context.Context, id int64) error {
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	if _, ok := r.store.state.absences[id]; !ok {
+		return models.ErrNotFound
+	}
+	delete(r.store.state.absences, id)
+	return nil
+}
+
+// UpsertAbsence keeps the handled mark only if the absence still starts at the
+// same time for the same user, so a moved absence hands reviews over again.
+func (r *UserRepository) UpsertAbsence(ctx context.Context, absence *models.Absence) (bool, error) {
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+	st := r.store.state
+
+	if _, ok := st.users[absence.UserID]; !ok {
+		return false, models.ErrNotFound
+	}
+
+	if row, ok := st.absenceByUID(absence.UID); ok {
+		absence.ID = row.ID
+		absence.CreatedAt = row.CreatedAt
+		if row.UserID != absence.UserID || !row.StartsAt.Equal(absence.StartsAt) {
+			row.handledAt = nil
+		}
+		row.Absence = *absence
+		st.absences[absence.ID] = row
+		return false, nil
+	}
+
+	absence.ID = st.nextID()
+	absence.CreatedAt = time.Now()
+	st.absences[absence.ID] = absenceRow{Absence: *absence}
+	return true, nil
+}
+
+func (r *UserRepository) DeleteAbsenceByUID(ctx context.Context, uid string) error {
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	row, ok := r.store.state.absenceByUID(uid)
+	if !ok {
+		return models.ErrNotFound
+	}
+	delete(r.store.state.absences, row.ID)
+	return nil
+}
+
+func (r *UserRepository) GetStartedAbsences(ctx context.Context, now time.Time) ([]models.Absence, error) {
+	defer r.store.rlock(ctx)()
+
+	absences := []models.Absence{}
+	for _, row := range r.store.state.absences {
+		if row.handledAt == nil && !row.StartsAt.After(now) && row.EndsAt.After(now) {
+			absences = append(absences, row.Absence)
+		}
+	}
+	sortAbsences(absences)
+	return absences, nil
+}
+
+func (r *UserRepository) MarkAbsenceHandled(ctx context.Context, id int64, at time.Time) error {
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	if row, ok := r.store.state.absences[id]; ok {
+		row.handledAt = &at
+		r.store.state.absences[id] = row
+	}
+	return nil
+}
+
+// DeactivateUsers calls pick while holding the store lock; pick gets a context
+// that lets it read the store.
+func (r *UserRepository) DeactivateUsers(
+	ctx context.Context,
+	userIDs []string,
+	pick repository.ReviewerPicker,
+	events ...models.Event,
+) error {
+	ctx, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	restore := r.store.savepoint()
+	if err := r.deactivate(ctx, userIDs, pick, events); err != nil {
+		restore()
+		return err
+	}
+	return nil
+}
+
+func (r *UserRepository) deactivate(
+	ctx context.Context,
+	userIDs []string,
+	pick repository.ReviewerPicker,
+	events []models.Event,
+) error {
+	st := r.store.state
+	for _, id := range userIDs {
+		if row, ok := st.users[id]; ok {
+			row.IsActive = false
+			st.users[id] = row
+		}
+	}
+
+	var reviews []models.ReviewToUpdate
+	for _, pr := range st.sortedPRs() {
+		if pr.Status != models.PRStatusOpen {
+			continue
+		}
+		for _, a := range st.reviewers[pr.ID] {
+			if slices.Contains(userIDs, a.reviewerID) {
+				reviews = append(reviews, models.ReviewToUpdate{
+					PRID:          pr.ID,
+					OldReviewerID: a.reviewerID,
+					AuthorID:      pr.AuthorID,
+					TeamName:      st.users[pr.AuthorID].TeamName,
+					RepositoryID:  pr.RepositoryID,
+				})
+			}
+		}
+	}
+
+	now := time.Now()
+	for _, rev := range reviews {
+		for _, a := range st.reviewers[rev.PRID] {
+			rev.Reviewers = append(rev.Reviewers, a.reviewerID)
+		}
+
+		newReviewerID, fromFallback, err := pick(ctx, rev)
+		if err != nil {
+			return fmt.Errorf("failed to pick candidate: %w", err)
+		}
+
+		assignments := st.reviewers[rev.PRID]
+		i := slices.IndexFunc(assignments, func(a assignment) bool { return a.reviewerID == rev.OldReviewerID })
+		if newReviewerID == "" {
+			st.reviewers[rev.PRID] = slices.Delete(assignments, i, i+1)
+			continue
+		}
+		if _, ok := st.users[newReviewerID]; !ok {
+			return fmt.Errorf("reviewer %s: %w", newReviewerID, models.ErrNotFound)
+		}
+		if st.assigned(rev.PRID, newReviewerID) {
+			return fmt.Errorf("reviewer already assigned: %w", models.ErrAlreadyExists)
+		}
+		assignments[i] = assignment{reviewerID: newReviewerID, fromFallback: fromFallback, assignedAt: now}
+		events = append(events, models.NewEvent(models.EventReviewerReassigned,
+			rev.ReassignedPayload(newReviewerID, fromFallback)))
+	}
+
+	st.appendOutbox(events)
+	return nil
+}
+
+func (r *UserRepository) update(ctx context.Context, userID string, change func(row *userRow)) error {
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	row, ok := r.store.state.users[userID]
+	if !ok {
+		return models.ErrNotFound
+	}
+	change(&row)
+	r.store.state.users[userID] = row
+	return nil
+}
+
+func (s *state) sortedUsers() []userRow {
+	rows := make([]userRow, 0, len(s.users))
+	for _, row := range s.users {
+		rows = append(rows, row)
+	}
+	slices.SortFunc(rows, func(a, b userRow) int { return strings.Compare(a.ID, b.ID) })
+	return rows
+}
+
+// absent reports whether the user has an absence overlapping [from, to).
+func (s *state) absent(userID string, from, to time.Time) bool {
+	for _, row := range s.absences {
+		if row.UserID == userID && row.StartsAt.Before(to) && row.EndsAt.After(from) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *state) absenceByUID(uid string) (absenceRow, bool) {
+	for _, row := range s.absences {
+		if row.UID != "" && row.UID == uid {
+			return row, true
+		}
+	}
+	return absenceRow{}, false
+}
+
+func sortAbsences(absences []models.Absence) {
+	slices.SortFunc(absences, func(a, b models.Absence) int {
+		return cmp.Or(a.StartsAt.Compare(b.StartsAt), cmp.Compare(a.ID, b.ID))
+	})
+}
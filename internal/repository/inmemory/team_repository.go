@@ -7,12 +7,14 @@ import (
 )
 
 type TestTeamRepo struct {
-	Teams map[string]*models.Team
+	Teams    map[string]*models.Team
+	Settings map[string]*models.TeamSettings
 }
 
 func NewTestTeamRepo() *TestTeamRepo {
 	return &TestTeamRepo{
-		Teams: make(map[string]*models.Team),
+		Teams:    make(map[string]*models.Team),
+		Settings: make(map[string]*models.TeamSettings),
 	}
 }
 
@@ -32,3 +34,23 @@ func (s *TestTeamRepo) GetTeam(ctx context.Context, name string) (*models.Team,
 	}
 	return t, nil
 }
+
+func (s *TestTeamRepo) GetSettings(ctx context.Context, teamName string) (*models.TeamSettings, error) {
+	if _, ok := s.Teams[teamName]; !ok {
+		return nil, models.ErrNotFound
+	}
+	if settings, ok := s.Settings[teamName]; ok {
+		copied := *settings
+		return &copied, nil
+	}
+	return models.DefaultTeamSettings(), nil
+}
+
+func (s *TestTeamRepo) UpsertSettings(ctx context.Context, teamName string, settings *models.TeamSettings) error {
+	if _, ok := s.Teams[teamName]; !ok {
+		return models.ErrNotFound
+	}
+	copied := *settings
+	s.Settings[teamName] = &copied
+	return nil
+}
@@ -2,17 +2,43 @@ package inmemory
 
 import (
 	"context"
+	"slices"
+	"sort"
+	"time"
 
 	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/repository"
 )
 
 type TestUserRepo struct {
-	Users map[string]*models.User
+	Users        map[string]*models.User
+	ReviewCounts map[string]int
+	Identities   map[string]string
+	ChatHandles  map[string]string
+	QuietHours   map[string]models.QuietHours
+	LastDigestAt map[string]time.Time
+	Absences     []models.Absence
+	Handled      map[int64]time.Time
+	Events       []models.Event
+	// OpenReviews are handed over by DeactivateUsers; a picked replacement takes
+	// over the review count and is recorded in Replacements by PR ID, and in
+	// FallbackReplacements when it comes from a fallback team.
+	OpenReviews          []models.ReviewToUpdate
+	Replacements         map[string]string
+	FallbackReplacements map[string]bool
 }
 
 func NewTestUserRepo() *TestUserRepo {
 	return &TestUserRepo{
-		Users: make(map[string]*models.User),
+		Users:                make(map[string]*models.User),
+		ReviewCounts:         make(map[string]int),
+		Identities:           make(map[string]string),
+		ChatHandles:          make(map[string]string),
+		QuietHours:           make(map[string]models.QuietHours),
+		LastDigestAt:         make(map[string]time.Time),
+		Handled:              make(map[int64]time.Time),
+		Replacements:         make(map[string]string),
+		FallbackReplacements: make(map[string]bool),
 	}
 }
 
@@ -34,21 +60,215 @@ func (s *TestUserRepo) SetUserIsActive(ctx context.Context, id string, isActive
 	return u, nil
 }
 
-func (s *TestUserRepo) DeactivateUsers(ctx context.Context, userIDs []string) error {
+func (s *TestUserRepo) DeactivateUsers(
+	ctx context.Context,
+	userIDs []string,
+	pick repository.ReviewerPicker,
+	events ...models.Event,
+) error {
 	for _, id := range userIDs {
 		if u, ok := s.Users[id]; ok {
 			u.IsActive = false
 		}
 	}
+
+	for _, rev := range s.OpenReviews {
+		if !slices.Contains(userIDs, rev.OldReviewerID) {
+			continue
+		}
+		newReviewerID, fromFallback, err := pick(ctx, rev)
+		if err != nil {
+			return err
+		}
+		if newReviewerID != "" {
+			s.ReviewCounts[rev.OldReviewerID]--
+			s.ReviewCounts[newReviewerID]++
+			s.Replacements[rev.PRID] = newReviewerID
+			if fromFallback {
+				s.FallbackReplacements[rev.PRID] = true
+			}
+		}
+	}
+
+	s.Events = append(s.Events, events...)
 	return nil
 }
 
-func (r *TestUserRepo) GetActiveUsersByTeam(ctx context.Context, teamName string) ([]models.User, error) {
+func (r *TestUserRepo) GetActiveUsersByTeam(
+	ctx context.Context,
+	teamName string,
+	from, to time.Time,
+) ([]models.User, error) {
 	var result []models.User
 	for _, u := range r.Users {
-		if u.TeamName == teamName && u.IsActive {
+		if u.TeamName == teamName && u.IsActive && !r.absent(u.ID, from, to) {
 			result = append(result, *u)
 		}
 	}
 	return result, nil
 }
+
+func (r *TestUserRepo) absent(userID string, from, to time.Time) bool {
+	for _, a := range r.Absences {
+		if a.UserID == userID && a.StartsAt.Before(to) && a.EndsAt.After(from) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *TestUserRepo) CreateAbsence(ctx context.Context, absence *models.Absence) error {
+	if _, ok := r.Users[absence.UserID]; !ok {
+		return models.ErrNotFound
+	}
+	absence.ID = 1
+	if n := len(r.Absences); n > 0 {
+		absence.ID = r.Absences[n-1].ID + 1
+	}
+	absence.CreatedAt = time.Now()
+	r.Absences = append(r.Absences, *absence)
+	return nil
+}
+
+func (r *TestUserRepo) ListAbsences(ctx context.Context, userID string) ([]models.Absence, error) {
+	var result []models.Absence
+	for _, a := range r.Absences {
+		if a.UserID == userID {
+			result = append(result, a)
+		}
+	}
+	return result, nil
+}
+
+func (r *TestUserRepo) DeleteAbsence(ctx context.Context, id int64) error {
+	for i, a := range r.Absences {
+		if a.ID == id {
+			r.Absences = append(r.Absences[:i], r.Absences[i+1:]...)
+			return nil
+		}
+	}
+	return models.ErrNotFound
+}
+
+func (r *TestUserRepo) UpsertAbsence(ctx context.Context, absence *models.Absence) (bool, error) {
+	if _, ok := r.Users[absence.UserID]; !ok {
+		return false, models.ErrNotFound
+	}
+	for i, a := range r.Absences {
+		if a.UID == absence.UID {
+			absence.ID = a.ID
+			absence.CreatedAt = a.CreatedAt
+			r.Absences[i] = *absence
+			if a.UserID != absence.UserID || !a.StartsAt.Equal(absence.StartsAt) {
+				delete(r.Handled, a.ID)
+			}
+			return false, nil
+		}
+	}
+	return true, r.CreateAbsence(ctx, absence)
+}
+
+func (r *TestUserRepo) DeleteAbsenceByUID(ctx context.Context, uid string) error {
+	for _, a := range r.Absences {
+		if a.UID == uid {
+			return r.DeleteAbsence(ctx, a.ID)
+		}
+	}
+	return models.ErrNotFound
+}
+
+func (r *TestUserRepo) GetStartedAbsences(ctx context.Context, now time.Time) ([]models.Absence, error) {
+	var result []models.Absence
+	for _, a := range r.Absences {
+		if _, handled := r.Handled[a.ID]; handled {
+			continue
+		}
+		if !a.StartsAt.After(now) && a.EndsAt.After(now) {
+			result = append(result, a)
+		}
+	}
+	return result, nil
+}
+
+func (r *TestUserRepo) MarkAbsenceHandled(ctx context.Context, id int64, at time.Time) error {
+	r.Handled[id] = at
+	return nil
+}
+
+func (r *TestUserRepo) GetOpenReviewCounts(ctx context.Context, teamName string) (map[string]int, error) {
+	counts := make(map[string]int)
+	for _, u := range r.Users {
+		if u.TeamName == teamName {
+			counts[u.ID] = r.ReviewCounts[u.ID]
+		}
+	}
+	return counts, nil
+}
+
+func (r *TestUserRepo) LinkIdentity(ctx context.Context, identity *models.UserIdentity) error {
+	if _, ok := r.Users[identity.UserID]; !ok {
+		return models.ErrNotFound
+	}
+	r.Identities[identity.Provider+"/"+identity.Login] = identity.UserID
+	return nil
+}
+
+func (r *TestUserRepo) GetUserByIdentity(ctx context.Context, provider, login string) (*models.User, error) {
+	id, ok := r.Identities[provider+"/"+login]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	return r.GetUser(ctx, id)
+}
+
+func (r *TestUserRepo) SetChatHandle(ctx context.Context, userID, handle string) error {
+	if _, ok := r.Users[userID]; !ok {
+		return models.ErrNotFound
+	}
+	r.ChatHandles[userID] = handle
+	return nil
+}
+
+func (r *TestUserRepo) GetChatHandles(ctx context.Context, userIDs []string) (map[string]string, error) {
+	handles := make(map[string]string)
+	for _, id := range userIDs {
+		if handle := r.ChatHandles[id]; handle != "" {
+			handles[id] = handle
+		}
+	}
+	return handles, nil
+}
+
+func (r *TestUserRepo) SetQuietHours(ctx context.Context, userID string, quiet models.QuietHours) error {
+	if _, ok := r.Users[userID]; !ok {
+		return models.ErrNotFound
+	}
+	r.QuietHours[userID] = quiet
+	return nil
+}
+
+func (r *TestUserRepo) GetDigestRecipients(ctx context.Context) ([]models.DigestRecipient, error) {
+	var result []models.DigestRecipient
+	for _, u := range r.Users {
+		if !u.IsActive {
+			continue
+		}
+		recipient := models.DigestRecipient{
+			UserID:     u.ID,
+			TeamName:   u.TeamName,
+			QuietHours: r.QuietHours[u.ID],
+		}
+		if at, ok := r.LastDigestAt[u.ID]; ok {
+			recipient.LastDigestAt = &at
+		}
+		result = append(result, recipient)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].UserID < result[j].UserID })
+	return result, nil
+}
+
+func (r *TestUserRepo) RecordDigest(ctx context.Context, userID string, at time.Time, event models.Event) error {
+	r.LastDigestAt[userID] = at
+	r.Events = append(r.Events, event)
+	return nil
+}
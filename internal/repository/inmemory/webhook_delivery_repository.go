@@ -0,0 +1,29 @@
+package inmemory
+
+import (
+	"context"
+)
+
+type TestWebhookDeliveryRepo struct {
+	Deliveries map[string]bool
+}
+
+func NewTestWebhookDeliveryRepo() *TestWebhookDeliveryRepo {
+	return &TestWebhookDeliveryRepo{
+		Deliveries: make(map[string]bool),
+	}
+}
+
+func (r *TestWebhookDeliveryRepo) MarkReceived(ctx context.Context, provider, deliveryID string) (bool, error) {
+	key := provider + "/" + deliveryID
+	if r.Deliveries[key] {
+		return false, nil
+	}
+	r.Deliveries[key] = true
+	return true, nil
+}
+
+func (r *TestWebhookDeliveryRepo) Forget(ctx context.Context, provider, deliveryID string) error {
+	delete(r.Deliveries, provider+"/"+deliveryID)
+	return nil
+}
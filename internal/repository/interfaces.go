@@ -2,28 +2,149 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
 )
 
+// ReviewerPicker chooses a replacement for review.OldReviewerID and reports whether
+// it comes from a fallback team. An empty result means the reviewer is removed
+// without a replacement. Repositories read with the ctx passed to it see the
+// deactivation and the replacements made so far.
+type ReviewerPicker func(ctx context.Context, review models.ReviewToUpdate) (string, bool, error)
+
+// MergeGuard decides whether pr may be merged. MergePR calls it with the locked
+// current state of the PR, and ctx runs reads in the merge transaction.
+type MergeGuard func(ctx context.Context, pr *models.PullRequest) error
+
 type TeamRepositoryInterface interface {
 	CreateTeam(ctx context.Context, team *models.Team) error
 	GetTeam(ctx context.Context, name string) (*models.Team, error)
+	GetSettings(ctx context.Context, teamName string) (*models.TeamSettings, error)
+	UpsertSettings(ctx context.Context, teamName string, settings *models.TeamSettings) error
+}
+
+type RepositoryRepositoryInterface interface {
+	CreateRepository(ctx context.Context, repo *models.Repository) error
+	UpdateRepository(ctx context.Context, repo *models.Repository) error
+	GetRepository(ctx context.Context, id string) (*models.Repository, error)
+	ListRepositories(ctx context.Context, teamName string) ([]models.Repository, error)
 }
 
 type UserRepositoryInterface interface {
 	GetUser(ctx context.Context, id string) (*models.User, error)
 	SetUserIsActive(ctx context.Context, id string, isActive bool) (*models.User, error)
-	GetActiveUsersByTeam(ctx context.Context, teamName string) ([]models.User, error)
-	DeactivateUsers(ctx context.Context, userIDs []string) error
+	// GetActiveUsersByTeam returns active team members without an absence overlapping [from, to).
+	GetActiveUsersByTeam(ctx context.Context, teamName string, from, to time.Time) ([]models.User, error)
+	GetOpenReviewCounts(ctx context.Context, teamName string) (map[string]int, error)
+	LinkIdentity(ctx context.Context, identity *models.UserIdentity) error
+	GetUserByIdentity(ctx context.Context, provider, login string) (*models.User, error)
+	SetChatHandle(ctx context.Context, userID, handle string) error
+	// GetChatHandles returns handles of the given users that have one set.
+	GetChatHandles(ctx context.Context, userIDs []string) (map[string]string, error)
+	SetQuietHours(ctx context.Context, userID string, quiet models.QuietHours) error
+	GetDigestRecipients(ctx context.Context) ([]models.DigestRecipient, error)
+	// RecordDigest stamps the last digest time and writes the digest event to the outbox together.
+	RecordDigest(ctx context.Context, userID string, at time.Time, event models.Event) error
+	CreateAbsence(ctx context.Context, absence *models.Absence) error
+	ListAbsences(ctx context.Context, userID string) ([]models.Absence, error)
+	DeleteAbsence(ctx context.Context, id int64) error
+	// UpsertAbsence creates or updates the absence with the same UID and reports
+	// whether it was created.
+	UpsertAbsence(ctx context.Context, absence *models.Absence) (bool, error)
+	DeleteAbsenceByUID(ctx context.Context, uid string) error
+	// GetStartedAbsences returns absences in progress at now that were not marked handled yet.
+	GetStartedAbsences(ctx context.Context, now time.Time) ([]models.Absence, error)
+	MarkAbsenceHandled(ctx context.Context, id int64, at time.Time) error
+	// DeactivateUsers writes events to the outbox together with the change and adds
+	// a reviewer.reassigned event for every replacement made by pick.
+	DeactivateUsers(ctx context.Context, userIDs []string, pick ReviewerPicker, events ...models.Event) error
 }
 
+// Mutating methods write events to the outbox in the same transaction as the change.
 type PullRequestRepositoryInterface interface {
-	CreatePR(ctx context.Context, pr *models.PullRequest) error
-	MergePR(ctx context.Context, id string) (*models.PullRequest, error)
-	ReassignReviewer(ctx context.Context, id, oldUserID, newUserID string) error
+	CreatePR(ctx context.Context, pr *models.PullRequest, events ...models.Event) error
+	// MergePR merges the PR unless guard, if set, returns an error. The events are
+	// written only if this call changed the status, so a repeated merge is silent.
+	MergePR(ctx context.Context, id string, guard MergeGuard, events ...models.Event) (*models.PullRequest, error)
+	// ReassignReviewer replaces oldUserID only if the PR still has the status and
+	// reviewers of snapshot, and returns models.ErrPRChanged otherwise.
+	ReassignReviewer(
+		ctx context.Context,
+		snapshot *models.PullRequest,
+		oldUserID, newUserID string,
+		fromFallback bool,
+		events ...models.Event,
+	) error
+	// AddReviewer and RemoveReviewer lock the PR and return the error of
+	// models.PRStatus.ReviewersEditable if its reviewers may not be changed.
+	AddReviewer(ctx context.Context, id, userID string, events ...models.Event) error
+	RemoveReviewer(ctx context.Context, id, userID string, events ...models.Event) error
 	GetByReviewerID(ctx context.Context, userID string) ([]*models.PullRequestShort, error)
 	GetByID(ctx context.Context, id string) (*models.PullRequest, error)
+	// UpdateStatus rechecks the current status against from under the PR lock and
+	// returns ErrPRMerged or ErrInvalidStatus if it no longer fits.
+	UpdateStatus(
+		ctx context.Context,
+		id string,
+		status models.PRStatus,
+		from []models.PRStatus,
+		addReviewers, fallbackReviewers []string,
+		events ...models.Event,
+	) (*models.PullRequest, error)
+	AddReview(ctx context.Context, review *models.Review) error
+	GetReviews(ctx context.Context, prID string) ([]models.Review, error)
+	// MarkOverdue flags OPEN assignments without a verdict past the team SLA and returns
+	// the newly flagged ones. AddReview clears the flag of the reviewer.
+	MarkOverdue(ctx context.Context, now time.Time) ([]models.OverdueReview, error)
+	// GetOverdue lists flagged assignments of OPEN PRs; an empty teamName means all teams.
+	GetOverdue(ctx context.Context, teamName string) ([]models.OverdueReview, error)
+}
+
+type WebhookDeliveryRepositoryInterface interface {
+	// MarkReceived records the delivery and reports false if it was already recorded.
+	MarkReceived(ctx context.Context, provider, deliveryID string) (bool, error)
+	Forget(ctx context.Context, provider, deliveryID string) error
+}
+
+type NotificationRepositoryInterface interface {
+	CreateSubscription(ctx context.Context, sub *models.Subscription) error
+	ListSubscriptions(ctx context.Context) ([]models.Subscription, error)
+	DeleteSubscription(ctx context.Context, id int64) error
+	// EnqueueDeliveries creates a pending delivery for every subscription interested in the event.
+	EnqueueDeliveries(ctx context.Context, event models.Event) error
+	// ClaimDueDeliveries returns pending deliveries due at now and postpones them by lease,
+	// so that concurrent workers do not pick the same ones.
+	ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.Delivery, error)
+	MarkDelivered(ctx context.Context, id int64, at time.Time) error
+	MarkFailed(ctx context.Context, id int64, lastError string, nextAttemptAt time.Time, dead bool) error
+	ListDeadLetters(ctx context.Context) ([]models.Delivery, error)
+	ReplayDeadLetter(ctx context.Context, id int64, at time.Time) error
+}
+
+type OutboxRepositoryInterface interface {
+	// Dispatch locks up to limit undispatched events, passes them to handle in order
+	// and marks the handled ones as dispatched. It stops at the first handle error.
+	Dispatch(ctx context.Context, limit int, handle func(ctx context.Context, event models.Event) error) (int, error)
+}
+
+type TxManager interface {
+	// WithinTx runs fn in one transaction shared by all repositories called with
+	// the context passed to fn. An error from fn rolls the transaction back.
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type LockerInterface interface {
+	// TryWithLock runs fn while holding the named lock shared by all replicas.
+	// It reports false without running fn if another holder has the lock.
+	TryWithLock(ctx context.Context, name string, fn func(ctx context.Context) error) (bool, error)
+}
+
+type CodeOwnerRepositoryInterface interface {
+	// GetRules returns rules of the repository in evaluation order; later rules take
+	// precedence. An empty repositoryID holds the default rules.
+	GetRules(ctx context.Context, repositoryID string) ([]models.CodeOwnerRule, error)
+	ReplaceRules(ctx context.Context, repositoryID string, rules []models.CodeOwnerRule) error
 }
 
 type StatsRepositoryInterface interface {
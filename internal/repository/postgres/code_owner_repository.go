@@ -0,0 +1,77 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+)
+
+type CodeOwnerRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewCodeOwnerRepository(db *pgxpool.Pool) *CodeOwnerRepository {
+	return &CodeOwnerRepository{db: db}
+}
+
+func (r *CodeOwnerRepository) GetRules(ctx context.Context, repositoryID string) ([]models.CodeOwnerRule, error) {
+	query := `
+        SELECT pattern, user_ids, team_names
+        FROM code_owner_rules
+        WHERE repository_id = $1
+        ORDER BY position
+    `
+	rows, err := conn(ctx, r.db).Query(ctx, query, repositoryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query code owner rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.CodeOwnerRule, error) {
+		var rule models.CodeOwnerRule
+		err := row.Scan(&rule.Pattern, &rule.Users, &rule.Teams)
+		return rule, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect code owner rules: %w", err)
+	}
+
+	return rules, nil
+}
+
+func (r *CodeOwnerRepository) ReplaceRules(
+	ctx context.Context,
+	repositoryID string,
+	rules []models.CodeOwnerRule,
+) error {
+	tx, err := begin(ctx, r.db)
+	if err != nil {
+		return fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	if _, err := tx.Exec(ctx, `DELETE FROM code_owner_rules WHERE repository_id = $1`, repositoryID); err != nil {
+		return fmt.Errorf("failed to clear code owner rules: %w", err)
+	}
+
+	query := `
+        INSERT INTO code_owner_rules (repository_id, position, pattern, user_ids, team_names)
+        VALUES ($1, $2, $3, $4, $5)
+    `
+	for i, rule := range rules {
+		if _, err := tx.Exec(ctx, query, repositoryID, i, rule.Pattern, rule.Users, rule.Teams); err != nil {
+			return fmt.Errorf("failed to insert code owner rule: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit tx: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package postgres
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/repository/repotest"
+)
+
+// TestRepositories_Contract needs a migrated database in TEST_POSTGRES_DSN,
+// whose tables it truncates before every check.
+func TestRepositories_Contract(t *testing.T) {
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN is not set")
+	}
+
+	ctx := context.Background()
+	db, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	t.Cleanup(db.Close)
+
+	repotest.Run(t, func(t *testing.T) repotest.Repositories {
+		query := `TRUNCATE teams, users, pull_requests, pr_reviewers, pr_reviews, outbox RESTART IDENTITY CASCADE`
+		if _, err := db.Exec(ctx, query); err != nil {
+			t.Fatalf("Failed to truncate tables: %v", err)
+		}
+
+		return repotest.Repositories{
+			Teams: NewTeamRepository(db),
+			Users: NewUserRepository(db),
+			PRs:   NewPullRequestRepository(db),
+		}
+	})
+}
@@ -0,0 +1,216 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+)
+
+type NotificationRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewNotificationRepository(db *pgxpool.Pool) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+func (r *NotificationRepository) CreateSubscription(ctx context.Context, sub *models.Subscription) error {
+	query := `
+        INSERT INTO webhook_subscriptions (url, secret, event_types)
+        VALUES ($1, $2, $3)
+        RETURNING id, created_at
+    `
+	eventTypes := sub.EventTypes
+	if eventTypes == nil {
+		eventTypes = []string{}
+	}
+	if err := conn(ctx, r.db).QueryRow(ctx, query, sub.URL, sub.Secret, eventTypes).Scan(&sub.ID, &sub.CreatedAt); err != nil {
+		return fmt.Errorf("failed to insert subscription: %w", err)
+	}
+	return nil
+}
+
+func (r *NotificationRepository) ListSubscriptions(ctx context.Context) ([]models.Subscription, error) {
+	query := `
+        SELECT id, url, secret, event_types, created_at
+        FROM webhook_subscriptions
+        ORDER BY id
+    `
+	rows, err := conn(ctx, r.db).Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	subs, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.Subscription, error) {
+		var sub models.Subscription
+		err := row.Scan(&sub.ID, &sub.URL, &sub.Secret, &sub.EventTypes, &sub.CreatedAt)
+		return sub, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect subscriptions: %w", err)
+	}
+
+	return subs, nil
+}
+
+func (r *NotificationRepository) DeleteSubscription(ctx context.Context, id int64) error {
+	res, err := conn(ctx, r.db).Exec(ctx, `DELETE FROM webhook_subscriptions WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete subscription: %w", err)
+	}
+	if res.RowsAffected() == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+func (r *NotificationRepository) EnqueueDeliveries(ctx context.Context, event models.Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	query := `
+        INSERT INTO outbound_deliveries (subscription_id, event_id, event_type, event, next_attempt_at)
+        SELECT id, $1, $2, $3, $4
+        FROM webhook_subscriptions
+        WHERE cardinality(event_types) = 0 OR $2 = ANY(event_types)
+        ON CONFLICT (subscription_id, event_id) DO NOTHING
+    `
+	if _, err := conn(ctx, r.db).Exec(ctx, query, event.ID, event.Type, body, event.OccurredAt); err != nil {
+		return fmt.Errorf("failed to enqueue deliveries: %w", err)
+	}
+	return nil
+}
+
+func (r *NotificationRepository) ClaimDueDeliveries(
+	ctx context.Context,
+	now time.Time,
+	lease time.Duration,
+	limit int,
+) ([]models.Delivery, error) {
+	query := `
+        UPDATE outbound_deliveries d
+        SET next_attempt_at = $2
+        FROM webhook_subscriptions s
+        WHERE s.id = d.subscription_id
+          AND d.id IN (
+              SELECT id FROM outbound_deliveries
+              WHERE status = 'PENDING' AND next_attempt_at <= $1
+              ORDER BY next_attempt_at
+              LIMIT $3
+              FOR UPDATE SKIP LOCKED
+          )
+        RETURNING d.id, d.subscription_id, s.url, s.secret, d.event, d.status, d.attempts,
+                  d.next_attempt_at, d.last_error, d.created_at, d.delivered_at
+    `
+	rows, err := conn(ctx, r.db).Query(ctx, query, now, now.Add(lease), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries, err := pgx.CollectRows(rows, scanDelivery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+func (r *NotificationRepository) MarkDelivered(ctx context.Context, id int64, at time.Time) error {
+	query := `
+        UPDATE outbound_deliveries
+        SET status = 'DELIVERED', attempts = attempts + 1, delivered_at = $2, last_error = ''
+        WHERE id = $1
+    `
+	if _, err := conn(ctx, r.db).Exec(ctx, query, id, at); err != nil {
+		return fmt.Errorf("failed to mark delivery as delivered: %w", err)
+	}
+	return nil
+}
+
+func (r *NotificationRepository) MarkFailed(
+	ctx context.Context,
+	id int64,
+	lastError string,
+	nextAttemptAt time.Time,
+	dead bool,
+) error {
+	status := models.DeliveryPending
+	if dead {
+		status = models.DeliveryDead
+	}
+
+	query := `
+        UPDATE outbound_deliveries
+        SET status = $2, attempts = attempts + 1, last_error = $3, next_attempt_at = $4
+        WHERE id = $1
+    `
+	if _, err := conn(ctx, r.db).Exec(ctx, query, id, status, lastError, nextAttemptAt); err != nil {
+		return fmt.Errorf("failed to mark delivery as failed: %w", err)
+	}
+	return nil
+}
+
+func (r *NotificationRepository) ListDeadLetters(ctx context.Context) ([]models.Delivery, error) {
+	query := `
+        SELECT d.id, d.subscription_id, s.url, s.secret, d.event, d.status, d.attempts,
+               d.next_attempt_at, d.last_error, d.created_at, d.delivered_at
+        FROM outbound_deliveries d
+        JOIN webhook_subscriptions s ON s.id = d.subscription_id
+        WHERE d.status = 'DEAD'
+        ORDER BY d.id
+    `
+	rows, err := conn(ctx, r.db).Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query dead letters: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries, err := pgx.CollectRows(rows, scanDelivery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect dead letters: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+func (r *NotificationRepository) ReplayDeadLetter(ctx context.Context, id int64, at time.Time) error {
+	query := `
+        UPDATE outbound_deliveries
+        SET status = 'PENDING', attempts = 0, next_attempt_at = $2
+        WHERE id = $1 AND status = 'DEAD'
+    `
+	res, err := conn(ctx, r.db).Exec(ctx, query, id, at)
+	if err != nil {
+		return fmt.Errorf("failed to replay dead letter: %w", err)
+	}
+	if res.RowsAffected() == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+func scanDelivery(row pgx.CollectableRow) (models.Delivery, error) {
+	var (
+		d    models.Delivery
+		body []byte
+	)
+	err := row.Scan(&d.ID, &d.SubscriptionID, &d.URL, &d.Secret, &body, &d.Status, &d.Attempts,
+		&d.NextAttemptAt, &d.LastError, &d.CreatedAt, &d.DeliveredAt)
+	if err != nil {
+		return d, err
+	}
+	if err := json.Unmarshal(body, &d.Event); err != nil {
+		return d, fmt.Errorf("failed to decode event: %w", err)
+	}
+	return d, nil
+}
@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+)
+
+type OutboxRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewOutboxRepository(db *pgxpool.Pool) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+func (r *OutboxRepository) Dispatch(
+	ctx context.Context,
+	limit int,
+	handle func(ctx context.Context, event models.Event) error,
+) (int, error) {
+	tx, err := begin(ctx, r.db)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	query := `
+        SELECT id, event
+        FROM outbox
+        WHERE dispatched_at IS NULL
+        ORDER BY id
+        LIMIT $1
+        FOR UPDATE SKIP LOCKED
+    `
+	rows, err := tx.Query(ctx, query, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query outbox: %w", err)
+	}
+
+	type outboxRow struct {
+		id    int64
+		event models.Event
+	}
+	pending, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (outboxRow, error) {
+		var (
+			item outboxRow
+			body []byte
+		)
+		if err := row.Scan(&item.id, &body); err != nil {
+			return item, err
+		}
+		return item, json.Unmarshal(body, &item.event)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to collect outbox: %w", err)
+	}
+
+	var (
+		dispatched []int64
+		handleErr  error
+	)
+	for _, item := range pending {
+		if handleErr = handle(ctx, item.event); handleErr != nil {
+			break
+		}
+		dispatched = append(dispatched, item.id)
+	}
+
+	if len(dispatched) > 0 {
+		if _, err := tx.Exec(ctx, `UPDATE outbox SET dispatched_at = NOW() WHERE id = ANY($1)`, dispatched); err != nil {
+			return 0, fmt.Errorf("failed to mark outbox dispatched: %w", err)
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return 0, fmt.Errorf("failed to commit tx: %w", err)
+		}
+	}
+
+	return len(dispatched), handleErr
+}
+
+func insertOutbox(ctx context.Context, tx pgx.Tx, events []models.Event) error {
+	query := `
+        INSERT INTO outbox (event_id, event_type, event, created_at)
+        VALUES ($1, $2, $3, $4)
+        ON CONFLICT (event_id) DO NOTHING
+    `
+	for _, event := range events {
+		body, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to encode event: %w", err)
+		}
+		if _, err := tx.Exec(ctx, query, event.ID, event.Type, body, event.OccurredAt); err != nil {
+			return fmt.Errorf("failed to insert outbox event: %w", err)
+		}
+	}
+	return nil
+}
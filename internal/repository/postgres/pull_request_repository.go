@@ -4,12 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/repository"
 )
 
 type PullRequestRepository struct {
@@ -20,8 +22,8 @@ func NewPullRequestRepository(db *pgxpool.Pool) *PullRequestRepository {
 	return &PullRequestRepository{db: db}
 }
 
-func (r *PullRequestRepository) CreatePR(ctx context.Context, pr *models.PullRequest) error {
-	tx, err := r.db.Begin(ctx)
+func (r *PullRequestRepository) CreatePR(ctx context.Context, pr *models.PullRequest, events ...models.Event) error {
+	tx, err := begin(ctx, r.db)
 	if err != nil {
 		return fmt.Errorf("failed to begin tx: %w", err)
 	}
@@ -30,10 +32,14 @@ func (r *PullRequestRepository) CreatePR(ctx context.Context, pr *models.PullReq
 	}()
 
 	queryPR := `
-        INSERT INTO pull_requests (id, name, author_id, status, created_at, merged_at)
-        VALUES ($1, $2, $3, $4, $5, $6)
+        INSERT INTO pull_requests (id, name, author_id, status, created_at, merged_at, files, repository_id)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
     `
-	_, err = tx.Exec(ctx, queryPR, pr.ID, pr.Name, pr.AuthorID, pr.Status, pr.CreatedAt, pr.MergedAt)
+	files := pr.Files
+	if files == nil {
+		files = []string{}
+	}
+	_, err = tx.Exec(ctx, queryPR, pr.ID, pr.Name, pr.AuthorID, pr.Status, pr.CreatedAt, pr.MergedAt, files, pr.RepositoryID)
 	if err != nil {
 		if IsUnique(err) {
 			return fmt.Errorf("pr exists: %w", models.ErrAlreadyExists)
@@ -43,16 +49,21 @@ func (r *PullRequestRepository) CreatePR(ctx context.Context, pr *models.PullReq
 
 	if len(pr.Reviewers) > 0 {
 		queryReviewers := `
-            INSERT INTO pr_reviewers (pr_id, reviewer_id)
-            VALUES ($1, $2)
+            INSERT INTO pr_reviewers (pr_id, reviewer_id, from_fallback)
+            VALUES ($1, $2, $3)
         `
 		for _, reviewerID := range pr.Reviewers {
-			if _, err := tx.Exec(ctx, queryReviewers, pr.ID, reviewerID); err != nil {
+			fromFallback := slices.Contains(pr.FallbackReviewers, reviewerID)
+			if _, err := tx.Exec(ctx, queryReviewers, pr.ID, reviewerID, fromFallback); err != nil {
 				return fmt.Errorf("failed to insert reviewer %s: %w", reviewerID, err)
 			}
 		}
 	}
 
+	if err := insertOutbox(ctx, tx, events); err != nil {
+		return err
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return fmt.Errorf("failed to commit tx: %w", err)
 	}
@@ -60,24 +71,73 @@ func (r *PullRequestRepository) CreatePR(ctx context.Context, pr *models.PullReq
 	return nil
 }
 
-func (r *PullRequestRepository) MergePR(ctx context.Context, id string) (*models.PullRequest, error) {
+// MergePR locks the PR row before calling guard; AddReview takes a share lock on it,
+// so reviews cannot change while guard decides.
+func (r *PullRequestRepository) MergePR(
+	ctx context.Context,
+	id string,
+	guard repository.MergeGuard,
+	events ...models.Event,
+) (*models.PullRequest, error) {
+	tx, err := begin(ctx, r.db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	// The row lock makes concurrent merges take turns, so only the first one sees
+	// a status other than MERGED and writes the events.
+	queryLock := `
+        SELECT id, name, author_id, status, created_at, merged_at, files, repository_id
+        FROM pull_requests
+        WHERE id = $1
+        FOR UPDATE
+    `
+	var current models.PullRequest
+	err = tx.QueryRow(ctx, queryLock, id).Scan(
+		&current.ID, &current.Name, &current.AuthorID, &current.Status, &current.CreatedAt,
+		&current.MergedAt, &current.Files, &current.RepositoryID,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to lock pr: %w", err)
+	}
+	if current.Status == models.PRStatusMerged {
+		events = nil
+	}
+
+	if guard != nil {
+		if err := loadReviewers(ctx, tx, &current); err != nil {
+			return nil, err
+		}
+		if err := guard(context.WithValue(ctx, txKey{}, tx), &current); err != nil {
+			return nil, err
+		}
+	}
+
 	query := `
         UPDATE pull_requests 
         SET status = 'MERGED', 
             merged_at = COALESCE(merged_at, $2)
         WHERE id = $1
-        RETURNING id, name, author_id, status, created_at, merged_at
+        RETURNING id, name, author_id, status, created_at, merged_at, files, repository_id
     `
 
 	var pr models.PullRequest
 
-	err := r.db.QueryRow(ctx, query, id, time.Now()).Scan(
+	err = tx.QueryRow(ctx, query, id, time.Now()).Scan(
 		&pr.ID,
 		&pr.Name,
 		&pr.AuthorID,
 		&pr.Status,
 		&pr.CreatedAt,
 		&pr.MergedAt,
+		&pr.Files,
+		&pr.RepositoryID,
 	)
 
 	if err != nil {
@@ -87,33 +147,60 @@ func (r *PullRequestRepository) MergePR(ctx context.Context, id string) (*models
 		return nil, fmt.Errorf("failed to merge pr: %w", err)
 	}
 
-	reviewersQuery := `
-		SELECT reviewer_id 
-		FROM pr_reviewers 
-		WHERE pr_id = $1
-	`
-	rows, err := r.db.Query(ctx, reviewersQuery, id)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get reviewers: %w", err)
+	if err := loadReviewers(ctx, tx, &pr); err != nil {
+		return nil, err
 	}
-	defer rows.Close()
 
-	reviewers, err := pgx.CollectRows(rows, pgx.RowTo[string])
-	if err != nil {
-		return nil, fmt.Errorf("failed to collect reviewers: %w", err)
+	if err := insertOutbox(ctx, tx, events); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit tx: %w", err)
 	}
-	pr.Reviewers = reviewers
 
 	return &pr, nil
 }
 
-func (r *PullRequestRepository) ReassignReviewer(ctx context.Context, prID, oldUserID, newUserID string) error {
+// ReassignReviewer locks the PR row, so merges and other reassignments of the PR
+// wait until the snapshot is compared and the change is written.
+func (r *PullRequestRepository) ReassignReviewer(
+	ctx context.Context,
+	snapshot *models.PullRequest,
+	oldUserID, newUserID string,
+	fromFallback bool,
+	events ...models.Event,
+) error {
+	prID := snapshot.ID
+	tx, err := begin(ctx, r.db)
+	if err != nil {
+		return fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	current := models.PullRequest{ID: prID}
+	err = tx.QueryRow(ctx, `SELECT status FROM pull_requests WHERE id = $1 FOR UPDATE`, prID).Scan(&current.Status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.ErrNotFound
+		}
+		return fmt.Errorf("failed to lock pr: %w", err)
+	}
+	if err := loadReviewers(ctx, tx, &current); err != nil {
+		return err
+	}
+	if !sameReviewState(snapshot, &current) {
+		return models.ErrPRChanged
+	}
+
 	query := `
         UPDATE pr_reviewers
-        SET reviewer_id = $3
+        SET reviewer_id = $3, from_fallback = $4, assigned_at = NOW(), overdue_at = NULL
         WHERE pr_id = $1 AND reviewer_id = $2
     `
-	res, err := r.db.Exec(ctx, query, prID, oldUserID, newUserID)
+	res, err := tx.Exec(ctx, query, prID, oldUserID, newUserID, fromFallback)
 	if err != nil {
 		if IsUnique(err) {
 			return fmt.Errorf("reviewer already assigned: %w", models.ErrAlreadyExists)
@@ -123,18 +210,108 @@ func (r *PullRequestRepository) ReassignReviewer(ctx context.Context, prID, oldU
 	if res.RowsAffected() == 0 {
 		return models.ErrNotAssigned
 	}
+
+	if err := insertOutbox(ctx, tx, events); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit tx: %w", err)
+	}
+	return nil
+}
+
+func (r *PullRequestRepository) AddReviewer(ctx context.Context, prID, userID string, events ...models.Event) error {
+	tx, err := begin(ctx, r.db)
+	if err != nil {
+		return fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	var status models.PRStatus
+	err = tx.QueryRow(ctx, `SELECT status FROM pull_requests WHERE id = $1 FOR UPDATE`, prID).Scan(&status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("pr %s: %w", prID, models.ErrNotFound)
+		}
+		return fmt.Errorf("failed to lock pr: %w", err)
+	}
+	if err := status.ReviewersEditable(); err != nil {
+		return err
+	}
+
+	query := `INSERT INTO pr_reviewers (pr_id, reviewer_id) VALUES ($1, $2)`
+	if _, err := tx.Exec(ctx, query, prID, userID); err != nil {
+		if IsUnique(err) {
+			return fmt.Errorf("reviewer %s: %w", userID, models.ErrAlreadyAssigned)
+		}
+		if IsForeignKey(err) {
+			return fmt.Errorf("pr %s or user %s: %w", prID, userID, models.ErrNotFound)
+		}
+		return fmt.Errorf("failed to add reviewer: %w", err)
+	}
+
+	if err := insertOutbox(ctx, tx, events); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit tx: %w", err)
+	}
+	return nil
+}
+
+func (r *PullRequestRepository) RemoveReviewer(ctx context.Context, prID, userID string, events ...models.Event) error {
+	tx, err := begin(ctx, r.db)
+	if err != nil {
+		return fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	var status models.PRStatus
+	err = tx.QueryRow(ctx, `SELECT status FROM pull_requests WHERE id = $1 FOR UPDATE`, prID).Scan(&status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("pr %s: %w", prID, models.ErrNotFound)
+		}
+		return fmt.Errorf("failed to lock pr: %w", err)
+	}
+	if err := status.ReviewersEditable(); err != nil {
+		return err
+	}
+
+	res, err := tx.Exec(ctx, `DELETE FROM pr_reviewers WHERE pr_id = $1 AND reviewer_id = $2`, prID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to remove reviewer: %w", err)
+	}
+	if res.RowsAffected() == 0 {
+		return models.ErrNotAssigned
+	}
+
+	if err := insertOutbox(ctx, tx, events); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit tx: %w", err)
+	}
 	return nil
 }
 
 func (r *PullRequestRepository) GetByReviewerID(ctx context.Context, userID string) ([]*models.PullRequestShort, error) {
 	query := `
-        SELECT pr.id, pr.name, pr.author_id, pr.status
+        SELECT pr.id, pr.name, pr.author_id, pr.status, prr.assigned_at, prr.from_fallback
         FROM pull_requests pr
         INNER JOIN pr_reviewers prr ON pr.id = prr.pr_id
         WHERE prr.reviewer_id = $1
+          AND pr.status <> 'CLOSED'
         ORDER BY pr.created_at DESC
     `
-	rows, err := r.db.Query(ctx, query, userID)
+	rows, err := conn(ctx, r.db).Query(ctx, query, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query prs: %w", err)
 	}
@@ -142,7 +319,7 @@ func (r *PullRequestRepository) GetByReviewerID(ctx context.Context, userID stri
 
 	result, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*models.PullRequestShort, error) {
 		var pr models.PullRequestShort
-		err := row.Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.Status)
+		err := row.Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.Status, &pr.AssignedAt, &pr.FromFallback)
 		return &pr, err
 	})
 
@@ -155,13 +332,13 @@ func (r *PullRequestRepository) GetByReviewerID(ctx context.Context, userID stri
 
 func (r *PullRequestRepository) GetByID(ctx context.Context, id string) (*models.PullRequest, error) {
 	query := `
-        SELECT id, name, author_id, status, created_at, merged_at 
+        SELECT id, name, author_id, status, created_at, merged_at, files, repository_id
         FROM pull_requests 
         WHERE id = $1
     `
 	var pr models.PullRequest
-	err := r.db.QueryRow(ctx, query, id).Scan(
-		&pr.ID, &pr.Name, &pr.AuthorID, &pr.Status, &pr.CreatedAt, &pr.MergedAt,
+	err := conn(ctx, r.db).QueryRow(ctx, query, id).Scan(
+		&pr.ID, &pr.Name, &pr.AuthorID, &pr.Status, &pr.CreatedAt, &pr.MergedAt, &pr.Files, &pr.RepositoryID,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -170,18 +347,276 @@ func (r *PullRequestRepository) GetByID(ctx context.Context, id string) (*models
 		return nil, fmt.Errorf("failed to get pr: %w", err)
 	}
 
-	queryRev := `SELECT reviewer_id FROM pr_reviewers WHERE pr_id = $1`
-	rows, err := r.db.Query(ctx, queryRev, id)
+	if err := loadReviewers(ctx, conn(ctx, r.db), &pr); err != nil {
+		return nil, err
+	}
+
+	return &pr, nil
+}
+
+// UpdateStatus locks the PR row, so a merge can't land between the status check
+// and the update.
+func (r *PullRequestRepository) UpdateStatus(
+	ctx context.Context,
+	id string,
+	status models.PRStatus,
+	from []models.PRStatus,
+	addReviewers, fallbackReviewers []string,
+	events ...models.Event,
+) (*models.PullRequest, error) {
+	tx, err := begin(ctx, r.db)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get reviewers: %w", err)
+		return nil, fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	var current models.PRStatus
+	err = tx.QueryRow(ctx, `SELECT status FROM pull_requests WHERE id = $1 FOR UPDATE`, id).Scan(&current)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to lock pr: %w", err)
+	}
+	if err := current.TransitionFrom(from); err != nil {
+		return nil, err
 	}
-	defer rows.Close()
 
-	reviewers, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	query := `
+        UPDATE pull_requests
+        SET status = $2
+        WHERE id = $1
+        RETURNING id, name, author_id, status, created_at, merged_at, files, repository_id
+    `
+	var pr models.PullRequest
+	err = tx.QueryRow(ctx, query, id, status).Scan(
+		&pr.ID, &pr.Name, &pr.AuthorID, &pr.Status, &pr.CreatedAt, &pr.MergedAt, &pr.Files, &pr.RepositoryID,
+	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to collect reviewers: %w", err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to update pr status: %w", err)
+	}
+
+	if status == models.PRStatusOpen {
+		// The SLA clock restarts whenever the PR goes back to review.
+		queryRestart := `UPDATE pr_reviewers SET assigned_at = NOW(), overdue_at = NULL WHERE pr_id = $1`
+		if _, err := tx.Exec(ctx, queryRestart, id); err != nil {
+			return nil, fmt.Errorf("failed to restart review sla: %w", err)
+		}
+	}
+
+	queryReviewers := `
+        INSERT INTO pr_reviewers (pr_id, reviewer_id, from_fallback)
+        VALUES ($1, $2, $3)
+        ON CONFLICT DO NOTHING
+    `
+	for _, reviewerID := range addReviewers {
+		fromFallback := slices.Contains(fallbackReviewers, reviewerID)
+		if _, err := tx.Exec(ctx, queryReviewers, id, reviewerID, fromFallback); err != nil {
+			return nil, fmt.Errorf("failed to insert reviewer %s: %w", reviewerID, err)
+		}
+	}
+
+	if err := loadReviewers(ctx, tx, &pr); err != nil {
+		return nil, err
+	}
+
+	if err := insertOutbox(ctx, tx, events); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit tx: %w", err)
 	}
-	pr.Reviewers = reviewers
 
 	return &pr, nil
 }
+
+// AddReview holds a share lock on the PR row, so a merge deciding on approvals
+// waits for the review.
+func (r *PullRequestRepository) AddReview(ctx context.Context, review *models.Review) error {
+	tx, err := begin(ctx, r.db)
+	if err != nil {
+		return fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	var locked string
+	err = tx.QueryRow(ctx, `SELECT id FROM pull_requests WHERE id = $1 FOR SHARE`, review.PRID).Scan(&locked)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("pr %s: %w", review.PRID, models.ErrNotFound)
+		}
+		return fmt.Errorf("failed to lock pr: %w", err)
+	}
+
+	query := `
+        INSERT INTO pr_reviews (pr_id, reviewer_id, verdict, submitted_at)
+        VALUES ($1, $2, $3, $4)
+    `
+	if _, err := tx.Exec(ctx, query, review.PRID, review.ReviewerID, review.Verdict, review.SubmittedAt); err != nil {
+		if IsForeignKey(err) {
+			return fmt.Errorf("pr or reviewer: %w", models.ErrNotFound)
+		}
+		return fmt.Errorf("failed to insert review: %w", err)
+	}
+
+	queryClear := `UPDATE pr_reviewers SET overdue_at = NULL WHERE pr_id = $1 AND reviewer_id = $2`
+	if _, err := tx.Exec(ctx, queryClear, review.PRID, review.ReviewerID); err != nil {
+		return fmt.Errorf("failed to clear overdue flag: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit tx: %w", err)
+	}
+	return nil
+}
+
+func (r *PullRequestRepository) GetReviews(ctx context.Context, prID string) ([]models.Review, error) {
+	query := `
+        SELECT pr_id, reviewer_id, verdict, submitted_at
+        FROM pr_reviews
+        WHERE pr_id = $1
+        ORDER BY submitted_at, id
+    `
+	rows, err := conn(ctx, r.db).Query(ctx, query, prID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reviews: %w", err)
+	}
+	defer rows.Close()
+
+	reviews, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.Review, error) {
+		var rev models.Review
+		err := row.Scan(&rev.PRID, &rev.ReviewerID, &rev.Verdict, &rev.SubmittedAt)
+		return rev, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect reviews: %w", err)
+	}
+
+	return reviews, nil
+}
+
+// MarkOverdue applies the SLA of the PR's review scope: the team of its registered
+// repository, or the author's team.
+func (r *PullRequestRepository) MarkOverdue(ctx context.Context, now time.Time) ([]models.OverdueReview, error) {
+	query := `
+        UPDATE pr_reviewers prr
+        SET overdue_at = $1
+        FROM pull_requests pr
+        JOIN users a ON a.id = pr.author_id
+        LEFT JOIN repositories rp ON rp.id = pr.repository_id
+        JOIN team_settings ts ON ts.team_name = COALESCE(rp.team_name, a.team_name)
+        WHERE prr.pr_id = pr.id
+          AND pr.status = 'OPEN'
+          AND prr.overdue_at IS NULL
+          AND ts.review_sla_minutes > 0
+          AND prr.assigned_at + make_interval(mins => ts.review_sla_minutes) <= $1
+          AND NOT EXISTS (
+              SELECT 1 FROM pr_reviews rv
+              WHERE rv.pr_id = prr.pr_id
+                AND rv.reviewer_id = prr.reviewer_id
+                AND rv.submitted_at >= prr.assigned_at
+          )
+        RETURNING pr.id, pr.name, prr.reviewer_id, pr.author_id, ts.team_name, prr.assigned_at,
+                  prr.assigned_at + make_interval(mins => ts.review_sla_minutes), prr.overdue_at
+    `
+	rows, err := conn(ctx, r.db).Query(ctx, query, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark overdue reviews: %w", err)
+	}
+	defer rows.Close()
+
+	overdue, err := pgx.CollectRows(rows, scanOverdueReview)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect overdue reviews: %w", err)
+	}
+
+	return overdue, nil
+}
+
+func (r *PullRequestRepository) GetOverdue(ctx context.Context, teamName string) ([]models.OverdueReview, error) {
+	query := `
+        SELECT pr.id, pr.name, prr.reviewer_id, pr.author_id, COALESCE(rp.team_name, a.team_name), prr.assigned_at,
+               prr.assigned_at + make_interval(mins => COALESCE(ts.review_sla_minutes, 0)), prr.overdue_at
+        FROM pr_reviewers prr
+        JOIN pull_requests pr ON pr.id = prr.pr_id
+        JOIN users a ON a.id = pr.author_id
+        LEFT JOIN repositories rp ON rp.id = pr.repository_id
+        LEFT JOIN team_settings ts ON ts.team_name = COALESCE(rp.team_name, a.team_name)
+        WHERE prr.overdue_at IS NOT NULL
+          AND pr.status = 'OPEN'
+          AND ($1 = '' OR COALESCE(rp.team_name, a.team_name) = $1)
+        ORDER BY prr.overdue_at, pr.id
+    `
+	rows, err := conn(ctx, r.db).Query(ctx, query, teamName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query overdue reviews: %w", err)
+	}
+	defer rows.Close()
+
+	overdue, err := pgx.CollectRows(rows, scanOverdueReview)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect overdue reviews: %w", err)
+	}
+
+	return overdue, nil
+}
+
+func scanOverdueReview(row pgx.CollectableRow) (models.OverdueReview, error) {
+	var o models.OverdueReview
+	err := row.Scan(&o.PRID, &o.PRName, &o.ReviewerID, &o.AuthorID, &o.TeamName, &o.AssignedAt, &o.DueAt, &o.OverdueAt)
+	return o, err
+}
+
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// sameReviewState reports whether a and b have the same status and reviewers.
+func sameReviewState(a, b *models.PullRequest) bool {
+	if a.Status != b.Status || len(a.Reviewers) != len(b.Reviewers) {
+		return false
+	}
+	for _, reviewerID := range a.Reviewers {
+		if !slices.Contains(b.Reviewers, reviewerID) {
+			return false
+		}
+	}
+	return true
+}
+
+// loadReviewers fills pr.Reviewers and pr.FallbackReviewers.
+func loadReviewers(ctx context.Context, q querier, pr *models.PullRequest) error {
+	rows, err := q.Query(ctx, `SELECT reviewer_id, from_fallback FROM pr_reviewers WHERE pr_id = $1`, pr.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get reviewers: %w", err)
+	}
+	defer rows.Close()
+
+	pr.Reviewers = []string{}
+	pr.FallbackReviewers = nil
+	for rows.Next() {
+		var (
+			reviewerID   string
+			fromFallback bool
+		)
+		if err := rows.Scan(&reviewerID, &fromFallback); err != nil {
+			return fmt.Errorf("failed to scan reviewer: %w", err)
+		}
+		pr.Reviewers = append(pr.Reviewers, reviewerID)
+		if fromFallback {
+			pr.FallbackReviewers = append(pr.FallbackReviewers, reviewerID)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to collect reviewers: %w", err)
+	}
+	return nil
+}
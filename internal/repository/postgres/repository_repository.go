@@ -0,0 +1,166 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+)
+
+const selectRepositories = `
+        SELECT r.id, r.team_name, r.reviewers_count, r.min_reviewers, r.required_approvals,
+               COALESCE(array_agg(rr.user_id ORDER BY rr.user_id) FILTER (WHERE rr.user_id IS NOT NULL), '{}')
+        FROM repositories r
+        LEFT JOIN repository_reviewers rr ON rr.repository_id = r.id
+    `
+
+type RepositoryRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepositoryRepository(db *pgxpool.Pool) *RepositoryRepository {
+	return &RepositoryRepository{db: db}
+}
+
+func (r *RepositoryRepository) CreateRepository(ctx context.Context, repo *models.Repository) error {
+	tx, err := begin(ctx, r.db)
+	if err != nil {
+		return fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	count, minReviewers, approvals := settingsColumns(repo.Settings)
+	query := `
+        INSERT INTO repositories (id, team_name, reviewers_count, min_reviewers, required_approvals)
+        VALUES ($1, $2, $3, $4, $5)
+    `
+	if _, err := tx.Exec(ctx, query, repo.ID, repo.TeamName, count, minReviewers, approvals); err != nil {
+		if IsUnique(err) {
+			return fmt.Errorf("repository %s: %w", repo.ID, models.ErrAlreadyExists)
+		}
+		if IsForeignKey(err) {
+			return fmt.Errorf("team %s: %w", repo.TeamName, models.ErrNotFound)
+		}
+		return fmt.Errorf("failed to insert repository: %w", err)
+	}
+
+	if err := insertRepositoryReviewers(ctx, tx, repo); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit tx: %w", err)
+	}
+	return nil
+}
+
+func (r *RepositoryRepository) UpdateRepository(ctx context.Context, repo *models.Repository) error {
+	tx, err := begin(ctx, r.db)
+	if err != nil {
+		return fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	count, minReviewers, approvals := settingsColumns(repo.Settings)
+	query := `
+        UPDATE repositories
+        SET team_name = $2, reviewers_count = $3, min_reviewers = $4, required_approvals = $5
+        WHERE id = $1
+    `
+	tag, err := tx.Exec(ctx, query, repo.ID, repo.TeamName, count, minReviewers, approvals)
+	if err != nil {
+		if IsForeignKey(err) {
+			return fmt.Errorf("team %s: %w", repo.TeamName, models.ErrNotFound)
+		}
+		return fmt.Errorf("failed to update repository: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrNotFound
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM repository_reviewers WHERE repository_id = $1`, repo.ID); err != nil {
+		return fmt.Errorf("failed to clear repository reviewers: %w", err)
+	}
+	if err := insertRepositoryReviewers(ctx, tx, repo); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit tx: %w", err)
+	}
+	return nil
+}
+
+func (r *RepositoryRepository) GetRepository(ctx context.Context, id string) (*models.Repository, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, selectRepositories+` WHERE r.id = $1 GROUP BY r.id`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query repository: %w", err)
+	}
+	repo, err := pgx.CollectExactlyOneRow(rows, scanRepository)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get repository %s: %w", id, err)
+	}
+	return &repo, nil
+}
+
+func (r *RepositoryRepository) ListRepositories(ctx context.Context, teamName string) ([]models.Repository, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, selectRepositories+` WHERE r.team_name = $1 GROUP BY r.id ORDER BY r.id`, teamName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query repositories: %w", err)
+	}
+	repos, err := pgx.CollectRows(rows, scanRepository)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect repositories: %w", err)
+	}
+	return repos, nil
+}
+
+func insertRepositoryReviewers(ctx context.Context, tx pgx.Tx, repo *models.Repository) error {
+	query := `INSERT INTO repository_reviewers (repository_id, user_id) VALUES ($1, $2)`
+	for _, userID := range repo.Reviewers {
+		if _, err := tx.Exec(ctx, query, repo.ID, userID); err != nil {
+			if IsForeignKey(err) {
+				return fmt.Errorf("user %s: %w", userID, models.ErrNotFound)
+			}
+			return fmt.Errorf("failed to insert repository reviewer %s: %w", userID, err)
+		}
+	}
+	return nil
+}
+
+// settingsColumns stores nil settings as NULLs, meaning the team settings apply.
+func settingsColumns(settings *models.RepositorySettings) (count, minReviewers, approvals *int) {
+	if settings == nil {
+		return nil, nil, nil
+	}
+	return &settings.ReviewersCount, &settings.MinReviewers, &settings.RequiredApprovals
+}
+
+func scanRepository(row pgx.CollectableRow) (models.Repository, error) {
+	var (
+		repo                           models.Repository
+		count, minReviewers, approvals *int
+	)
+	if err := row.Scan(&repo.ID, &repo.TeamName, &count, &minReviewers, &approvals, &repo.Reviewers); err != nil {
+		return repo, err
+	}
+	if count != nil {
+		repo.Settings = &models.RepositorySettings{
+			ReviewersCount:    *count,
+			MinReviewers:      *minReviewers,
+			RequiredApprovals: *approvals,
+		}
+	}
+	return repo, nil
+}
@@ -29,7 +29,7 @@ func (r *StatsRepository) GetTopReviewers(ctx context.Context) ([]*models.Review
 		LIMIT 10
 	`
 
-	rows, err := r.db.Query(ctx, query)
+	rows, err := conn(ctx, r.db).Query(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query stats: %w", err)
 	}
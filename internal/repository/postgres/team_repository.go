@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/jackc/pgx/v5"
@@ -21,7 +22,7 @@ func NewTeamRepository(db *pgxpool.Pool) *TeamRepository {
 }
 
 func (r *TeamRepository) CreateTeam(ctx context.Context, team *models.Team) error {
-	tx, err := r.db.Begin(ctx)
+	tx, err := begin(ctx, r.db)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
@@ -62,7 +63,7 @@ func (r *TeamRepository) CreateTeam(ctx context.Context, team *models.Team) erro
 func (r *TeamRepository) GetTeam(ctx context.Context, name string) (*models.Team, error) {
 	var found bool
 	query := `SELECT EXISTS(SELECT 1 FROM teams WHERE name = $1)`
-	if err := r.db.QueryRow(ctx, query, name).Scan(&found); err != nil {
+	if err := conn(ctx, r.db).QueryRow(ctx, query, name).Scan(&found); err != nil {
 		return nil, fmt.Errorf("failed to check team existence: %w", err)
 	}
 
@@ -75,7 +76,7 @@ func (r *TeamRepository) GetTeam(ctx context.Context, name string) (*models.Team
         FROM users 
         WHERE team_name = $1
     `
-	rows, err := r.db.Query(ctx, query, name)
+	rows, err := conn(ctx, r.db).Query(ctx, query, name)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query members for team %s: %w", name, err)
 	}
@@ -95,3 +96,67 @@ func (r *TeamRepository) GetTeam(ctx context.Context, name string) (*models.Team
 		Members: members,
 	}, nil
 }
+
+func (r *TeamRepository) GetSettings(ctx context.Context, teamName string) (*models.TeamSettings, error) {
+	query := `
+        SELECT ts.reviewers_count, ts.min_reviewers, ts.required_approvals, ts.chat_webhook_url,
+               ts.review_sla_minutes, ts.sla_auto_reassign, ts.fallback_teams
+        FROM teams t
+        LEFT JOIN team_settings ts ON ts.team_name = t.name
+        WHERE t.name = $1
+    `
+	var (
+		reviewersCount, minReviewers, requiredApprovals, reviewSLAMinutes *int
+		chatWebhookURL                                                    *string
+		slaAutoReassign                                                   *bool
+		fallbackTeams                                                     []string
+	)
+	err := conn(ctx, r.db).QueryRow(ctx, query, teamName).Scan(&reviewersCount, &minReviewers, &requiredApprovals,
+		&chatWebhookURL, &reviewSLAMinutes, &slaAutoReassign, &fallbackTeams)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get settings for team %s: %w", teamName, err)
+	}
+
+	settings := models.DefaultTeamSettings()
+	if reviewersCount != nil {
+		settings.ReviewersCount = *reviewersCount
+		settings.MinReviewers = *minReviewers
+		settings.RequiredApprovals = *requiredApprovals
+		settings.ChatWebhookURL = *chatWebhookURL
+		settings.ReviewSLAMinutes = *reviewSLAMinutes
+		settings.SLAAutoReassign = *slaAutoReassign
+		settings.FallbackTeams = fallbackTeams
+	}
+
+	return settings, nil
+}
+
+func (r *TeamRepository) UpsertSettings(ctx context.Context, teamName string, settings *models.TeamSettings) error {
+	query := `
+        INSERT INTO team_settings (team_name, reviewers_count, min_reviewers, required_approvals,
+                                   chat_webhook_url, review_sla_minutes, sla_auto_reassign, fallback_teams)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        ON CONFLICT (team_name) DO UPDATE
+        SET reviewers_count = EXCLUDED.reviewers_count,
+            min_reviewers = EXCLUDED.min_reviewers,
+            required_approvals = EXCLUDED.required_approvals,
+            chat_webhook_url = EXCLUDED.chat_webhook_url,
+            review_sla_minutes = EXCLUDED.review_sla_minutes,
+            sla_auto_reassign = EXCLUDED.sla_auto_reassign,
+            fallback_teams = EXCLUDED.fallback_teams
+    `
+	_, err := conn(ctx, r.db).Exec(ctx, query, teamName, settings.ReviewersCount, settings.MinReviewers,
+		settings.RequiredApprovals, settings.ChatWebhookURL, settings.ReviewSLAMinutes, settings.SLAAutoReassign,
+		settings.FallbackTeams)
+	if err != nil {
+		if IsForeignKey(err) {
+			return fmt.Errorf("team %s: %w", teamName, models.ErrNotFound)
+		}
+		return fmt.Errorf("failed to upsert settings for team %s: %w", teamName, err)
+	}
+
+	return nil
+}
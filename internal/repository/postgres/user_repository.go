@@ -4,11 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/repository"
 )
 
 type UserRepository struct {
@@ -24,7 +26,7 @@ func NewUserRepository(db *pgxpool.Pool) *UserRepository {
 func (r *UserRepository) GetUser(ctx context.Context, id string) (*models.User, error) {
 	query := `SELECT id, name, team_name, is_active FROM users WHERE id = $1`
 	var u models.User
-	if err := r.db.QueryRow(ctx, query, id).Scan(&u.ID, &u.Name, &u.TeamName, &u.IsActive); err != nil {
+	if err := conn(ctx, r.db).QueryRow(ctx, query, id).Scan(&u.ID, &u.Name, &u.TeamName, &u.IsActive); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, fmt.Errorf("user not found: %w", models.ErrNotFound)
 		}
@@ -41,7 +43,7 @@ func (r *UserRepository) SetUserIsActive(ctx context.Context, id string, isActiv
         RETURNING id, name, team_name, is_active
     `
 	var u models.User
-	if err := r.db.QueryRow(ctx, query, id, isActive).Scan(&u.ID, &u.Name, &u.TeamName, &u.IsActive); err != nil {
+	if err := conn(ctx, r.db).QueryRow(ctx, query, id, isActive).Scan(&u.ID, &u.Name, &u.TeamName, &u.IsActive); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, models.ErrNotFound
 		}
@@ -51,13 +53,21 @@ func (r *UserRepository) SetUserIsActive(ctx context.Context, id string, isActiv
 	return &u, nil
 }
 
-func (r *UserRepository) GetActiveUsersByTeam(ctx context.Context, teamName string) ([]models.User, error) {
+func (r *UserRepository) GetActiveUsersByTeam(
+	ctx context.Context,
+	teamName string,
+	from, to time.Time,
+) ([]models.User, error) {
 	query := `
         SELECT id, name, team_name, is_active 
-        FROM users 
+        FROM users u
         WHERE team_name = $1 AND is_active = true
+          AND NOT EXISTS (
+              SELECT 1 FROM absences a
+              WHERE a.user_id = u.id AND a.starts_at < $3 AND a.ends_at > $2
+          )
     `
-	rows, err := r.db.Query(ctx, query, teamName)
+	rows, err := conn(ctx, r.db).Query(ctx, query, teamName, from, to)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query active users for team %s: %w", teamName, err)
 	}
@@ -76,8 +86,305 @@ func (r *UserRepository) GetActiveUsersByTeam(ctx context.Context, teamName stri
 	return users, nil
 }
 
-func (r *UserRepository) DeactivateUsers(ctx context.Context, userIDs []string) error {
-	tx, err := r.db.Begin(ctx)
+func (r *UserRepository) GetOpenReviewCounts(ctx context.Context, teamName string) (map[string]int, error) {
+	query := `
+		SELECT u.id, COUNT(pr.id)
+		FROM users u
+		LEFT JOIN pr_reviewers prr ON prr.reviewer_id = u.id
+		LEFT JOIN pull_requests pr ON pr.id = prr.pr_id AND pr.status = 'OPEN'
+		WHERE u.team_name = $1
+		GROUP BY u.id
+	`
+	rows, err := conn(ctx, r.db).Query(ctx, query, teamName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query review counts for team %s: %w", teamName, err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var (
+			id    string
+			count int
+		)
+		if err := rows.Scan(&id, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan review count: %w", err)
+		}
+		counts[id] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to collect review counts: %w", err)
+	}
+
+	return counts, nil
+}
+
+func (r *UserRepository) LinkIdentity(ctx context.Context, identity *models.UserIdentity) error {
+	query := `
+        INSERT INTO user_identities (provider, login, user_id)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (provider, login) DO UPDATE
+        SET user_id = EXCLUDED.user_id
+    `
+	if _, err := conn(ctx, r.db).Exec(ctx, query, identity.Provider, identity.Login, identity.UserID); err != nil {
+		if IsForeignKey(err) {
+			return fmt.Errorf("user %s: %w", identity.UserID, models.ErrNotFound)
+		}
+		return fmt.Errorf("failed to link identity: %w", err)
+	}
+	return nil
+}
+
+func (r *UserRepository) GetUserByIdentity(ctx context.Context, provider, login string) (*models.User, error) {
+	query := `
+        SELECT u.id, u.name, u.team_name, u.is_active
+        FROM users u
+        JOIN user_identities ui ON ui.user_id = u.id
+        WHERE ui.provider = $1 AND ui.login = $2
+    `
+	var u models.User
+	if err := conn(ctx, r.db).QueryRow(ctx, query, provider, login).Scan(&u.ID, &u.Name, &u.TeamName, &u.IsActive); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("identity %s/%s: %w", provider, login, models.ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get user by identity: %w", err)
+	}
+	return &u, nil
+}
+
+func (r *UserRepository) SetChatHandle(ctx context.Context, userID, handle string) error {
+	res, err := conn(ctx, r.db).Exec(ctx, `UPDATE users SET chat_handle = $2 WHERE id = $1`, userID, handle)
+	if err != nil {
+		return fmt.Errorf("failed to set chat handle: %w", err)
+	}
+	if res.RowsAffected() == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+func (r *UserRepository) GetChatHandles(ctx context.Context, userIDs []string) (map[string]string, error) {
+	query := `
+        SELECT id, chat_handle
+        FROM users
+        WHERE id = ANY($1) AND chat_handle <> ''
+    `
+	rows, err := conn(ctx, r.db).Query(ctx, query, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query chat handles: %w", err)
+	}
+	defer rows.Close()
+
+	handles := make(map[string]string)
+	for rows.Next() {
+		var id, handle string
+		if err := rows.Scan(&id, &handle); err != nil {
+			return nil, fmt.Errorf("failed to scan chat handle: %w", err)
+		}
+		handles[id] = handle
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read chat handles: %w", err)
+	}
+
+	return handles, nil
+}
+
+func (r *UserRepository) SetQuietHours(ctx context.Context, userID string, quiet models.QuietHours) error {
+	query := `
+        UPDATE users
+        SET quiet_hours_from = $2, quiet_hours_to = $3, timezone = $4
+        WHERE id = $1
+    `
+	res, err := conn(ctx, r.db).Exec(ctx, query, userID, quiet.From, quiet.To, quiet.Timezone)
+	if err != nil {
+		return fmt.Errorf("failed to set quiet hours: %w", err)
+	}
+	if res.RowsAffected() == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+func (r *UserRepository) GetDigestRecipients(ctx context.Context) ([]models.DigestRecipient, error) {
+	query := `
+        SELECT id, team_name, quiet_hours_from, quiet_hours_to, timezone, last_digest_at
+        FROM users
+        WHERE is_active = true
+        ORDER BY id
+    `
+	rows, err := conn(ctx, r.db).Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query digest recipients: %w", err)
+	}
+	defer rows.Close()
+
+	recipients, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.DigestRecipient, error) {
+		var d models.DigestRecipient
+		err := row.Scan(&d.UserID, &d.TeamName, &d.QuietHours.From, &d.QuietHours.To,
+			&d.QuietHours.Timezone, &d.LastDigestAt)
+		return d, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect digest recipients: %w", err)
+	}
+
+	return recipients, nil
+}
+
+func (r *UserRepository) RecordDigest(ctx context.Context, userID string, at time.Time, event models.Event) error {
+	tx, err := begin(ctx, r.db)
+	if err != nil {
+		return fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	if _, err := tx.Exec(ctx, `UPDATE users SET last_digest_at = $2 WHERE id = $1`, userID, at); err != nil {
+		return fmt.Errorf("failed to record digest: %w", err)
+	}
+
+	if err := insertOutbox(ctx, tx, []models.Event{event}); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit tx: %w", err)
+	}
+	return nil
+}
+
+func (r *UserRepository) CreateAbsence(ctx context.Context, absence *models.Absence) error {
+	query := `
+        INSERT INTO absences (user_id, starts_at, ends_at, reason)
+        VALUES ($1, $2, $3, $4)
+        RETURNING id, created_at
+    `
+	err := conn(ctx, r.db).QueryRow(ctx, query, absence.UserID, absence.StartsAt, absence.EndsAt, absence.Reason).
+		Scan(&absence.ID, &absence.CreatedAt)
+	if err != nil {
+		if IsForeignKey(err) {
+			return models.ErrNotFound
+		}
+		return fmt.Errorf("failed to create absence: %w", err)
+	}
+	return nil
+}
+
+func (r *UserRepository) ListAbsences(ctx context.Context, userID string) ([]models.Absence, error) {
+	query := `
+        SELECT id, user_id, starts_at, ends_at, reason, COALESCE(external_uid, ''), created_at
+        FROM absences
+        WHERE user_id = $1
+        ORDER BY starts_at
+    `
+	rows, err := conn(ctx, r.db).Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query absences: %w", err)
+	}
+	defer rows.Close()
+
+	absences, err := pgx.CollectRows(rows, scanAbsence)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect absences: %w", err)
+	}
+
+	return absences, nil
+}
+
+func (r *UserRepository) DeleteAbsence(ctx context.Context, id int64) error {
+	res, err := conn(ctx, r.db).Exec(ctx, `DELETE FROM absences WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete absence: %w", err)
+	}
+	if res.RowsAffected() == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+// UpsertAbsence keeps handled_at only if the absence still starts at the same time
+// for the same user, so a moved absence hands reviews over again.
+func (r *UserRepository) UpsertAbsence(ctx context.Context, absence *models.Absence) (bool, error) {
+	query := `
+        INSERT INTO absences (user_id, starts_at, ends_at, reason, external_uid)
+        VALUES ($1, $2, $3, $4, $5)
+        ON CONFLICT (external_uid) DO UPDATE
+        SET user_id = EXCLUDED.user_id,
+            starts_at = EXCLUDED.starts_at,
+            ends_at = EXCLUDED.ends_at,
+            reason = EXCLUDED.reason,
+            handled_at = CASE
+                WHEN absences.user_id = EXCLUDED.user_id AND absences.starts_at = EXCLUDED.starts_at
+                THEN absences.handled_at
+            END
+        RETURNING id, created_at, xmax = 0
+    `
+	var created bool
+	err := conn(ctx, r.db).QueryRow(ctx, query, absence.UserID, absence.StartsAt, absence.EndsAt, absence.Reason, absence.UID).
+		Scan(&absence.ID, &absence.CreatedAt, &created)
+	if err != nil {
+		if IsForeignKey(err) {
+			return false, models.ErrNotFound
+		}
+		return false, fmt.Errorf("failed to upsert absence: %w", err)
+	}
+	return created, nil
+}
+
+func (r *UserRepository) DeleteAbsenceByUID(ctx context.Context, uid string) error {
+	res, err := conn(ctx, r.db).Exec(ctx, `DELETE FROM absences WHERE external_uid = $1`, uid)
+	if err != nil {
+		return fmt.Errorf("failed to delete absence: %w", err)
+	}
+	if res.RowsAffected() == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+func (r *UserRepository) GetStartedAbsences(ctx context.Context, now time.Time) ([]models.Absence, error) {
+	query := `
+        SELECT id, user_id, starts_at, ends_at, reason, COALESCE(external_uid, ''), created_at
+        FROM absences
+        WHERE handled_at IS NULL AND starts_at <= $1 AND ends_at > $1
+        ORDER BY starts_at, id
+    `
+	rows, err := conn(ctx, r.db).Query(ctx, query, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query started absences: %w", err)
+	}
+	defer rows.Close()
+
+	absences, err := pgx.CollectRows(rows, scanAbsence)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect started absences: %w", err)
+	}
+
+	return absences, nil
+}
+
+func (r *UserRepository) MarkAbsenceHandled(ctx context.Context, id int64, at time.Time) error {
+	if _, err := conn(ctx, r.db).Exec(ctx, `UPDATE absences SET handled_at = $2 WHERE id = $1`, id, at); err != nil {
+		return fmt.Errorf("failed to mark absence handled: %w", err)
+	}
+	return nil
+}
+
+func scanAbsence(row pgx.CollectableRow) (models.Absence, error) {
+	var a models.Absence
+	err := row.Scan(&a.ID, &a.UserID, &a.StartsAt, &a.EndsAt, &a.Reason, &a.UID, &a.CreatedAt)
+	return a, err
+}
+
+func (r *UserRepository) DeactivateUsers(
+	ctx context.Context,
+	userIDs []string,
+	pick repository.ReviewerPicker,
+	events ...models.Event,
+) error {
+	tx, err := begin(ctx, r.db)
 	if err != nil {
 		return err
 	}
@@ -92,7 +399,7 @@ func (r *UserRepository) DeactivateUsers(ctx context.Context, userIDs []string)
 	}
 
 	query := `
-		SELECT prr.pr_id, prr.reviewer_id, pr.author_id, u.team_name
+		SELECT prr.pr_id, prr.reviewer_id, pr.author_id, u.team_name, pr.repository_id
 		FROM pr_reviewers prr
 		JOIN pull_requests pr ON prr.pr_id = pr.id
 		JOIN users u ON pr.author_id = u.id
@@ -107,7 +414,7 @@ func (r *UserRepository) DeactivateUsers(ctx context.Context, userIDs []string)
 
 	reviews, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.ReviewToUpdate, error) {
 		var item models.ReviewToUpdate
-		err := row.Scan(&item.PRID, &item.OldReviewerID, &item.AuthorID, &item.TeamName)
+		err := row.Scan(&item.PRID, &item.OldReviewerID, &item.AuthorID, &item.TeamName, &item.RepositoryID)
 		return item, err
 	})
 	if err != nil {
@@ -116,38 +423,46 @@ func (r *UserRepository) DeactivateUsers(ctx context.Context, userIDs []string)
 
 	queryDeleteReviewer := "DELETE FROM pr_reviewers WHERE pr_id=$1 AND reviewer_id=$2"
 
-	queryUpdateReviewer := "UPDATE pr_reviewers SET reviewer_id=$1 WHERE pr_id=$2 AND reviewer_id=$3"
+	queryUpdateReviewer := `
+		UPDATE pr_reviewers SET reviewer_id=$1, from_fallback=$4, assigned_at=NOW(), overdue_at=NULL
+		WHERE pr_id=$2 AND reviewer_id=$3
+	`
 
+	// pick reads users and review loads through the transaction, so it sees the
+	// deactivated users and counts replacements made so far towards their load.
+	pickCtx := context.WithValue(ctx, txKey{}, tx)
 	for _, rev := range reviews {
-		var newReviewerID string
-
-		queryCandidate := `
-			SELECT id FROM users
-			WHERE team_name = $1
-			  AND is_active = true
-			  AND id != $2
-			  AND id NOT IN (
-			      SELECT reviewer_id FROM pr_reviewers WHERE pr_id = $3
-			  )
-			LIMIT 1
-		`
-
-		err := tx.QueryRow(ctx, queryCandidate, rev.TeamName, rev.AuthorID, rev.PRID).Scan(&newReviewerID)
+		rows, err := tx.Query(ctx, `SELECT reviewer_id FROM pr_reviewers WHERE pr_id = $1`, rev.PRID)
+		if err != nil {
+			return fmt.Errorf("failed to query reviewers: %w", err)
+		}
+		rev.Reviewers, err = pgx.CollectRows(rows, pgx.RowTo[string])
+		if err != nil {
+			return fmt.Errorf("failed to collect reviewers: %w", err)
+		}
 
+		newReviewerID, fromFallback, err := pick(pickCtx, rev)
 		if err != nil {
-			if errors.Is(err, pgx.ErrNoRows) {
-				if _, err := tx.Exec(ctx, queryDeleteReviewer, rev.PRID, rev.OldReviewerID); err != nil {
-					return fmt.Errorf("failed to delete reviewer: %w", err)
-				}
-			} else {
-				return fmt.Errorf("failed to find candidate: %w", err)
+			return fmt.Errorf("failed to pick candidate: %w", err)
+		}
+
+		if newReviewerID == "" {
+			if _, err := tx.Exec(ctx, queryDeleteReviewer, rev.PRID, rev.OldReviewerID); err != nil {
+				return fmt.Errorf("failed to delete reviewer: %w", err)
 			}
 		} else {
-			if _, err := tx.Exec(ctx, queryUpdateReviewer, newReviewerID, rev.PRID, rev.OldReviewerID); err != nil {
+			_, err := tx.Exec(ctx, queryUpdateReviewer, newReviewerID, rev.PRID, rev.OldReviewerID, fromFallback)
+			if err != nil {
 				return fmt.Errorf("failed to update reviewer: %w", err)
 			}
+			events = append(events, models.NewEvent(models.EventReviewerReassigned,
+				rev.ReassignedPayload(newReviewerID, fromFallback)))
 		}
 	}
 
+	if err := insertOutbox(ctx, tx, events); err != nil {
+		return err
+	}
+
 	return tx.Commit(ctx)
 }
@@ -0,0 +1,37 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type WebhookDeliveryRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewWebhookDeliveryRepository(db *pgxpool.Pool) *WebhookDeliveryRepository {
+	return &WebhookDeliveryRepository{db: db}
+}
+
+func (r *WebhookDeliveryRepository) MarkReceived(ctx context.Context, provider, deliveryID string) (bool, error) {
+	query := `
+        INSERT INTO webhook_deliveries (provider, delivery_id)
+        VALUES ($1, $2)
+        ON CONFLICT DO NOTHING
+    `
+	res, err := conn(ctx, r.db).Exec(ctx, query, provider, deliveryID)
+	if err != nil {
+		return false, fmt.Errorf("failed to record delivery: %w", err)
+	}
+	return res.RowsAffected() == 1, nil
+}
+
+func (r *WebhookDeliveryRepository) Forget(ctx context.Context, provider, deliveryID string) error {
+	query := `DELETE FROM webhook_deliveries WHERE provider = $1 AND delivery_id = $2`
+	if _, err := conn(ctx, r.db).Exec(ctx, query, provider, deliveryID); err != nil {
+		return fmt.Errorf("failed to delete delivery: %w", err)
+	}
+	return nil
+}
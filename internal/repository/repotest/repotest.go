@@ -0,0 +1,414 @@
+// Package repotest holds the contract every storage backend of the repository
+// interfaces has to satisfy.
+package repotest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/repository"
+)
+
+// Repositories are the repositories of one backend sharing the same storage.
+type Repositories struct {
+	Teams repository.TeamRepositoryInterface
+	Users repository.UserRepositoryInterface
+	PRs   repository.PullRequestRepositoryInterface
+}
+
+// Run runs the contract against the backend. open is called once per subtest
+// and must return repositories over empty storage.
+func Run(t *testing.T, open func(t *testing.T) Repositories) {
+	ctx := context.Background()
+
+	t.Run("Missing resources are reported as not found", func(t *testing.T) {
+		repos := open(t)
+		seed(t, repos)
+
+		if _, err := repos.Teams.GetTeam(ctx, "ghost"); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("GetTeam: expected ErrNotFound, got %v", err)
+		}
+		if _, err := repos.Users.GetUser(ctx, "ghost"); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("GetUser: expected ErrNotFound, got %v", err)
+		}
+		if _, err := repos.Users.SetUserIsActive(ctx, "ghost", false); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("SetUserIsActive: expected ErrNotFound, got %v", err)
+		}
+		if _, err := repos.PRs.GetByID(ctx, "ghost"); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("GetByID: expected ErrNotFound, got %v", err)
+		}
+		if _, err := repos.PRs.MergePR(ctx, "ghost", nil); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("MergePR: expected ErrNotFound, got %v", err)
+		}
+		if err := repos.PRs.AddReviewer(ctx, "pr1", "ghost"); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("AddReviewer of unknown user: expected ErrNotFound, got %v", err)
+		}
+		if err := repos.PRs.AddReviewer(ctx, "ghost", "u3"); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("AddReviewer to unknown PR: expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("Duplicates are reported as already existing", func(t *testing.T) {
+		repos := open(t)
+		seed(t, repos)
+
+		err := repos.Teams.CreateTeam(ctx, &models.Team{Name: "backend"})
+		if !errors.Is(err, models.ErrAlreadyExists) {
+			t.Errorf("CreateTeam: expected ErrAlreadyExists, got %v", err)
+		}
+		err = repos.PRs.CreatePR(ctx, &models.PullRequest{
+			ID: "pr1", Name: "Again", AuthorID: "u1", Status: models.PRStatusOpen, CreatedAt: time.Now(),
+		})
+		if !errors.Is(err, models.ErrAlreadyExists) {
+			t.Errorf("CreatePR: expected ErrAlreadyExists, got %v", err)
+		}
+	})
+
+	t.Run("Merging twice keeps the first merge time", func(t *testing.T) {
+		repos := open(t)
+		seed(t, repos)
+
+		first, err := repos.PRs.MergePR(ctx, "pr1", nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if first.Status != models.PRStatusMerged || first.MergedAt == nil {
+			t.Fatalf("Expected a merged PR with a merge time, got %+v", first)
+		}
+
+		time.Sleep(5 * time.Millisecond)
+		second, err := repos.PRs.MergePR(ctx, "pr1", nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if second.MergedAt == nil || !second.MergedAt.Equal(*first.MergedAt) {
+			t.Errorf("Expected merge time %v to be kept, got %v", first.MergedAt, second.MergedAt)
+		}
+		if !slices.Equal(second.Reviewers, []string{"u2"}) {
+			t.Errorf("Expected reviewers [u2], got %v", second.Reviewers)
+		}
+
+		stored, _ := repos.PRs.GetByID(ctx, "pr1")
+		if stored.MergedAt == nil || !stored.MergedAt.Equal(*first.MergedAt) {
+			t.Errorf("Expected stored merge time %v, got %v", first.MergedAt, stored.MergedAt)
+		}
+	})
+
+	t.Run("A rejecting merge guard leaves the PR open", func(t *testing.T) {
+		repos := open(t)
+		seed(t, repos)
+
+		review := &models.Review{PRID: "pr1", ReviewerID: "u2", Verdict: models.VerdictChangesRequested, SubmittedAt: time.Now()}
+		if err := repos.PRs.AddReview(ctx, review); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		guard := func(ctx context.Context, pr *models.PullRequest) error {
+			reviews, err := repos.PRs.GetReviews(ctx, pr.ID)
+			if err != nil {
+				return err
+			}
+			if pr.Status != models.PRStatusOpen || len(reviews) != 1 {
+				t.Errorf("Expected the open PR with its review, got %s and %v", pr.Status, reviews)
+			}
+			return models.ErrNotApproved
+		}
+		if _, err := repos.PRs.MergePR(ctx, "pr1", guard); !errors.Is(err, models.ErrNotApproved) {
+			t.Errorf("MergePR: expected the guard error, got %v", err)
+		}
+
+		pr, _ := repos.PRs.GetByID(ctx, "pr1")
+		if pr.Status != models.PRStatusOpen || pr.MergedAt != nil {
+			t.Errorf("Expected the PR to stay open, got %s merged at %v", pr.Status, pr.MergedAt)
+		}
+	})
+
+	t.Run("A review clears the overdue flag", func(t *testing.T) {
+		repos := open(t)
+		seed(t, repos)
+
+		settings := models.DefaultTeamSettings()
+		settings.ReviewSLAMinutes = 60
+		if err := repos.Teams.UpsertSettings(ctx, "backend", settings); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		later := time.Now().Add(2 * time.Hour)
+		if overdue, err := repos.PRs.MarkOverdue(ctx, later); err != nil || len(overdue) != 1 {
+			t.Fatalf("Expected u2 to be overdue, got %v, %v", overdue, err)
+		}
+
+		review := &models.Review{PRID: "pr1", ReviewerID: "u2", Verdict: models.VerdictApproved, SubmittedAt: time.Now()}
+		if err := repos.PRs.AddReview(ctx, review); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if listed, _ := repos.PRs.GetOverdue(ctx, ""); len(listed) != 0 {
+			t.Errorf("Expected no overdue reviews after the verdict, got %v", listed)
+		}
+		if overdue, _ := repos.PRs.MarkOverdue(ctx, later.Add(time.Hour)); len(overdue) != 0 {
+			t.Errorf("Expected the reviewed assignment not to be flagged again, got %v", overdue)
+		}
+	})
+
+	t.Run("Reviewers of merged or closed PRs are locked", func(t *testing.T) {
+		repos := open(t)
+		seed(t, repos)
+
+		if _, err := repos.PRs.MergePR(ctx, "pr1", nil); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if err := repos.PRs.AddReviewer(ctx, "pr1", "u3"); !errors.Is(err, models.ErrPRMerged) {
+			t.Errorf("AddReviewer: expected ErrPRMerged, got %v", err)
+		}
+		if err := repos.PRs.RemoveReviewer(ctx, "pr1", "u2"); !errors.Is(err, models.ErrPRMerged) {
+			t.Errorf("RemoveReviewer: expected ErrPRMerged, got %v", err)
+		}
+		if pr, _ := repos.PRs.GetByID(ctx, "pr1"); !slices.Equal(pr.Reviewers, []string{"u2"}) {
+			t.Errorf("Expected reviewers [u2], got %v", pr.Reviewers)
+		}
+
+		err := repos.PRs.CreatePR(ctx, &models.PullRequest{
+			ID: "pr2", Name: "Fix", AuthorID: "u2", Status: models.PRStatusOpen, CreatedAt: time.Now(),
+		})
+		if err != nil {
+			t.Fatalf("Failed to create PR: %v", err)
+		}
+		if _, err := repos.PRs.UpdateStatus(ctx, "pr2", models.PRStatusClosed, []models.PRStatus{models.PRStatusOpen}, nil, nil); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if err := repos.PRs.AddReviewer(ctx, "pr2", "u1"); !errors.Is(err, models.ErrInvalidStatus) {
+			t.Errorf("AddReviewer: expected ErrInvalidStatus, got %v", err)
+		}
+	})
+
+	t.Run("A merge and a concurrent close don't both win", func(t *testing.T) {
+		repos := open(t)
+		seed(t, repos)
+
+		openOnly := func(ctx context.Context, pr *models.PullRequest) error {
+			if pr.Status != models.PRStatusOpen {
+				return models.ErrInvalidStatus
+			}
+			return nil
+		}
+		for i := range 10 {
+			id := fmt.Sprintf("race%d", i)
+			err := repos.PRs.CreatePR(ctx, &models.PullRequest{
+				ID: id, Name: "Race", AuthorID: "u1", Status: models.PRStatusOpen, CreatedAt: time.Now(),
+			})
+			if err != nil {
+				t.Fatalf("Failed to create PR: %v", err)
+			}
+
+			var mergeErr, closeErr error
+			var wg sync.WaitGroup
+			wg.Go(func() {
+				_, mergeErr = repos.PRs.MergePR(ctx, id, openOnly)
+			})
+			wg.Go(func() {
+				_, closeErr = repos.PRs.UpdateStatus(ctx, id, models.PRStatusClosed,
+					[]models.PRStatus{models.PRStatusOpen, models.PRStatusDraft}, nil, nil)
+			})
+			wg.Wait()
+
+			pr, err := repos.PRs.GetByID(ctx, id)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			switch pr.Status {
+			case models.PRStatusMerged:
+				if mergeErr != nil || !errors.Is(closeErr, models.ErrPRMerged) {
+					t.Errorf("%s merged: expected merge to succeed and close to get ErrPRMerged, got %v and %v",
+						id, mergeErr, closeErr)
+				}
+			case models.PRStatusClosed:
+				if closeErr != nil || !errors.Is(mergeErr, models.ErrInvalidStatus) {
+					t.Errorf("%s closed: expected close to succeed and merge to get ErrInvalidStatus, got %v and %v",
+						id, closeErr, mergeErr)
+				}
+			default:
+				t.Errorf("%s: expected MERGED or CLOSED, got %s", id, pr.Status)
+			}
+		}
+
+		if _, err := repos.PRs.MergePR(ctx, "pr1", nil); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		_, err := repos.PRs.UpdateStatus(ctx, "pr1", models.PRStatusOpen, []models.PRStatus{models.PRStatusClosed}, nil, nil)
+		if !errors.Is(err, models.ErrPRMerged) {
+			t.Errorf("Reopen: expected ErrPRMerged, got %v", err)
+		}
+	})
+
+	t.Run("Reviewers are unique per PR", func(t *testing.T) {
+		repos := open(t)
+		seed(t, repos)
+
+		if err := repos.PRs.AddReviewer(ctx, "pr1", "u2"); !errors.Is(err, models.ErrAlreadyAssigned) {
+			t.Errorf("AddReviewer: expected ErrAlreadyAssigned, got %v", err)
+		}
+		if err := repos.PRs.AddReviewer(ctx, "pr1", "u3"); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		snapshot, _ := repos.PRs.GetByID(ctx, "pr1")
+		err := repos.PRs.ReassignReviewer(ctx, snapshot, "u2", "u3", false)
+		if !errors.Is(err, models.ErrAlreadyExists) {
+			t.Errorf("ReassignReviewer: expected ErrAlreadyExists, got %v", err)
+		}
+		if err := repos.PRs.RemoveReviewer(ctx, "pr1", "u4"); !errors.Is(err, models.ErrNotAssigned) {
+			t.Errorf("RemoveReviewer: expected ErrNotAssigned, got %v", err)
+		}
+
+		pr, _ := repos.PRs.GetByID(ctx, "pr1")
+		if reviewers := sorted(pr.Reviewers); !slices.Equal(reviewers, []string{"u2", "u3"}) {
+			t.Errorf("Expected reviewers [u2 u3], got %v", reviewers)
+		}
+	})
+
+	t.Run("Deactivation reassigns open reviews", func(t *testing.T) {
+		repos := open(t)
+		seed(t, repos)
+
+		err := repos.PRs.CreatePR(ctx, &models.PullRequest{
+			ID: "pr2", Name: "Fix", AuthorID: "u3", Status: models.PRStatusOpen,
+			Reviewers: []string{"u2"}, CreatedAt: time.Now(),
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		offered := map[string]models.ReviewToUpdate{}
+		pick := func(ctx context.Context, review models.ReviewToUpdate) (string, bool, error) {
+			offered[review.PRID] = review
+			// The deactivation is visible to reads made while picking.
+			if user, err := repos.Users.GetUser(ctx, "u2"); err != nil || user.IsActive {
+				t.Errorf("Expected u2 to be inactive while picking, got %+v, %v", user, err)
+			}
+			if review.PRID == "pr2" {
+				return "", false, nil
+			}
+			return "u3", true, nil
+		}
+		if err := repos.Users.DeactivateUsers(ctx, []string{"u2"}, pick); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if len(offered) != 2 {
+			t.Fatalf("Expected a pick for pr1 and pr2, got %v", offered)
+		}
+		for _, id := range []string{"pr1", "pr2"} {
+			if review := offered[id]; review.OldReviewerID != "u2" || !slices.Equal(review.Reviewers, []string{"u2"}) {
+				t.Errorf("Unexpected review to update for %s: %+v", id, review)
+			}
+		}
+		if offered["pr2"].AuthorID != "u3" {
+			t.Errorf("Expected author u3 for pr2, got %+v", offered["pr2"])
+		}
+
+		user, _ := repos.Users.GetUser(ctx, "u2")
+		if user == nil || user.IsActive {
+			t.Errorf("Expected u2 to be inactive, got %+v", user)
+		}
+		pr1, _ := repos.PRs.GetByID(ctx, "pr1")
+		if !slices.Equal(pr1.Reviewers, []string{"u3"}) {
+			t.Errorf("Expected u2 to be replaced by u3 on pr1, got %v", pr1.Reviewers)
+		}
+		if !slices.Equal(pr1.FallbackReviewers, []string{"u3"}) {
+			t.Errorf("Expected u3 to be stored as a fallback reviewer, got %v", pr1.FallbackReviewers)
+		}
+		pr2, _ := repos.PRs.GetByID(ctx, "pr2")
+		if len(pr2.Reviewers) != 0 {
+			t.Errorf("Expected u2 to be removed from pr2, got %v", pr2.Reviewers)
+		}
+	})
+
+	t.Run("Deactivation picks see earlier replacements", func(t *testing.T) {
+		repos := open(t)
+
+		err := repos.Teams.CreateTeam(ctx, &models.Team{Name: "balance", Members: []models.TeamMember{
+			{UserID: "a1", IsActive: true},
+			{UserID: "r1", IsActive: true},
+			{UserID: "c1", IsActive: true},
+			{UserID: "c2", IsActive: true},
+		}})
+		if err != nil {
+			t.Fatalf("Failed to create team: %v", err)
+		}
+		for _, id := range []string{"p1", "p2"} {
+			err := repos.PRs.CreatePR(ctx, &models.PullRequest{
+				ID: id, Name: id, AuthorID: "a1", Status: models.PRStatusOpen,
+				Reviewers: []string{"r1"}, CreatedAt: time.Now(),
+			})
+			if err != nil {
+				t.Fatalf("Failed to create PR: %v", err)
+			}
+		}
+
+		leastLoaded := func(ctx context.Context, review models.ReviewToUpdate) (string, bool, error) {
+			now := time.Now()
+			users, err := repos.Users.GetActiveUsersByTeam(ctx, review.TeamName, now, now)
+			if err != nil {
+				return "", false, err
+			}
+			counts, err := repos.Users.GetOpenReviewCounts(ctx, review.TeamName)
+			if err != nil {
+				return "", false, err
+			}
+			best := ""
+			for _, u := range users {
+				if u.ID == review.AuthorID || slices.Contains(review.Reviewers, u.ID) {
+					continue
+				}
+				if best == "" || counts[u.ID] < counts[best] || counts[u.ID] == counts[best] && u.ID < best {
+					best = u.ID
+				}
+			}
+			return best, false, nil
+		}
+		if err := repos.Users.DeactivateUsers(ctx, []string{"r1"}, leastLoaded); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		p1, _ := repos.PRs.GetByID(ctx, "p1")
+		p2, _ := repos.PRs.GetByID(ctx, "p2")
+		if reviewers := sorted(append(p1.Reviewers, p2.Reviewers...)); !slices.Equal(reviewers, []string{"c1", "c2"}) {
+			t.Errorf("Expected the reviews to be split between c1 and c2, got %v", reviewers)
+		}
+	})
+}
+
+// seed creates team "backend" with author u1, reviewer u2, spare u3 and inactive
+// u4, and pr1 by u1 reviewed by u2.
+func seed(t *testing.T, repos Repositories) {
+	t.Helper()
+	ctx := context.Background()
+
+	err := repos.Teams.CreateTeam(ctx, &models.Team{Name: "backend", Members: []models.TeamMember{
+		{UserID: "u1", UserName: "Alice", IsActive: true},
+		{UserID: "u2", UserName: "Bob", IsActive: true},
+		{UserID: "u3", UserName: "Carol", IsActive: true},
+		{UserID: "u4", UserName: "Dave", IsActive: false},
+	}})
+	if err != nil {
+		t.Fatalf("Failed to create team: %v", err)
+	}
+	err = repos.PRs.CreatePR(ctx, &models.PullRequest{
+		ID: "pr1", Name: "Feature", AuthorID: "u1", Status: models.PRStatusOpen,
+		Reviewers: []string{"u2"}, CreatedAt: time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create PR: %v", err)
+	}
+}
+
+func sorted(values []string) []string {
+	values = slices.Clone(values)
+	slices.Sort(values)
+	return values
+}
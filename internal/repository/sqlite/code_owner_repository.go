@@ -0,0 +1,74 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+)
+
+type CodeOwnerRepository struct {
+	db *sql.DB
+}
+
+func NewCodeOwnerRepository(db *sql.DB) *CodeOwnerRepository {
+	return &CodeOwnerRepository{db: db}
+}
+
+func (r *CodeOwnerRepository) GetRules(ctx context.Context, repositoryID string) ([]models.CodeOwnerRule, error) {
+	query := `
+        SELECT pattern, user_ids, team_names
+        FROM code_owner_rules
+        WHERE repository_id = ?
+        ORDER BY position
+    `
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, repositoryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query code owner rules: %w", err)
+	}
+
+	rules, err := collect(rows, func(rows *sql.Rows) (models.CodeOwnerRule, error) {
+		var (
+			rule         models.CodeOwnerRule
+			users, teams string
+		)
+		if err := rows.Scan(&rule.Pattern, &users, &teams); err != nil {
+			return rule, err
+		}
+		if rule.Users, err = fromJSON(users); err != nil {
+			return rule, err
+		}
+		rule.Teams, err = fromJSON(teams)
+		return rule, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect code owner rules: %w", err)
+	}
+
+	return rules, nil
+}
+
+func (r *CodeOwnerRepository) ReplaceRules(
+	ctx context.Context,
+	repositoryID string,
+	rules []models.CodeOwnerRule,
+) error {
+	return withTx(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM code_owner_rules WHERE repository_id = ?`, repositoryID); err != nil {
+			return fmt.Errorf("failed to clear code owner rules: %w", err)
+		}
+
+		query := `
+            INSERT INTO code_owner_rules (repository_id, position, pattern, user_ids, team_names)
+            VALUES (?, ?, ?, ?, ?)
+        `
+		for i, rule := range rules {
+			_, err := tx.ExecContext(ctx, query, repositoryID, i, rule.Pattern, toJSON(rule.Users), toJSON(rule.Teams))
+			if err != nil {
+				return fmt.Errorf("failed to insert code owner rule: %w", err)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,361 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/repository/repotest"
+)
+
+func TestOpen_Simple(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "reviewer.db")
+
+	db, err := Open(ctx, path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	teams := NewTeamRepository(db)
+	users := NewUserRepository(db)
+	prs := NewPullRequestRepository(db)
+
+	err = teams.CreateTeam(ctx, &models.Team{Name: "backend", Members: []models.TeamMember{
+		{UserID: "u1", UserName: "Author", IsActive: true},
+		{UserID: "u2", UserName: "Bob", IsActive: true},
+	}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	settings := models.DefaultTeamSettings()
+	settings.ReviewSLAMinutes = 60
+	if err := teams.UpsertSettings(ctx, "backend", settings); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	err = prs.CreatePR(ctx, &models.PullRequest{
+		ID: "pr1", Name: "Feature", AuthorID: "u1", Status: models.PRStatusOpen,
+		Reviewers: []string{"u2"}, CreatedAt: time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	t.Run("Reopening keeps data and skips applied migrations", func(t *testing.T) {
+		if err := db.Close(); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		db, err = Open(ctx, path)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		teams, users, prs = NewTeamRepository(db), NewUserRepository(db), NewPullRequestRepository(db)
+
+		pr, err := prs.GetByID(ctx, "pr1")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(pr.Reviewers) != 1 || pr.Reviewers[0] != "u2" {
+			t.Errorf("Expected reviewer u2, got %v", pr.Reviewers)
+		}
+		got, _ := teams.GetSettings(ctx, "backend")
+		if got.ReviewSLAMinutes != 60 {
+			t.Errorf("Expected SLA of 60 minutes, got %d", got.ReviewSLAMinutes)
+		}
+	})
+
+	t.Run("Assignments past the SLA are marked overdue once", func(t *testing.T) {
+		overdue, err := prs.MarkOverdue(ctx, time.Now().Add(30*time.Minute))
+		if err != nil || len(overdue) != 0 {
+			t.Fatalf("Expected nothing overdue yet, got %v, %v", overdue, err)
+		}
+
+		now := time.Now().Add(2 * time.Hour)
+		overdue, err = prs.MarkOverdue(ctx, now)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(overdue) != 1 || overdue[0].ReviewerID != "u2" {
+			t.Fatalf("Expected u2 to be overdue, got %v", overdue)
+		}
+		if due := overdue[0].AssignedAt.Add(time.Hour); !overdue[0].DueAt.Equal(due) {
+			t.Errorf("Expected due at %v, got %v", due, overdue[0].DueAt)
+		}
+
+		overdue, _ = prs.MarkOverdue(ctx, now)
+		if len(overdue) != 0 {
+			t.Errorf("Expected no new overdue reviews, got %v", overdue)
+		}
+		listed, _ := prs.GetOverdue(ctx, "")
+		if len(listed) != 1 {
+			t.Errorf("Expected one listed overdue review, got %v", listed)
+		}
+	})
+
+	t.Run("Unknown users are reported as not found", func(t *testing.T) {
+		_, err := users.SetUserIsActive(ctx, "ghost", false)
+		if !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+		err = prs.AddReviewer(ctx, "pr1", "ghost")
+		if !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+	})
+
+	_ = db.Close()
+}
+
+func TestRepositories_Contract(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Repositories {
+		db, err := Open(context.Background(), ":memory:")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		t.Cleanup(func() { _ = db.Close() })
+
+		return repotest.Repositories{
+			Teams: NewTeamRepository(db),
+			Users: NewUserRepository(db),
+			PRs:   NewPullRequestRepository(db),
+		}
+	})
+}
+
+func TestStorage_Simple(t *testing.T) {
+	ctx := context.Background()
+	db, err := Open(ctx, ":memory:")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	teams := NewTeamRepository(db)
+	users := NewUserRepository(db)
+	prs := NewPullRequestRepository(db)
+	txm := NewTxManager(db)
+
+	err = teams.CreateTeam(ctx, &models.Team{Name: "backend", Members: []models.TeamMember{
+		{UserID: "u1", UserName: "Author", IsActive: true},
+		{UserID: "u2", UserName: "Bob", IsActive: true},
+		{UserID: "u3", UserName: "Carol", IsActive: true},
+	}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	created := models.NewEvent(models.EventPRCreated, models.EventPayload{PRID: "pr1"})
+	err = prs.CreatePR(ctx, &models.PullRequest{
+		ID: "pr1", Name: "Feature", AuthorID: "u1", Status: models.PRStatusOpen,
+		Reviewers: []string{"u2"}, CreatedAt: time.Now(),
+	}, created)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	t.Run("Failed nested transaction is rolled back alone", func(t *testing.T) {
+		failure := errors.New("boom")
+		err := txm.WithinTx(ctx, func(ctx context.Context) error {
+			if _, err := users.SetUserIsActive(ctx, "u3", false); err != nil {
+				return err
+			}
+			err := txm.WithinTx(ctx, func(ctx context.Context) error {
+				if err := prs.AddReviewer(ctx, "pr1", "u3"); err != nil {
+					return err
+				}
+				return failure
+			})
+			if !errors.Is(err, failure) {
+				t.Errorf("Expected the nested fn error, got %v", err)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		user, _ := users.GetUser(ctx, "u3")
+		if user.IsActive {
+			t.Error("Expected the outer deactivation to be committed")
+		}
+		pr, _ := prs.GetByID(ctx, "pr1")
+		if len(pr.Reviewers) != 1 {
+			t.Errorf("Expected the nested reviewer addition to be rolled back, got %v", pr.Reviewers)
+		}
+		if _, err := users.SetUserIsActive(ctx, "u3", true); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	})
+
+	t.Run("Outbox events are dispatched once", func(t *testing.T) {
+		outbox := NewOutboxRepository(db)
+		failure := errors.New("boom")
+		n, err := outbox.Dispatch(ctx, 10, func(ctx context.Context, event models.Event) error {
+			return failure
+		})
+		if n != 0 || !errors.Is(err, failure) {
+			t.Fatalf("Expected the handle error, got %d, %v", n, err)
+		}
+
+		var handled []string
+		handle := func(ctx context.Context, event models.Event) error {
+			handled = append(handled, event.ID)
+			return nil
+		}
+		if n, err := outbox.Dispatch(ctx, 10, handle); n != 1 || err != nil {
+			t.Fatalf("Expected one dispatched event, got %d, %v", n, err)
+		}
+		if n, err := outbox.Dispatch(ctx, 10, handle); n != 0 || err != nil {
+			t.Fatalf("Expected nothing left to dispatch, got %d, %v", n, err)
+		}
+		if len(handled) != 1 || handled[0] != created.ID {
+			t.Errorf("Expected %s to be handled, got %v", created.ID, handled)
+		}
+	})
+
+	t.Run("Deliveries are enqueued for interested subscriptions", func(t *testing.T) {
+		notifications := NewNotificationRepository(db)
+		all := &models.Subscription{URL: "http://all", Secret: "s"}
+		merged := &models.Subscription{URL: "http://merged", Secret: "s", EventTypes: []string{models.EventPRMerged}}
+		for _, sub := range []*models.Subscription{all, merged} {
+			if err := notifications.CreateSubscription(ctx, sub); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+		}
+		if err := notifications.EnqueueDeliveries(ctx, created); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if err := notifications.EnqueueDeliveries(ctx, created); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		now := time.Now()
+		claimed, err := notifications.ClaimDueDeliveries(ctx, now, time.Minute, 10)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(claimed) != 1 || claimed[0].URL != "http://all" || claimed[0].Event.ID != created.ID {
+			t.Fatalf("Expected one delivery to http://all, got %+v", claimed)
+		}
+		if again, _ := notifications.ClaimDueDeliveries(ctx, now, time.Minute, 10); len(again) != 0 {
+			t.Errorf("Expected a claimed delivery to be leased, got %+v", again)
+		}
+
+		if err := notifications.MarkFailed(ctx, claimed[0].ID, "timeout", now, true); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		dead, _ := notifications.ListDeadLetters(ctx)
+		if len(dead) != 1 || dead[0].LastError != "timeout" || dead[0].Attempts != 1 {
+			t.Errorf("Expected one dead letter, got %+v", dead)
+		}
+		if err := notifications.ReplayDeadLetter(ctx, 999, now); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("Webhook deliveries are recorded once", func(t *testing.T) {
+		deliveries := NewWebhookDeliveryRepository(db)
+		if ok, err := deliveries.MarkReceived(ctx, models.ProviderGitHub, "d1"); !ok || err != nil {
+			t.Fatalf("Expected a new delivery, got %v, %v", ok, err)
+		}
+		if ok, _ := deliveries.MarkReceived(ctx, models.ProviderGitHub, "d1"); ok {
+			t.Error("Expected a repeated delivery to be reported")
+		}
+		if err := deliveries.Forget(ctx, models.ProviderGitHub, "d1"); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if ok, _ := deliveries.MarkReceived(ctx, models.ProviderGitHub, "d1"); !ok {
+			t.Error("Expected a forgotten delivery to be new again")
+		}
+	})
+
+	t.Run("Repositories and code owners round trip", func(t *testing.T) {
+		repos := NewRepositoryRepository(db)
+		repo := &models.Repository{
+			ID: "acme/api", TeamName: "backend", Reviewers: []string{"u3", "u2"},
+			Settings: &models.RepositorySettings{ReviewersCount: 1, RequiredApprovals: 1},
+		}
+		if err := repos.CreateRepository(ctx, repo); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if err := repos.CreateRepository(ctx, repo); !errors.Is(err, models.ErrAlreadyExists) {
+			t.Errorf("Expected ErrAlreadyExists, got %v", err)
+		}
+		if err := repos.CreateRepository(ctx, &models.Repository{ID: "x", TeamName: "ghost"}); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound for unknown team, got %v", err)
+		}
+
+		got, err := repos.GetRepository(ctx, "acme/api")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(got.Reviewers) != 2 || got.Reviewers[0] != "u2" || got.Settings == nil || got.Settings.ReviewersCount != 1 {
+			t.Errorf("Unexpected repository: %+v", got)
+		}
+
+		repo.Reviewers, repo.Settings = nil, nil
+		if err := repos.UpdateRepository(ctx, repo); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		listed, _ := repos.ListRepositories(ctx, "backend")
+		if len(listed) != 1 || len(listed[0].Reviewers) != 0 || listed[0].Settings != nil {
+			t.Errorf("Expected the updated repository, got %+v", listed)
+		}
+		if _, err := repos.GetRepository(ctx, "ghost"); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+
+		owners := NewCodeOwnerRepository(db)
+		rules := []models.CodeOwnerRule{
+			{Pattern: "*", Teams: []string{"backend"}},
+			{Pattern: "/db/", Users: []string{"u3"}},
+		}
+		if err := owners.ReplaceRules(ctx, "acme/api", rules); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		gotRules, _ := owners.GetRules(ctx, "acme/api")
+		if len(gotRules) != 2 || gotRules[1].Pattern != "/db/" || gotRules[1].Users[0] != "u3" {
+			t.Errorf("Unexpected rules: %+v", gotRules)
+		}
+		if defaults, _ := owners.GetRules(ctx, ""); len(defaults) != 0 {
+			t.Errorf("Expected no default rules, got %+v", defaults)
+		}
+	})
+
+	t.Run("Overdue reviews follow the repository team SLA", func(t *testing.T) {
+		err := teams.CreateTeam(ctx, &models.Team{Name: "platform", Members: []models.TeamMember{
+			{UserID: "p1", IsActive: true},
+		}})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		settings := models.DefaultTeamSettings()
+		settings.ReviewSLAMinutes = 30
+		if err := teams.UpsertSettings(ctx, "platform", settings); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		err = NewRepositoryRepository(db).CreateRepository(ctx, &models.Repository{ID: "acme/infra", TeamName: "platform"})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		err = prs.CreatePR(ctx, &models.PullRequest{
+			ID: "pr-infra", Name: "Infra", AuthorID: "u1", Status: models.PRStatusOpen, RepositoryID: "acme/infra",
+			Reviewers: []string{"p1"}, CreatedAt: time.Now(),
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		overdue, err := prs.MarkOverdue(ctx, time.Now().Add(time.Hour))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(overdue) != 1 || overdue[0].PRID != "pr-infra" || overdue[0].TeamName != "platform" {
+			t.Errorf("Expected pr-infra to be overdue for platform, got %+v", overdue)
+		}
+		if listed, _ := prs.GetOverdue(ctx, "platform"); len(listed) != 1 {
+			t.Errorf("Expected pr-infra to be listed for platform, got %+v", listed)
+		}
+	})
+}
@@ -0,0 +1,91 @@
+-- Timestamps are Unix microseconds in UTC, arrays are JSON.
+
+CREATE TABLE teams (
+    name TEXT PRIMARY KEY
+);
+
+CREATE TABLE team_settings (
+    team_name TEXT PRIMARY KEY REFERENCES teams(name) ON DELETE CASCADE,
+    reviewers_count INTEGER NOT NULL DEFAULT 2 CHECK (reviewers_count >= 1),
+    min_reviewers INTEGER NOT NULL DEFAULT 0 CHECK (min_reviewers >= 0 AND min_reviewers <= reviewers_count),
+    required_approvals INTEGER NOT NULL DEFAULT 0 CHECK (required_approvals >= 0),
+    chat_webhook_url TEXT NOT NULL DEFAULT '',
+    review_sla_minutes INTEGER NOT NULL DEFAULT 0 CHECK (review_sla_minutes >= 0),
+    sla_auto_reassign INTEGER NOT NULL DEFAULT 0,
+    fallback_teams TEXT NOT NULL DEFAULT '[]'
+);
+
+CREATE TABLE users (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    is_active INTEGER NOT NULL DEFAULT 1,
+    team_name TEXT NOT NULL REFERENCES teams(name) ON DELETE CASCADE,
+    chat_handle TEXT NOT NULL DEFAULT '',
+    quiet_hours_from TEXT NOT NULL DEFAULT '',
+    quiet_hours_to TEXT NOT NULL DEFAULT '',
+    timezone TEXT NOT NULL DEFAULT 'UTC',
+    last_digest_at INTEGER
+);
+
+CREATE TABLE user_identities (
+    provider TEXT NOT NULL,
+    login TEXT NOT NULL,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    PRIMARY KEY (provider, login)
+);
+
+CREATE TABLE absences (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    starts_at INTEGER NOT NULL,
+    ends_at INTEGER NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    external_uid TEXT UNIQUE,
+    created_at INTEGER NOT NULL,
+    handled_at INTEGER,
+    CHECK (ends_at > starts_at)
+);
+
+CREATE TABLE pull_requests (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    author_id TEXT NOT NULL REFERENCES users(id),
+    status TEXT NOT NULL DEFAULT 'OPEN' CHECK (status IN ('DRAFT', 'OPEN', 'CLOSED', 'MERGED')),
+    created_at INTEGER NOT NULL,
+    merged_at INTEGER,
+    files TEXT NOT NULL DEFAULT '[]',
+    repository_id TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE pr_reviewers (
+    pr_id TEXT NOT NULL REFERENCES pull_requests(id) ON DELETE CASCADE,
+    reviewer_id TEXT NOT NULL REFERENCES users(id),
+    assigned_at INTEGER NOT NULL,
+    overdue_at INTEGER,
+    from_fallback INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (pr_id, reviewer_id)
+);
+
+CREATE TABLE pr_reviews (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    pr_id TEXT NOT NULL REFERENCES pull_requests(id) ON DELETE CASCADE,
+    reviewer_id TEXT NOT NULL REFERENCES users(id),
+    verdict TEXT NOT NULL CHECK (verdict IN ('APPROVED', 'CHANGES_REQUESTED', 'COMMENTED')),
+    submitted_at INTEGER NOT NULL
+);
+
+CREATE TABLE outbox (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    event_id TEXT NOT NULL UNIQUE,
+    event_type TEXT NOT NULL,
+    event TEXT NOT NULL,
+    created_at INTEGER NOT NULL,
+    dispatched_at INTEGER
+);
+
+CREATE INDEX idx_users_team_active ON users(team_name, is_active);
+CREATE INDEX idx_pull_requests_author ON pull_requests(author_id);
+CREATE INDEX idx_pr_reviewers_reviewer ON pr_reviewers(reviewer_id);
+CREATE INDEX idx_pr_reviews_pr ON pr_reviews(pr_id, reviewer_id, submitted_at);
+CREATE INDEX idx_absences_user ON absences(user_id, ends_at);
+CREATE INDEX idx_outbox_pending ON outbox(id) WHERE dispatched_at IS NULL;
@@ -0,0 +1,59 @@
+-- Tables the PostgreSQL schema has besides the core ones, so the binary can run on SQLite.
+
+CREATE TABLE webhook_deliveries (
+    provider TEXT NOT NULL,
+    delivery_id TEXT NOT NULL,
+    received_at INTEGER NOT NULL,
+    PRIMARY KEY (provider, delivery_id)
+);
+
+CREATE TABLE webhook_subscriptions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    event_types TEXT NOT NULL DEFAULT '[]',
+    created_at INTEGER NOT NULL
+);
+
+CREATE TABLE outbound_deliveries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    subscription_id INTEGER NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+    event_id TEXT NOT NULL,
+    event_type TEXT NOT NULL,
+    event TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'PENDING' CHECK (status IN ('PENDING', 'DELIVERED', 'DEAD')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at INTEGER NOT NULL,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at INTEGER NOT NULL,
+    delivered_at INTEGER,
+    UNIQUE (subscription_id, event_id)
+);
+
+CREATE TABLE code_owner_rules (
+    repository_id TEXT NOT NULL DEFAULT '',
+    position INTEGER NOT NULL,
+    pattern TEXT NOT NULL,
+    user_ids TEXT NOT NULL DEFAULT '[]',
+    team_names TEXT NOT NULL DEFAULT '[]',
+    PRIMARY KEY (repository_id, position)
+);
+
+CREATE TABLE repositories (
+    id TEXT PRIMARY KEY,
+    team_name TEXT NOT NULL REFERENCES teams(name) ON DELETE CASCADE,
+    reviewers_count INTEGER CHECK (reviewers_count >= 1),
+    min_reviewers INTEGER CHECK (min_reviewers >= 0 AND min_reviewers <= reviewers_count),
+    required_approvals INTEGER CHECK (required_approvals >= 0 AND required_approvals <= reviewers_count),
+    created_at INTEGER NOT NULL
+);
+
+CREATE TABLE repository_reviewers (
+    repository_id TEXT NOT NULL REFERENCES repositories(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    PRIMARY KEY (repository_id, user_id)
+);
+
+CREATE INDEX idx_outbound_deliveries_due ON outbound_deliveries(status, next_attempt_at);
+CREATE INDEX idx_repositories_team ON repositories(team_name);
+CREATE INDEX idx_pull_requests_repository ON pull_requests(repository_id);
@@ -0,0 +1,236 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+)
+
+const selectDeliveries = `
+        SELECT d.id, d.subscription_id, s.url, s.secret, d.event, d.status, d.attempts,
+               d.next_attempt_at, d.last_error, d.created_at, d.delivered_at
+        FROM outbound_deliveries d
+        JOIN webhook_subscriptions s ON s.id = d.subscription_id
+    `
+
+type NotificationRepository struct {
+	db *sql.DB
+}
+
+func NewNotificationRepository(db *sql.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+func (r *NotificationRepository) CreateSubscription(ctx context.Context, sub *models.Subscription) error {
+	query := `
+        INSERT INTO webhook_subscriptions (url, secret, event_types, created_at)
+        VALUES (?, ?, ?, ?)
+        RETURNING id, created_at
+    `
+	var createdAt int64
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, sub.URL, sub.Secret, toJSON(sub.EventTypes), toMicros(time.Now()))
+	if err := row.Scan(&sub.ID, &createdAt); err != nil {
+		return fmt.Errorf("failed to insert subscription: %w", err)
+	}
+	sub.CreatedAt = fromMicros(createdAt)
+	return nil
+}
+
+func (r *NotificationRepository) ListSubscriptions(ctx context.Context) ([]models.Subscription, error) {
+	query := `
+        SELECT id, url, secret, event_types, created_at
+        FROM webhook_subscriptions
+        ORDER BY id
+    `
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query subscriptions: %w", err)
+	}
+
+	subs, err := collect(rows, func(rows *sql.Rows) (models.Subscription, error) {
+		var (
+			sub        models.Subscription
+			eventTypes string
+			createdAt  int64
+		)
+		if err := rows.Scan(&sub.ID, &sub.URL, &sub.Secret, &eventTypes, &createdAt); err != nil {
+			return sub, err
+		}
+		sub.CreatedAt = fromMicros(createdAt)
+		sub.EventTypes, err = fromJSON(eventTypes)
+		return sub, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect subscriptions: %w", err)
+	}
+
+	return subs, nil
+}
+
+func (r *NotificationRepository) DeleteSubscription(ctx context.Context, id int64) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM webhook_subscriptions WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete subscription: %w", err)
+	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to read affected rows: %w", err)
+	} else if affected == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+func (r *NotificationRepository) EnqueueDeliveries(ctx context.Context, event models.Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	query := `
+        INSERT INTO outbound_deliveries (subscription_id, event_id, event_type, event, next_attempt_at, created_at)
+        SELECT id, ?1, ?2, ?3, ?4, ?5
+        FROM webhook_subscriptions
+        WHERE json_array_length(event_types) = 0
+           OR EXISTS (SELECT 1 FROM json_each(event_types) WHERE value = ?2)
+        ON CONFLICT (subscription_id, event_id) DO NOTHING
+    `
+	_, err = conn(ctx, r.db).ExecContext(ctx, query,
+		event.ID, event.Type, string(body), toMicros(event.OccurredAt), toMicros(time.Now()))
+	if err != nil {
+		return fmt.Errorf("failed to enqueue deliveries: %w", err)
+	}
+	return nil
+}
+
+// ClaimDueDeliveries postpones the claimed deliveries in one transaction; SQLite
+// has a single writer, so concurrent workers never claim the same ones.
+func (r *NotificationRepository) ClaimDueDeliveries(
+	ctx context.Context,
+	now time.Time,
+	lease time.Duration,
+	limit int,
+) ([]models.Delivery, error) {
+	var deliveries []models.Delivery
+	err := withTx(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		query := selectDeliveries + `
+            WHERE d.status = 'PENDING' AND d.next_attempt_at <= ?
+            ORDER BY d.next_attempt_at
+            LIMIT ?
+        `
+		rows, err := tx.QueryContext(ctx, query, toMicros(now), limit)
+		if err != nil {
+			return fmt.Errorf("failed to claim deliveries: %w", err)
+		}
+		deliveries, err = collect(rows, scanDelivery)
+		if err != nil {
+			return fmt.Errorf("failed to collect deliveries: %w", err)
+		}
+
+		next := now.Add(lease)
+		for i := range deliveries {
+			_, err := tx.ExecContext(ctx, `UPDATE outbound_deliveries SET next_attempt_at = ? WHERE id = ?`,
+				toMicros(next), deliveries[i].ID)
+			if err != nil {
+				return fmt.Errorf("failed to claim delivery: %w", err)
+			}
+			deliveries[i].NextAttemptAt = next
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (r *NotificationRepository) MarkDelivered(ctx context.Context, id int64, at time.Time) error {
+	query := `
+        UPDATE outbound_deliveries
+        SET status = 'DELIVERED', attempts = attempts + 1, delivered_at = ?, last_error = ''
+        WHERE id = ?
+    `
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, toMicros(at), id); err != nil {
+		return fmt.Errorf("failed to mark delivery as delivered: %w", err)
+	}
+	return nil
+}
+
+func (r *NotificationRepository) MarkFailed(
+	ctx context.Context,
+	id int64,
+	lastError string,
+	nextAttemptAt time.Time,
+	dead bool,
+) error {
+	status := models.DeliveryPending
+	if dead {
+		status = models.DeliveryDead
+	}
+
+	query := `
+        UPDATE outbound_deliveries
+        SET status = ?, attempts = attempts + 1, last_error = ?, next_attempt_at = ?
+        WHERE id = ?
+    `
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, status, lastError, toMicros(nextAttemptAt), id); err != nil {
+		return fmt.Errorf("failed to mark delivery as failed: %w", err)
+	}
+	return nil
+}
+
+func (r *NotificationRepository) ListDeadLetters(ctx context.Context) ([]models.Delivery, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, selectDeliveries+` WHERE d.status = 'DEAD' ORDER BY d.id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query dead letters: %w", err)
+	}
+
+	deliveries, err := collect(rows, scanDelivery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect dead letters: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+func (r *NotificationRepository) ReplayDeadLetter(ctx context.Context, id int64, at time.Time) error {
+	query := `
+        UPDATE outbound_deliveries
+        SET status = 'PENDING', attempts = 0, next_attempt_at = ?
+        WHERE id = ? AND status = 'DEAD'
+    `
+	res, err := conn(ctx, r.db).ExecContext(ctx, query, toMicros(at), id)
+	if err != nil {
+		return fmt.Errorf("failed to replay dead letter: %w", err)
+	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to read affected rows: %w", err)
+	} else if affected == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+func scanDelivery(rows *sql.Rows) (models.Delivery, error) {
+	var (
+		d                        models.Delivery
+		body                     string
+		nextAttemptAt, createdAt int64
+		deliveredAt              sql.NullInt64
+	)
+	err := rows.Scan(&d.ID, &d.SubscriptionID, &d.URL, &d.Secret, &body, &d.Status, &d.Attempts,
+		&nextAttemptAt, &d.LastError, &createdAt, &deliveredAt)
+	if err != nil {
+		return d, err
+	}
+	d.NextAttemptAt = fromMicros(nextAttemptAt)
+	d.CreatedAt = fromMicros(createdAt)
+	d.DeliveredAt = fromNullMicros(deliveredAt)
+	if err := json.Unmarshal([]byte(body), &d.Event); err != nil {
+		return d, fmt.Errorf("failed to decode event: %w", err)
+	}
+	return d, nil
+}
@@ -0,0 +1,90 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+)
+
+type OutboxRepository struct {
+	db *sql.DB
+}
+
+func NewOutboxRepository(db *sql.DB) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+// Dispatch runs handle outside of a transaction: handlers may call slow endpoints
+// and would hold the only connection meanwhile. A database file serves a single
+// replica with a single dispatcher, so no one else picks the same events.
+func (r *OutboxRepository) Dispatch(
+	ctx context.Context,
+	limit int,
+	handle func(ctx context.Context, event models.Event) error,
+) (int, error) {
+	query := `
+        SELECT id, event
+        FROM outbox
+        WHERE dispatched_at IS NULL
+        ORDER BY id
+        LIMIT ?
+    `
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query outbox: %w", err)
+	}
+
+	type outboxRow struct {
+		id    int64
+		event models.Event
+	}
+	pending, err := collect(rows, func(rows *sql.Rows) (outboxRow, error) {
+		var (
+			item outboxRow
+			body string
+		)
+		if err := rows.Scan(&item.id, &body); err != nil {
+			return item, err
+		}
+		return item, json.Unmarshal([]byte(body), &item.event)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to collect outbox: %w", err)
+	}
+
+	dispatched := 0
+	for _, item := range pending {
+		if err := handle(ctx, item.event); err != nil {
+			return dispatched, err
+		}
+		query := `UPDATE outbox SET dispatched_at = ? WHERE id = ?`
+		if _, err := conn(ctx, r.db).ExecContext(ctx, query, toMicros(time.Now()), item.id); err != nil {
+			return dispatched, fmt.Errorf("failed to mark outbox dispatched: %w", err)
+		}
+		dispatched++
+	}
+
+	return dispatched, nil
+}
+
+func insertOutbox(ctx context.Context, tx *sql.Tx, events []models.Event) error {
+	query := `
+        INSERT INTO outbox (event_id, event_type, event, created_at)
+        VALUES (?, ?, ?, ?)
+        ON CONFLICT (event_id) DO NOTHING
+    `
+	for _, event := range events {
+		body, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to encode event: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, query, event.ID, event.Type, string(body), toMicros(event.OccurredAt)); err != nil {
+			return fmt.Errorf("failed to insert outbox event: %w", err)
+		}
+	}
+	return nil
+}
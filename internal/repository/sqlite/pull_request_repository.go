@@ -0,0 +1,530 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/repository"
+)
+
+type PullRequestRepository struct {
+	db *sql.DB
+}
+
+func NewPullRequestRepository(db *sql.DB) *PullRequestRepository {
+	return &PullRequestRepository{db: db}
+}
+
+func (r *PullRequestRepository) CreatePR(ctx context.Context, pr *models.PullRequest, events ...models.Event) error {
+	return withTx(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		queryPR := `
+            INSERT INTO pull_requests (id, name, author_id, status, created_at, merged_at, files, repository_id)
+            VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+        `
+		_, err := tx.ExecContext(ctx, queryPR, pr.ID, pr.Name, pr.AuthorID, pr.Status, toMicros(pr.CreatedAt),
+			nullMicros(pr.MergedAt), toJSON(pr.Files), pr.RepositoryID)
+		if err != nil {
+			if IsUnique(err) {
+				return fmt.Errorf("pr exists: %w", models.ErrAlreadyExists)
+			}
+			return fmt.Errorf("failed to insert pr: %w", err)
+		}
+
+		queryReviewers := `
+            INSERT INTO pr_reviewers (pr_id, reviewer_id, assigned_at, from_fallback)
+            VALUES (?, ?, ?, ?)
+        `
+		now := toMicros(time.Now())
+		for _, reviewerID := range pr.Reviewers {
+			fromFallback := slices.Contains(pr.FallbackReviewers, reviewerID)
+			if _, err := tx.ExecContext(ctx, queryReviewers, pr.ID, reviewerID, now, fromFallback); err != nil {
+				return fmt.Errorf("failed to insert reviewer %s: %w", reviewerID, err)
+			}
+		}
+
+		return insertOutbox(ctx, tx, events)
+	})
+}
+
+// MergePR calls guard inside the transaction; with one writer at a time, reviews
+// can't change while it decides.
+func (r *PullRequestRepository) MergePR(
+	ctx context.Context,
+	id string,
+	guard repository.MergeGuard,
+	events ...models.Event,
+) (*models.PullRequest, error) {
+	var pr *models.PullRequest
+	err := withTx(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		current, err := r.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if current.Status == models.PRStatusMerged {
+			events = nil
+		}
+		if guard != nil {
+			if err := guard(ctx, current); err != nil {
+				return err
+			}
+		}
+
+		query := `
+            UPDATE pull_requests
+            SET status = 'MERGED',
+                merged_at = COALESCE(merged_at, ?2)
+            WHERE id = ?1
+            RETURNING id, name, author_id, status, created_at, merged_at, files, repository_id
+        `
+		pr, err = scanPullRequest(tx.QueryRowContext(ctx, query, id, toMicros(time.Now())))
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return models.ErrNotFound
+			}
+			return fmt.Errorf("failed to merge pr: %w", err)
+		}
+
+		if err := loadReviewers(ctx, tx, pr); err != nil {
+			return err
+		}
+
+		return insertOutbox(ctx, tx, events)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pr, nil
+}
+
+// ReassignReviewer compares the snapshot inside the transaction; SQLite takes
+// one writer at a time, so no other change of the PR can come in between.
+func (r *PullRequestRepository) ReassignReviewer(
+	ctx context.Context,
+	snapshot *models.PullRequest,
+	oldUserID, newUserID string,
+	fromFallback bool,
+	events ...models.Event,
+) error {
+	prID := snapshot.ID
+	return withTx(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		current := models.PullRequest{ID: prID}
+		err := tx.QueryRowContext(ctx, `SELECT status FROM pull_requests WHERE id = ?`, prID).Scan(&current.Status)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return models.ErrNotFound
+			}
+			return fmt.Errorf("failed to read pr: %w", err)
+		}
+		if err := loadReviewers(ctx, tx, &current); err != nil {
+			return err
+		}
+		if !sameReviewState(snapshot, &current) {
+			return models.ErrPRChanged
+		}
+
+		query := `
+            UPDATE pr_reviewers
+            SET reviewer_id = ?3, from_fallback = ?4, assigned_at = ?5, overdue_at = NULL
+            WHERE pr_id = ?1 AND reviewer_id = ?2
+        `
+		res, err := tx.ExecContext(ctx, query, prID, oldUserID, newUserID, fromFallback, toMicros(time.Now()))
+		if err != nil {
+			if IsUnique(err) {
+				return fmt.Errorf("reviewer already assigned: %w", models.ErrAlreadyExists)
+			}
+			return fmt.Errorf("failed to reassign: %w", err)
+		}
+		if affected, err := res.RowsAffected(); err != nil {
+			return fmt.Errorf("failed to read affected rows: %w", err)
+		} else if affected == 0 {
+			return models.ErrNotAssigned
+		}
+
+		return insertOutbox(ctx, tx, events)
+	})
+}
+
+func (r *PullRequestRepository) AddReviewer(ctx context.Context, prID, userID string, events ...models.Event) error {
+	return withTx(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		var status models.PRStatus
+		err := tx.QueryRowContext(ctx, `SELECT status FROM pull_requests WHERE id = ?`, prID).Scan(&status)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("pr %s: %w", prID, models.ErrNotFound)
+			}
+			return fmt.Errorf("failed to read pr: %w", err)
+		}
+		if err := status.ReviewersEditable(); err != nil {
+			return err
+		}
+
+		query := `INSERT INTO pr_reviewers (pr_id, reviewer_id, assigned_at) VALUES (?, ?, ?)`
+		if _, err := tx.ExecContext(ctx, query, prID, userID, toMicros(time.Now())); err != nil {
+			if IsUnique(err) {
+				return fmt.Errorf("reviewer %s: %w", userID, models.ErrAlreadyAssigned)
+			}
+			if IsForeignKey(err) {
+				return fmt.Errorf("pr %s or user %s: %w", prID, userID, models.ErrNotFound)
+			}
+			return fmt.Errorf("failed to add reviewer: %w", err)
+		}
+
+		return insertOutbox(ctx, tx, events)
+	})
+}
+
+func (r *PullRequestRepository) RemoveReviewer(ctx context.Context, prID, userID string, events ...models.Event) error {
+	return withTx(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		var status models.PRStatus
+		err := tx.QueryRowContext(ctx, `SELECT status FROM pull_requests WHERE id = ?`, prID).Scan(&status)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("pr %s: %w", prID, models.ErrNotFound)
+			}
+			return fmt.Errorf("failed to read pr: %w", err)
+		}
+		if err := status.ReviewersEditable(); err != nil {
+			return err
+		}
+
+		res, err := tx.ExecContext(ctx, `DELETE FROM pr_reviewers WHERE pr_id = ? AND reviewer_id = ?`, prID, userID)
+		if err != nil {
+			return fmt.Errorf("failed to remove reviewer: %w", err)
+		}
+		if affected, err := res.RowsAffected(); err != nil {
+			return fmt.Errorf("failed to read affected rows: %w", err)
+		} else if affected == 0 {
+			return models.ErrNotAssigned
+		}
+
+		return insertOutbox(ctx, tx, events)
+	})
+}
+
+func (r *PullRequestRepository) GetByReviewerID(ctx context.Context, userID string) ([]*models.PullRequestShort, error) {
+	query := `
+        SELECT pr.id, pr.name, pr.author_id, pr.status, prr.assigned_at, prr.from_fallback
+        FROM pull_requests pr
+        INNER JOIN pr_reviewers prr ON pr.id = prr.pr_id
+        WHERE prr.reviewer_id = ?
+          AND pr.status <> 'CLOSED'
+        ORDER BY pr.created_at DESC
+    `
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query prs: %w", err)
+	}
+
+	result, err := collect(rows, func(rows *sql.Rows) (*models.PullRequestShort, error) {
+		var (
+			pr         models.PullRequestShort
+			assignedAt int64
+		)
+		err := rows.Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.Status, &assignedAt, &pr.FromFallback)
+		at := fromMicros(assignedAt)
+		pr.AssignedAt = &at
+		return &pr, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect prs: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *PullRequestRepository) GetByID(ctx context.Context, id string) (*models.PullRequest, error) {
+	query := `
+        SELECT id, name, author_id, status, created_at, merged_at, files, repository_id
+        FROM pull_requests
+        WHERE id = ?
+    `
+	pr, err := scanPullRequest(conn(ctx, r.db).QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get pr: %w", err)
+	}
+
+	if err := loadReviewers(ctx, conn(ctx, r.db), pr); err != nil {
+		return nil, err
+	}
+
+	return pr, nil
+}
+
+// UpdateStatus checks the status inside the transaction; SQLite takes one writer
+// at a time, so a merge can't land between the check and the update.
+func (r *PullRequestRepository) UpdateStatus(
+	ctx context.Context,
+	id string,
+	status models.PRStatus,
+	from []models.PRStatus,
+	addReviewers, fallbackReviewers []string,
+	events ...models.Event,
+) (*models.PullRequest, error) {
+	var pr *models.PullRequest
+	err := withTx(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		var current models.PRStatus
+		err := tx.QueryRowContext(ctx, `SELECT status FROM pull_requests WHERE id = ?`, id).Scan(&current)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return models.ErrNotFound
+			}
+			return fmt.Errorf("failed to get pr status: %w", err)
+		}
+		if err := current.TransitionFrom(from); err != nil {
+			return err
+		}
+
+		query := `
+            UPDATE pull_requests
+            SET status = ?2
+            WHERE id = ?1
+            RETURNING id, name, author_id, status, created_at, merged_at, files, repository_id
+        `
+		pr, err = scanPullRequest(tx.QueryRowContext(ctx, query, id, status))
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return models.ErrNotFound
+			}
+			return fmt.Errorf("failed to update pr status: %w", err)
+		}
+
+		now := toMicros(time.Now())
+		if status == models.PRStatusOpen {
+			// The SLA clock restarts whenever the PR goes back to review.
+			queryRestart := `UPDATE pr_reviewers SET assigned_at = ?, overdue_at = NULL WHERE pr_id = ?`
+			if _, err := tx.ExecContext(ctx, queryRestart, now, id); err != nil {
+				return fmt.Errorf("failed to restart review sla: %w", err)
+			}
+		}
+
+		queryReviewers := `
+            INSERT INTO pr_reviewers (pr_id, reviewer_id, assigned_at, from_fallback)
+            VALUES (?, ?, ?, ?)
+            ON CONFLICT DO NOTHING
+        `
+		for _, reviewerID := range addReviewers {
+			fromFallback := slices.Contains(fallbackReviewers, reviewerID)
+			if _, err := tx.ExecContext(ctx, queryReviewers, id, reviewerID, now, fromFallback); err != nil {
+				return fmt.Errorf("failed to insert reviewer %s: %w", reviewerID, err)
+			}
+		}
+
+		if err := loadReviewers(ctx, tx, pr); err != nil {
+			return err
+		}
+
+		return insertOutbox(ctx, tx, events)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pr, nil
+}
+
+func (r *PullRequestRepository) AddReview(ctx context.Context, review *models.Review) error {
+	return withTx(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		query := `
+            INSERT INTO pr_reviews (pr_id, reviewer_id, verdict, submitted_at)
+            VALUES (?, ?, ?, ?)
+        `
+		_, err := tx.ExecContext(ctx, query, review.PRID, review.ReviewerID, review.Verdict,
+			toMicros(review.SubmittedAt))
+		if err != nil {
+			if IsForeignKey(err) {
+				return fmt.Errorf("pr or reviewer: %w", models.ErrNotFound)
+			}
+			return fmt.Errorf("failed to insert review: %w", err)
+		}
+
+		queryClear := `UPDATE pr_reviewers SET overdue_at = NULL WHERE pr_id = ? AND reviewer_id = ?`
+		if _, err := tx.ExecContext(ctx, queryClear, review.PRID, review.ReviewerID); err != nil {
+			return fmt.Errorf("failed to clear overdue flag: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *PullRequestRepository) GetReviews(ctx context.Context, prID string) ([]models.Review, error) {
+	query := `
+        SELECT pr_id, reviewer_id, verdict, submitted_at
+        FROM pr_reviews
+        WHERE pr_id = ?
+        ORDER BY submitted_at, id
+    `
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, prID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reviews: %w", err)
+	}
+
+	reviews, err := collect(rows, func(rows *sql.Rows) (models.Review, error) {
+		var (
+			rev         models.Review
+			submittedAt int64
+		)
+		err := rows.Scan(&rev.PRID, &rev.ReviewerID, &rev.Verdict, &submittedAt)
+		rev.SubmittedAt = fromMicros(submittedAt)
+		return rev, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect reviews: %w", err)
+	}
+
+	return reviews, nil
+}
+
+// MarkOverdue applies the SLA of the PR's review scope: the team of its registered
+// repository, or the author's team.
+func (r *PullRequestRepository) MarkOverdue(ctx context.Context, now time.Time) ([]models.OverdueReview, error) {
+	var overdue []models.OverdueReview
+	err := withTx(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		query := `
+            SELECT pr.id, pr.name, prr.reviewer_id, pr.author_id, ts.team_name, prr.assigned_at,
+                   prr.assigned_at + ts.review_sla_minutes * 60000000, ?1
+            FROM pr_reviewers prr
+            JOIN pull_requests pr ON pr.id = prr.pr_id
+            JOIN users a ON a.id = pr.author_id
+            LEFT JOIN repositories rp ON rp.id = pr.repository_id
+            JOIN team_settings ts ON ts.team_name = COALESCE(rp.team_name, a.team_name)
+            WHERE pr.status = 'OPEN'
+              AND prr.overdue_at IS NULL
+              AND ts.review_sla_minutes > 0
+              AND prr.assigned_at + ts.review_sla_minutes * 60000000 <= ?1
+              AND NOT EXISTS (
+                  SELECT 1 FROM pr_reviews rv
+                  WHERE rv.pr_id = prr.pr_id
+                    AND rv.reviewer_id = prr.reviewer_id
+                    AND rv.submitted_at >= prr.assigned_at
+              )
+        `
+		rows, err := tx.QueryContext(ctx, query, toMicros(now))
+		if err != nil {
+			return fmt.Errorf("failed to query overdue reviews: %w", err)
+		}
+		overdue, err = collect(rows, scanOverdueReview)
+		if err != nil {
+			return fmt.Errorf("failed to collect overdue reviews: %w", err)
+		}
+
+		queryMark := `UPDATE pr_reviewers SET overdue_at = ? WHERE pr_id = ? AND reviewer_id = ?`
+		for _, o := range overdue {
+			if _, err := tx.ExecContext(ctx, queryMark, toMicros(now), o.PRID, o.ReviewerID); err != nil {
+				return fmt.Errorf("failed to mark overdue review: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return overdue, nil
+}
+
+func (r *PullRequestRepository) GetOverdue(ctx context.Context, teamName string) ([]models.OverdueReview, error) {
+	query := `
+        SELECT pr.id, pr.name, prr.reviewer_id, pr.author_id, COALESCE(rp.team_name, a.team_name), prr.assigned_at,
+               prr.assigned_at + COALESCE(ts.review_sla_minutes, 0) * 60000000, prr.overdue_at
+        FROM pr_reviewers prr
+        JOIN pull_requests pr ON pr.id = prr.pr_id
+        JOIN users a ON a.id = pr.author_id
+        LEFT JOIN repositories rp ON rp.id = pr.repository_id
+        LEFT JOIN team_settings ts ON ts.team_name = COALESCE(rp.team_name, a.team_name)
+        WHERE prr.overdue_at IS NOT NULL
+          AND pr.status = 'OPEN'
+          AND (?1 = '' OR COALESCE(rp.team_name, a.team_name) = ?1)
+        ORDER BY prr.overdue_at, pr.id
+    `
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, teamName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query overdue reviews: %w", err)
+	}
+
+	overdue, err := collect(rows, scanOverdueReview)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect overdue reviews: %w", err)
+	}
+
+	return overdue, nil
+}
+
+func scanPullRequest(row *sql.Row) (*models.PullRequest, error) {
+	var (
+		pr        models.PullRequest
+		createdAt int64
+		mergedAt  sql.NullInt64
+		files     string
+	)
+	err := row.Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.Status, &createdAt, &mergedAt, &files, &pr.RepositoryID)
+	if err != nil {
+		return nil, err
+	}
+	pr.CreatedAt = fromMicros(createdAt)
+	pr.MergedAt = fromNullMicros(mergedAt)
+	if pr.Files, err = fromJSON(files); err != nil {
+		return nil, err
+	}
+	return &pr, nil
+}
+
+func scanOverdueReview(rows *sql.Rows) (models.OverdueReview, error) {
+	var (
+		o                            models.OverdueReview
+		assignedAt, dueAt, overdueAt int64
+	)
+	err := rows.Scan(&o.PRID, &o.PRName, &o.ReviewerID, &o.AuthorID, &o.TeamName, &assignedAt, &dueAt, &overdueAt)
+	o.AssignedAt = fromMicros(assignedAt)
+	o.DueAt = fromMicros(dueAt)
+	o.OverdueAt = fromMicros(overdueAt)
+	return o, err
+}
+
+// sameReviewState reports whether a and b have the same status and reviewers.
+func sameReviewState(a, b *models.PullRequest) bool {
+	if a.Status != b.Status || len(a.Reviewers) != len(b.Reviewers) {
+		return false
+	}
+	for _, reviewerID := range a.Reviewers {
+		if !slices.Contains(b.Reviewers, reviewerID) {
+			return false
+		}
+	}
+	return true
+}
+
+// loadReviewers fills pr.Reviewers and pr.FallbackReviewers.
+func loadReviewers(ctx context.Context, q querier, pr *models.PullRequest) error {
+	rows, err := q.QueryContext(ctx, `SELECT reviewer_id, from_fallback FROM pr_reviewers WHERE pr_id = ?`, pr.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get reviewers: %w", err)
+	}
+
+	type reviewer struct {
+		id           string
+		fromFallback bool
+	}
+	reviewers, err := collect(rows, func(rows *sql.Rows) (reviewer, error) {
+		var rev reviewer
+		err := rows.Scan(&rev.id, &rev.fromFallback)
+		return rev, err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to collect reviewers: %w", err)
+	}
+
+	pr.Reviewers = []string{}
+	pr.FallbackReviewers = nil
+	for _, rev := range reviewers {
+		pr.Reviewers = append(pr.Reviewers, rev.id)
+		if rev.fromFallback {
+			pr.FallbackReviewers = append(pr.FallbackReviewers, rev.id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+)
+
+const selectRepositories = `
+        SELECT r.id, r.team_name, r.reviewers_count, r.min_reviewers, r.required_approvals,
+               (SELECT json_group_array(user_id) FROM (
+                   SELECT user_id FROM repository_reviewers WHERE repository_id = r.id ORDER BY user_id
+               ))
+        FROM repositories r
+    `
+
+type RepositoryRepository struct {
+	db *sql.DB
+}
+
+func NewRepositoryRepository(db *sql.DB) *RepositoryRepository {
+	return &RepositoryRepository{db: db}
+}
+
+func (r *RepositoryRepository) CreateRepository(ctx context.Context, repo *models.Repository) error {
+	return withTx(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		count, minReviewers, approvals := settingsColumns(repo.Settings)
+		query := `
+            INSERT INTO repositories (id, team_name, reviewers_count, min_reviewers, required_approvals, created_at)
+            VALUES (?, ?, ?, ?, ?, ?)
+        `
+		_, err := tx.ExecContext(ctx, query, repo.ID, repo.TeamName, count, minReviewers, approvals, toMicros(time.Now()))
+		if err != nil {
+			if IsUnique(err) {
+				return fmt.Errorf("repository %s: %w", repo.ID, models.ErrAlreadyExists)
+			}
+			if IsForeignKey(err) {
+				return fmt.Errorf("team %s: %w", repo.TeamName, models.ErrNotFound)
+			}
+			return fmt.Errorf("failed to insert repository: %w", err)
+		}
+
+		return insertRepositoryReviewers(ctx, tx, repo)
+	})
+}
+
+func (r *RepositoryRepository) UpdateRepository(ctx context.Context, repo *models.Repository) error {
+	return withTx(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		count, minReviewers, approvals := settingsColumns(repo.Settings)
+		query := `
+            UPDATE repositories
+            SET team_name = ?, reviewers_count = ?, min_reviewers = ?, required_approvals = ?
+            WHERE id = ?
+        `
+		res, err := tx.ExecContext(ctx, query, repo.TeamName, count, minReviewers, approvals, repo.ID)
+		if err != nil {
+			if IsForeignKey(err) {
+				return fmt.Errorf("team %s: %w", repo.TeamName, models.ErrNotFound)
+			}
+			return fmt.Errorf("failed to update repository: %w", err)
+		}
+		if affected, err := res.RowsAffected(); err != nil {
+			return fmt.Errorf("failed to read affected rows: %w", err)
+		} else if affected == 0 {
+			return models.ErrNotFound
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM repository_reviewers WHERE repository_id = ?`, repo.ID); err != nil {
+			return fmt.Errorf("failed to clear repository reviewers: %w", err)
+		}
+		return insertRepositoryReviewers(ctx, tx, repo)
+	})
+}
+
+func (r *RepositoryRepository) GetRepository(ctx context.Context, id string) (*models.Repository, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, selectRepositories+` WHERE r.id = ?`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query repository: %w", err)
+	}
+	repos, err := collect(rows, scanRepository)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository %s: %w", id, err)
+	}
+	if len(repos) == 0 {
+		return nil, models.ErrNotFound
+	}
+	return &repos[0], nil
+}
+
+func (r *RepositoryRepository) ListRepositories(ctx context.Context, teamName string) ([]models.Repository, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, selectRepositories+` WHERE r.team_name = ? ORDER BY r.id`, teamName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query repositories: %w", err)
+	}
+	repos, err := collect(rows, scanRepository)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect repositories: %w", err)
+	}
+	return repos, nil
+}
+
+func insertRepositoryReviewers(ctx context.Context, tx *sql.Tx, repo *models.Repository) error {
+	query := `INSERT INTO repository_reviewers (repository_id, user_id) VALUES (?, ?)`
+	for _, userID := range repo.Reviewers {
+		if _, err := tx.ExecContext(ctx, query, repo.ID, userID); err != nil {
+			if IsForeignKey(err) {
+				return fmt.Errorf("user %s: %w", userID, models.ErrNotFound)
+			}
+			return fmt.Errorf("failed to insert repository reviewer %s: %w", userID, err)
+		}
+	}
+	return nil
+}
+
+// settingsColumns stores nil settings as NULLs, meaning the team settings apply.
+func settingsColumns(settings *models.RepositorySettings) (count, minReviewers, approvals sql.NullInt64) {
+	if settings == nil {
+		return count, minReviewers, approvals
+	}
+	return sql.NullInt64{Int64: int64(settings.ReviewersCount), Valid: true},
+		sql.NullInt64{Int64: int64(settings.MinReviewers), Valid: true},
+		sql.NullInt64{Int64: int64(settings.RequiredApprovals), Valid: true}
+}
+
+func scanRepository(rows *sql.Rows) (models.Repository, error) {
+	var (
+		repo                           models.Repository
+		count, minReviewers, approvals sql.NullInt64
+		reviewers                      string
+	)
+	err := rows.Scan(&repo.ID, &repo.TeamName, &count, &minReviewers, &approvals, &reviewers)
+	if err != nil {
+		return repo, err
+	}
+	if repo.Reviewers, err = fromJSON(reviewers); err != nil {
+		return repo, err
+	}
+	if count.Valid {
+		repo.Settings = &models.RepositorySettings{
+			ReviewersCount:    int(count.Int64),
+			MinReviewers:      int(minReviewers.Int64),
+			RequiredApprovals: int(approvals.Int64),
+		}
+	}
+	return repo, nil
+}
@@ -0,0 +1,43 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+)
+
+type StatsRepository struct {
+	db *sql.DB
+}
+
+func NewStatsRepository(db *sql.DB) *StatsRepository {
+	return &StatsRepository{db: db}
+}
+
+func (r *StatsRepository) GetTopReviewers(ctx context.Context) ([]*models.ReviewerStat, error) {
+	query := `
+        SELECT u.id, u.name, COUNT(prr.pr_id) AS review_count
+        FROM users u
+        LEFT JOIN pr_reviewers prr ON u.id = prr.reviewer_id
+        GROUP BY u.id, u.name
+        ORDER BY review_count DESC
+        LIMIT 10
+    `
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query stats: %w", err)
+	}
+
+	stats, err := collect(rows, func(rows *sql.Rows) (*models.ReviewerStat, error) {
+		var s models.ReviewerStat
+		err := rows.Scan(&s.UserID, &s.Username, &s.ReviewCount)
+		return &s, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect stats: %w", err)
+	}
+
+	return stats, nil
+}
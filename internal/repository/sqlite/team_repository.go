@@ -0,0 +1,139 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+)
+
+type TeamRepository struct {
+	db *sql.DB
+}
+
+func NewTeamRepository(db *sql.DB) *TeamRepository {
+	return &TeamRepository{db: db}
+}
+
+func (r *TeamRepository) CreateTeam(ctx context.Context, team *models.Team) error {
+	return withTx(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO teams (name) VALUES (?)`, team.Name); err != nil {
+			if IsUnique(err) {
+				return fmt.Errorf("team exists: %w", models.ErrAlreadyExists)
+			}
+			return fmt.Errorf("failed to insert team %s: %w", team.Name, err)
+		}
+
+		queryUser := `
+            INSERT INTO users (id, name, team_name, is_active)
+            VALUES (?, ?, ?, ?)
+            ON CONFLICT (id) DO UPDATE
+            SET name = excluded.name,
+                team_name = excluded.team_name,
+                is_active = excluded.is_active
+        `
+		for _, member := range team.Members {
+			if _, err := tx.ExecContext(ctx, queryUser, member.UserID, member.UserName, team.Name, member.IsActive); err != nil {
+				return fmt.Errorf("failed to upsert member %s: %w", member.UserID, err)
+			}
+		}
+		return nil
+	})
+}
+
+func (r *TeamRepository) GetTeam(ctx context.Context, name string) (*models.Team, error) {
+	var found bool
+	query := `SELECT EXISTS(SELECT 1 FROM teams WHERE name = ?)`
+	if err := conn(ctx, r.db).QueryRowContext(ctx, query, name).Scan(&found); err != nil {
+		return nil, fmt.Errorf("failed to check team existence: %w", err)
+	}
+
+	if !found {
+		return nil, models.ErrNotFound
+	}
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `SELECT id, name, is_active FROM users WHERE team_name = ? ORDER BY id`, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query members for team %s: %w", name, err)
+	}
+	members, err := collect(rows, func(rows *sql.Rows) (models.TeamMember, error) {
+		var m models.TeamMember
+		err := rows.Scan(&m.UserID, &m.UserName, &m.IsActive)
+		return m, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect members: %w", err)
+	}
+
+	return &models.Team{
+		Name:    name,
+		Members: members,
+	}, nil
+}
+
+func (r *TeamRepository) GetSettings(ctx context.Context, teamName string) (*models.TeamSettings, error) {
+	query := `
+        SELECT ts.reviewers_count, ts.min_reviewers, ts.required_approvals, ts.chat_webhook_url,
+               ts.review_sla_minutes, ts.sla_auto_reassign, ts.fallback_teams
+        FROM teams t
+        LEFT JOIN team_settings ts ON ts.team_name = t.name
+        WHERE t.name = ?
+    `
+	var (
+		reviewersCount, minReviewers, requiredApprovals, reviewSLAMinutes sql.NullInt64
+		chatWebhookURL, fallbackTeams                                     sql.NullString
+		slaAutoReassign                                                   sql.NullBool
+	)
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, teamName).Scan(&reviewersCount, &minReviewers,
+		&requiredApprovals, &chatWebhookURL, &reviewSLAMinutes, &slaAutoReassign, &fallbackTeams)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get settings for team %s: %w", teamName, err)
+	}
+
+	settings := models.DefaultTeamSettings()
+	if reviewersCount.Valid {
+		settings.ReviewersCount = int(reviewersCount.Int64)
+		settings.MinReviewers = int(minReviewers.Int64)
+		settings.RequiredApprovals = int(requiredApprovals.Int64)
+		settings.ChatWebhookURL = chatWebhookURL.String
+		settings.ReviewSLAMinutes = int(reviewSLAMinutes.Int64)
+		settings.SLAAutoReassign = slaAutoReassign.Bool
+		if settings.FallbackTeams, err = fromJSON(fallbackTeams.String); err != nil {
+			return nil, err
+		}
+	}
+
+	return settings, nil
+}
+
+func (r *TeamRepository) UpsertSettings(ctx context.Context, teamName string, settings *models.TeamSettings) error {
+	query := `
+        INSERT INTO team_settings (team_name, reviewers_count, min_reviewers, required_approvals,
+                                   chat_webhook_url, review_sla_minutes, sla_auto_reassign, fallback_teams)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+        ON CONFLICT (team_name) DO UPDATE
+        SET reviewers_count = excluded.reviewers_count,
+            min_reviewers = excluded.min_reviewers,
+            required_approvals = excluded.required_approvals,
+            chat_webhook_url = excluded.chat_webhook_url,
+            review_sla_minutes = excluded.review_sla_minutes,
+            sla_auto_reassign = excluded.sla_auto_reassign,
+            fallback_teams = excluded.fallback_teams
+    `
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, teamName, settings.ReviewersCount, settings.MinReviewers,
+		settings.RequiredApprovals, settings.ChatWebhookURL, settings.ReviewSLAMinutes, settings.SLAAutoReassign,
+		toJSON(settings.FallbackTeams))
+	if err != nil {
+		if IsForeignKey(err) {
+			return fmt.Errorf("team %s: %w", teamName, models.ErrNotFound)
+		}
+		return fmt.Errorf("failed to upsert settings for team %s: %w", teamName, err)
+	}
+
+	return nil
+}
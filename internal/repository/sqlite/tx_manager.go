@@ -0,0 +1,25 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+)
+
+// TxManager keeps the transaction in the context, so every repository called with
+// that context runs its queries in it. The pool has a single connection, so fn
+// must not query the database with a context that does not carry it.
+type TxManager struct {
+	db *sql.DB
+}
+
+func NewTxManager(db *sql.DB) *TxManager {
+	return &TxManager{db: db}
+}
+
+// WithinTx commits if fn succeeds and rolls back otherwise. Nested calls run in a
+// savepoint of the outer transaction.
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return withTx(ctx, m.db, func(ctx context.Context, _ *sql.Tx) error {
+		return fn(ctx)
+	})
+}
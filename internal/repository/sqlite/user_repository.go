@@ -0,0 +1,472 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/repository"
+)
+
+type UserRepository struct {
+	db *sql.DB
+}
+
+func NewUserRepository(db *sql.DB) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+func (r *UserRepository) GetUser(ctx context.Context, id string) (*models.User, error) {
+	query := `SELECT id, name, team_name, is_active FROM users WHERE id = ?`
+	var u models.User
+	if err := conn(ctx, r.db).QueryRowContext(ctx, query, id).Scan(&u.ID, &u.Name, &u.TeamName, &u.IsActive); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("user not found: %w", models.ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return &u, nil
+}
+
+func (r *UserRepository) SetUserIsActive(ctx context.Context, id string, isActive bool) (*models.User, error) {
+	query := `
+        UPDATE users
+        SET is_active = ?2
+        WHERE id = ?1
+        RETURNING id, name, team_name, is_active
+    `
+	var u models.User
+	if err := conn(ctx, r.db).QueryRowContext(ctx, query, id, isActive).Scan(&u.ID, &u.Name, &u.TeamName, &u.IsActive); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+
+	return &u, nil
+}
+
+func (r *UserRepository) GetActiveUsersByTeam(
+	ctx context.Context,
+	teamName string,
+	from, to time.Time,
+) ([]models.User, error) {
+	query := `
+        SELECT id, name, team_name, is_active
+        FROM users u
+        WHERE team_name = ?1 AND is_active = 1
+          AND NOT EXISTS (
+              SELECT 1 FROM absences a
+              WHERE a.user_id = u.id AND a.starts_at < ?3 AND a.ends_at > ?2
+          )
+        ORDER BY id
+    `
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, teamName, toMicros(from), toMicros(to))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query active users for team %s: %w", teamName, err)
+	}
+
+	users, err := collect(rows, scanUser)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect active users: %w", err)
+	}
+
+	return users, nil
+}
+
+func (r *UserRepository) GetOpenReviewCounts(ctx context.Context, teamName string) (map[string]int, error) {
+	query := `
+        SELECT u.id, COUNT(pr.id)
+        FROM users u
+        LEFT JOIN pr_reviewers prr ON prr.reviewer_id = u.id
+        LEFT JOIN pull_requests pr ON pr.id = prr.pr_id AND pr.status = 'OPEN'
+        WHERE u.team_name = ?
+        GROUP BY u.id
+    `
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, teamName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query review counts for team %s: %w", teamName, err)
+	}
+
+	type reviewCount struct {
+		id    string
+		count int
+	}
+	items, err := collect(rows, func(rows *sql.Rows) (reviewCount, error) {
+		var item reviewCount
+		err := rows.Scan(&item.id, &item.count)
+		return item, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect review counts: %w", err)
+	}
+
+	counts := make(map[string]int, len(items))
+	for _, item := range items {
+		counts[item.id] = item.count
+	}
+	return counts, nil
+}
+
+func (r *UserRepository) LinkIdentity(ctx context.Context, identity *models.UserIdentity) error {
+	query := `
+        INSERT INTO user_identities (provider, login, user_id)
+        VALUES (?, ?, ?)
+        ON CONFLICT (provider, login) DO UPDATE
+        SET user_id = excluded.user_id
+    `
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, identity.Provider, identity.Login, identity.UserID); err != nil {
+		if IsForeignKey(err) {
+			return fmt.Errorf("user %s: %w", identity.UserID, models.ErrNotFound)
+		}
+		return fmt.Errorf("failed to link identity: %w", err)
+	}
+	return nil
+}
+
+func (r *UserRepository) GetUserByIdentity(ctx context.Context, provider, login string) (*models.User, error) {
+	query := `
+        SELECT u.id, u.name, u.team_name, u.is_active
+        FROM users u
+        JOIN user_identities ui ON ui.user_id = u.id
+        WHERE ui.provider = ? AND ui.login = ?
+    `
+	var u models.User
+	if err := conn(ctx, r.db).QueryRowContext(ctx, query, provider, login).Scan(&u.ID, &u.Name, &u.TeamName, &u.IsActive); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("identity %s/%s: %w", provider, login, models.ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get user by identity: %w", err)
+	}
+	return &u, nil
+}
+
+func (r *UserRepository) SetChatHandle(ctx context.Context, userID, handle string) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `UPDATE users SET chat_handle = ? WHERE id = ?`, handle, userID)
+	if err != nil {
+		return fmt.Errorf("failed to set chat handle: %w", err)
+	}
+	return requireAffected(res)
+}
+
+func (r *UserRepository) GetChatHandles(ctx context.Context, userIDs []string) (map[string]string, error) {
+	query := `
+        SELECT id, chat_handle
+        FROM users
+        WHERE id IN (SELECT value FROM json_each(?)) AND chat_handle <> ''
+    `
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, toJSON(userIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query chat handles: %w", err)
+	}
+
+	pairs, err := collect(rows, func(rows *sql.Rows) ([2]string, error) {
+		var pair [2]string
+		err := rows.Scan(&pair[0], &pair[1])
+		return pair, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chat handles: %w", err)
+	}
+
+	handles := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		handles[pair[0]] = pair[1]
+	}
+	return handles, nil
+}
+
+func (r *UserRepository) SetQuietHours(ctx context.Context, userID string, quiet models.QuietHours) error {
+	query := `
+        UPDATE users
+        SET quiet_hours_from = ?, quiet_hours_to = ?, timezone = ?
+        WHERE id = ?
+    `
+	res, err := conn(ctx, r.db).ExecContext(ctx, query, quiet.From, quiet.To, quiet.Timezone, userID)
+	if err != nil {
+		return fmt.Errorf("failed to set quiet hours: %w", err)
+	}
+	return requireAffected(res)
+}
+
+func (r *UserRepository) GetDigestRecipients(ctx context.Context) ([]models.DigestRecipient, error) {
+	query := `
+        SELECT id, team_name, quiet_hours_from, quiet_hours_to, timezone, last_digest_at
+        FROM users
+        WHERE is_active = 1
+        ORDER BY id
+    `
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query digest recipients: %w", err)
+	}
+
+	recipients, err := collect(rows, func(rows *sql.Rows) (models.DigestRecipient, error) {
+		var (
+			d            models.DigestRecipient
+			lastDigestAt sql.NullInt64
+		)
+		err := rows.Scan(&d.UserID, &d.TeamName, &d.QuietHours.From, &d.QuietHours.To,
+			&d.QuietHours.Timezone, &lastDigestAt)
+		d.LastDigestAt = fromNullMicros(lastDigestAt)
+		return d, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect digest recipients: %w", err)
+	}
+
+	return recipients, nil
+}
+
+func (r *UserRepository) RecordDigest(ctx context.Context, userID string, at time.Time, event models.Event) error {
+	return withTx(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `UPDATE users SET last_digest_at = ? WHERE id = ?`, toMicros(at), userID); err != nil {
+			return fmt.Errorf("failed to record digest: %w", err)
+		}
+		return insertOutbox(ctx, tx, []models.Event{event})
+	})
+}
+
+func (r *UserRepository) CreateAbsence(ctx context.Context, absence *models.Absence) error {
+	query := `
+        INSERT INTO absences (user_id, starts_at, ends_at, reason, created_at)
+        VALUES (?, ?, ?, ?, ?)
+        RETURNING id, created_at
+    `
+	var createdAt int64
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, absence.UserID, toMicros(absence.StartsAt),
+		toMicros(absence.EndsAt), absence.Reason, toMicros(time.Now())).Scan(&absence.ID, &createdAt)
+	if err != nil {
+		if IsForeignKey(err) {
+			return models.ErrNotFound
+		}
+		return fmt.Errorf("failed to create absence: %w", err)
+	}
+	absence.CreatedAt = fromMicros(createdAt)
+	return nil
+}
+
+func (r *UserRepository) ListAbsences(ctx context.Context, userID string) ([]models.Absence, error) {
+	query := `
+        SELECT id, user_id, starts_at, ends_at, reason, COALESCE(external_uid, ''), created_at
+        FROM absences
+        WHERE user_id = ?
+        ORDER BY starts_at, id
+    `
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query absences: %w", err)
+	}
+
+	absences, err := collect(rows, scanAbsence)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect absences: %w", err)
+	}
+
+	return absences, nil
+}
+
+func (r *UserRepository) DeleteAbsence(ctx context.Context, id int64) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM absences WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete absence: %w", err)
+	}
+	return requireAffected(res)
+}
+
+// UpsertAbsence keeps handled_at only if the absence still starts at the same time
+// for the same user, so a moved absence hands reviews over again.
+func (r *UserRepository) UpsertAbsence(ctx context.Context, absence *models.Absence) (bool, error) {
+	var created bool
+	err := withTx(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		var existing bool
+		query := `SELECT EXISTS(SELECT 1 FROM absences WHERE external_uid = ?)`
+		if err := tx.QueryRowContext(ctx, query, absence.UID).Scan(&existing); err != nil {
+			return fmt.Errorf("failed to check absence: %w", err)
+		}
+		created = !existing
+
+		query = `
+            INSERT INTO absences (user_id, starts_at, ends_at, reason, external_uid, created_at)
+            VALUES (?, ?, ?, ?, ?, ?)
+            ON CONFLICT (external_uid) DO UPDATE
+            SET user_id = excluded.user_id,
+                starts_at = excluded.starts_at,
+                ends_at = excluded.ends_at,
+                reason = excluded.reason,
+                handled_at = CASE
+                    WHEN absences.user_id = excluded.user_id AND absences.starts_at = excluded.starts_at
+                    THEN absences.handled_at
+                END
+            RETURNING id, created_at
+        `
+		var createdAt int64
+		err := tx.QueryRowContext(ctx, query, absence.UserID, toMicros(absence.StartsAt), toMicros(absence.EndsAt),
+			absence.Reason, absence.UID, toMicros(time.Now())).Scan(&absence.ID, &createdAt)
+		if err != nil {
+			if IsForeignKey(err) {
+				return models.ErrNotFound
+			}
+			return fmt.Errorf("failed to upsert absence: %w", err)
+		}
+		absence.CreatedAt = fromMicros(createdAt)
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return created, nil
+}
+
+func (r *UserRepository) DeleteAbsenceByUID(ctx context.Context, uid string) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM absences WHERE external_uid = ?`, uid)
+	if err != nil {
+		return fmt.Errorf("failed to delete absence: %w", err)
+	}
+	return requireAffected(res)
+}
+
+func (r *UserRepository) GetStartedAbsences(ctx context.Context, now time.Time) ([]models.Absence, error) {
+	query := `
+        SELECT id, user_id, starts_at, ends_at, reason, COALESCE(external_uid, ''), created_at
+        FROM absences
+        WHERE handled_at IS NULL AND starts_at <= ?1 AND ends_at > ?1
+        ORDER BY starts_at, id
+    `
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, toMicros(now))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query started absences: %w", err)
+	}
+
+	absences, err := collect(rows, scanAbsence)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect started absences: %w", err)
+	}
+
+	return absences, nil
+}
+
+func (r *UserRepository) MarkAbsenceHandled(ctx context.Context, id int64, at time.Time) error {
+	if _, err := conn(ctx, r.db).ExecContext(ctx, `UPDATE absences SET handled_at = ? WHERE id = ?`, toMicros(at), id); err != nil {
+		return fmt.Errorf("failed to mark absence handled: %w", err)
+	}
+	return nil
+}
+
+// DeactivateUsers passes pick a context carrying the transaction, so the selector
+// can read review counts over the only connection.
+func (r *UserRepository) DeactivateUsers(
+	ctx context.Context,
+	userIDs []string,
+	pick repository.ReviewerPicker,
+	events ...models.Event,
+) error {
+	return withTx(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		ids := toJSON(userIDs)
+		queryDeactivate := `UPDATE users SET is_active = 0 WHERE id IN (SELECT value FROM json_each(?))`
+		if _, err := tx.ExecContext(ctx, queryDeactivate, ids); err != nil {
+			return fmt.Errorf("failed to deactivate users: %w", err)
+		}
+
+		query := `
+            SELECT prr.pr_id, prr.reviewer_id, pr.author_id, u.team_name, pr.repository_id
+            FROM pr_reviewers prr
+            JOIN pull_requests pr ON prr.pr_id = pr.id
+            JOIN users u ON pr.author_id = u.id
+            WHERE prr.reviewer_id IN (SELECT value FROM json_each(?))
+              AND pr.status = 'OPEN'
+            ORDER BY pr.created_at DESC, prr.pr_id, prr.reviewer_id
+        `
+		rows, err := tx.QueryContext(ctx, query, ids)
+		if err != nil {
+			return fmt.Errorf("failed to select reviews to update: %w", err)
+		}
+		reviews, err := collect(rows, func(rows *sql.Rows) (models.ReviewToUpdate, error) {
+			var item models.ReviewToUpdate
+			err := rows.Scan(&item.PRID, &item.OldReviewerID, &item.AuthorID, &item.TeamName, &item.RepositoryID)
+			return item, err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to collect reviews to update: %w", err)
+		}
+
+		queryDeleteReviewer := `DELETE FROM pr_reviewers WHERE pr_id = ? AND reviewer_id = ?`
+
+		queryUpdateReviewer := `
+            UPDATE pr_reviewers SET reviewer_id = ?, from_fallback = ?, assigned_at = ?, overdue_at = NULL
+            WHERE pr_id = ? AND reviewer_id = ?
+        `
+
+		now := toMicros(time.Now())
+		for _, rev := range reviews {
+			rows, err := tx.QueryContext(ctx, `SELECT reviewer_id FROM pr_reviewers WHERE pr_id = ?`, rev.PRID)
+			if err != nil {
+				return fmt.Errorf("failed to query reviewers: %w", err)
+			}
+			rev.Reviewers, err = collect(rows, func(rows *sql.Rows) (string, error) {
+				var id string
+				err := rows.Scan(&id)
+				return id, err
+			})
+			if err != nil {
+				return fmt.Errorf("failed to collect reviewers: %w", err)
+			}
+
+			newReviewerID, fromFallback, err := pick(ctx, rev)
+			if err != nil {
+				return fmt.Errorf("failed to pick candidate: %w", err)
+			}
+
+			if newReviewerID == "" {
+				if _, err := tx.ExecContext(ctx, queryDeleteReviewer, rev.PRID, rev.OldReviewerID); err != nil {
+					return fmt.Errorf("failed to delete reviewer: %w", err)
+				}
+				continue
+			}
+
+			_, err = tx.ExecContext(ctx, queryUpdateReviewer, newReviewerID, fromFallback, now, rev.PRID, rev.OldReviewerID)
+			if err != nil {
+				return fmt.Errorf("failed to update reviewer: %w", err)
+			}
+			events = append(events, models.NewEvent(models.EventReviewerReassigned,
+				rev.ReassignedPayload(newReviewerID, fromFallback)))
+		}
+
+		return insertOutbox(ctx, tx, events)
+	})
+}
+
+func scanUser(rows *sql.Rows) (models.User, error) {
+	var u models.User
+	err := rows.Scan(&u.ID, &u.Name, &u.TeamName, &u.IsActive)
+	return u, err
+}
+
+func scanAbsence(rows *sql.Rows) (models.Absence, error) {
+	var (
+		a                           models.Absence
+		startsAt, endsAt, createdAt int64
+	)
+	if err := rows.Scan(&a.ID, &a.UserID, &startsAt, &endsAt, &a.Reason, &a.UID, &createdAt); err != nil {
+		return a, err
+	}
+	a.StartsAt = fromMicros(startsAt)
+	a.EndsAt = fromMicros(endsAt)
+	a.CreatedAt = fromMicros(createdAt)
+	return a, nil
+}
+
+// requireAffected maps an update or delete that matched no rows to ErrNotFound.
+func requireAffected(res sql.Result) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to read affected rows: %w", err)
+	}
+	if affected == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type WebhookDeliveryRepository struct {
+	db *sql.DB
+}
+
+func NewWebhookDeliveryRepository(db *sql.DB) *WebhookDeliveryRepository {
+	return &WebhookDeliveryRepository{db: db}
+}
+
+func (r *WebhookDeliveryRepository) MarkReceived(ctx context.Context, provider, deliveryID string) (bool, error) {
+	query := `
+        INSERT INTO webhook_deliveries (provider, delivery_id, received_at)
+        VALUES (?, ?, ?)
+        ON CONFLICT DO NOTHING
+    `
+	res, err := conn(ctx, r.db).ExecContext(ctx, query, provider, deliveryID, toMicros(time.Now()))
+	if err != nil {
+		return false, fmt.Errorf("failed to record delivery: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to read affected rows: %w", err)
+	}
+	return affected == 1, nil
+}
+
+func (r *WebhookDeliveryRepository) Forget(ctx context.Context, provider, deliveryID string) error {
+	query := `DELETE FROM webhook_deliveries WHERE provider = ? AND delivery_id = ?`
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, provider, deliveryID); err != nil {
+		return fmt.Errorf("failed to delete delivery: %w", err)
+	}
+	return nil
+}
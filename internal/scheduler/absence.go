@@ -0,0 +1,106 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/repository"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/services"
+)
+
+const absenceLockName = "absence_reassign"
+
+// AbsenceJob hands the OPEN reviews of users whose absence has started over to
+// other team members. Reviews without a free candidate stay where they are. An
+// absence whose reviews failed to be handed over is retried on the next run.
+type AbsenceJob struct {
+	locker    repository.LockerInterface
+	txm       repository.TxManager
+	userRepo  repository.UserRepositoryInterface
+	prRepo    repository.PullRequestRepositoryInterface
+	prService services.PullRequestServiceInterface
+	log       *slog.Logger
+}
+
+func NewAbsenceJob(
+	locker repository.LockerInterface,
+	txm repository.TxManager,
+	userRepo repository.UserRepositoryInterface,
+	prRepo repository.PullRequestRepositoryInterface,
+	prService services.PullRequestServiceInterface,
+	log *slog.Logger,
+) *AbsenceJob {
+	return &AbsenceJob{
+		locker:    locker,
+		txm:       txm,
+		userRepo:  userRepo,
+		prRepo:    prRepo,
+		prService: prService,
+		log:       log,
+	}
+}
+
+func (j *AbsenceJob) Run(ctx context.Context, now time.Time) error {
+	_, err := j.locker.TryWithLock(ctx, absenceLockName, func(ctx context.Context) error {
+		return j.reassign(ctx, now)
+	})
+	return err
+}
+
+func (j *AbsenceJob) reassign(ctx context.Context, now time.Time) error {
+	absences, err := j.userRepo.GetStartedAbsences(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	// The hand-overs of one absence and its handled mark are stored together, so
+	// an absence is never marked handled without them.
+	for _, a := range absences {
+		err := j.txm.WithinTx(ctx, func(ctx context.Context) error {
+			if !j.handOver(ctx, a) {
+				return nil
+			}
+			return j.userRepo.MarkAbsenceHandled(ctx, a.ID, now)
+		})
+		if err != nil {
+			j.log.ErrorContext(ctx, "failed to hand over absence", "absence_id", a.ID, "err", err)
+		}
+	}
+
+	return nil
+}
+
+// handOver reassigns the OPEN reviews of the absent user and reports whether every
+// one of them was handed over or had no candidate. Failures are logged and do not
+// stop the other reviews.
+func (j *AbsenceJob) handOver(ctx context.Context, a models.Absence) bool {
+	prs, err := j.prRepo.GetByReviewerID(ctx, a.UserID)
+	if err != nil {
+		j.log.ErrorContext(ctx, "failed to get reviews of absent user", "user_id", a.UserID, "err", err)
+		return false
+	}
+
+	done := true
+	for _, pr := range prs {
+		if pr.Status != models.PRStatusOpen {
+			continue
+		}
+		_, newReviewerID, err := j.prService.ReassignReviewer(ctx, pr.ID, a.UserID, "")
+		if err != nil {
+			if errors.Is(err, models.ErrNoCandidates) {
+				j.log.WarnContext(ctx, "no candidates to take over review", "pr_id", pr.ID, "user_id", a.UserID)
+			} else {
+				j.log.ErrorContext(ctx, "failed to hand over review", "pr_id", pr.ID,
+					"user_id", a.UserID, "err", err)
+				done = false
+			}
+			continue
+		}
+		j.log.InfoContext(ctx, "review handed over for absence", "pr_id", pr.ID,
+			"old_reviewer", a.UserID, "new_reviewer", newReviewerID)
+	}
+	return done
+}
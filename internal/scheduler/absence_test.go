@@ -0,0 +1,140 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/repository/inmemory"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/services"
+)
+
+// failingPRRepo fails the reassignment of failPR and the review lookup of
+// failReviewer, like a broken connection would.
+type failingPRRepo struct {
+	*inmemory.TestPRRepo
+	failPR       string
+	failReviewer string
+}
+
+func (r *failingPRRepo) ReassignReviewer(
+	ctx context.Context,
+	snapshot *models.PullRequest,
+	oldID, newID string,
+	fromFallback bool,
+	events ...models.Event,
+) error {
+	if snapshot.ID == r.failPR {
+		return errors.New("connection reset")
+	}
+	return r.TestPRRepo.ReassignReviewer(ctx, snapshot, oldID, newID, fromFallback, events...)
+}
+
+func (r *failingPRRepo) GetByReviewerID(ctx context.Context, userID string) ([]*models.PullRequestShort, error) {
+	if userID == r.failReviewer {
+		return nil, errors.New("connection reset")
+	}
+	return r.TestPRRepo.GetByReviewerID(ctx, userID)
+}
+
+func TestAbsenceJob_Simple(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+	now := time.Now()
+
+	userRepo := inmemory.NewTestUserRepo()
+	userRepo.Users["u1"] = &models.User{ID: "u1", TeamName: "backend", IsActive: true}
+	userRepo.Users["u2"] = &models.User{ID: "u2", TeamName: "backend", IsActive: true}
+	userRepo.Users["u3"] = &models.User{ID: "u3", TeamName: "backend", IsActive: true}
+	userRepo.Users["u4"] = &models.User{ID: "u4", TeamName: "backend", IsActive: true}
+
+	teamRepo := inmemory.NewTestTeamRepo()
+	teamRepo.Teams["backend"] = &models.Team{Name: "backend"}
+	teamRepo.Settings["backend"] = &models.TeamSettings{ReviewersCount: 1}
+
+	prRepo := inmemory.NewTestPRRepo()
+	prRepo.Prs["pr-1"] = &models.PullRequest{ID: "pr-1", AuthorID: "u1", Status: models.PRStatusOpen, Reviewers: []string{"u2"}}
+
+	// u2 leaves now, u4 leaves tomorrow: only u3 can take the review.
+	_ = userRepo.CreateAbsence(ctx, &models.Absence{UserID: "u2", StartsAt: now.Add(-time.Minute), EndsAt: now.Add(72 * time.Hour)})
+	_ = userRepo.CreateAbsence(ctx, &models.Absence{UserID: "u4", StartsAt: now.Add(12 * time.Hour), EndsAt: now.Add(72 * time.Hour)})
+
+	txm := inmemory.NewTxManager(inmemory.NewStore())
+	prService := services.NewPullRequestService(
+		prRepo, userRepo, teamRepo, inmemory.NewTestRepositoryRepo(), inmemory.NewTestCodeOwnerRepo(),
+		txm, services.NewRandomSelector(), logger,
+	)
+	job := NewAbsenceJob(inmemory.NewLocker(), txm, userRepo, prRepo, prService, logger)
+
+	if err := job.Run(ctx, now); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	t.Run("Reviews are handed over when absence starts", func(t *testing.T) {
+		if got := prRepo.Prs["pr-1"].Reviewers; len(got) != 1 || got[0] != "u3" {
+			t.Errorf("Expected u3 to take over the review, got %v", got)
+		}
+		if _, ok := userRepo.Handled[1]; !ok {
+			t.Error("Started absence must be marked handled")
+		}
+		if _, ok := userRepo.Handled[2]; ok {
+			t.Error("Future absence must not be handled yet")
+		}
+	})
+
+	t.Run("Absent users are not picked for new PRs", func(t *testing.T) {
+		pr, err := prService.CreatePullRequest(ctx, "pr-2", "Feature", "u3", "", false, nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(pr.Reviewers) != 1 || pr.Reviewers[0] != "u1" {
+			t.Errorf("Expected u1 as the only available reviewer, got %v", pr.Reviewers)
+		}
+	})
+
+	t.Run("Failures are skipped and the absence retried", func(t *testing.T) {
+		later := now.Add(13 * time.Hour)
+		failing := &failingPRRepo{TestPRRepo: inmemory.NewTestPRRepo(), failPR: "pr-a", failReviewer: "u1"}
+		failing.Prs["pr-a"] = &models.PullRequest{ID: "pr-a", AuthorID: "u1", Status: models.PRStatusOpen, Reviewers: []string{"u4"}}
+		failing.Prs["pr-b"] = &models.PullRequest{ID: "pr-b", AuthorID: "u1", Status: models.PRStatusOpen, Reviewers: []string{"u4"}}
+		authorAbsence := &models.Absence{UserID: "u1", StartsAt: now.Add(12 * time.Hour), EndsAt: now.Add(72 * time.Hour)}
+		_ = userRepo.CreateAbsence(ctx, authorAbsence)
+
+		prService := services.NewPullRequestService(
+			failing, userRepo, teamRepo, inmemory.NewTestRepositoryRepo(), inmemory.NewTestCodeOwnerRepo(),
+			txm, services.NewRandomSelector(), logger,
+		)
+		job := NewAbsenceJob(inmemory.NewLocker(), txm, userRepo, failing, prService, logger)
+
+		if err := job.Run(ctx, later); err != nil {
+			t.Fatalf("Expected failures to be skipped, got %v", err)
+		}
+		if got := failing.Prs["pr-b"].Reviewers; len(got) != 1 || got[0] != "u3" {
+			t.Errorf("Expected u3 to take over pr-b, got %v", got)
+		}
+		if _, ok := userRepo.Handled[2]; ok {
+			t.Error("Absence with a failed hand-over must not be handled")
+		}
+		if _, ok := userRepo.Handled[authorAbsence.ID]; ok {
+			t.Error("Absence whose reviews failed to load must not be handled")
+		}
+
+		failing.failPR, failing.failReviewer = "", ""
+		if err := job.Run(ctx, later); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got := failing.Prs["pr-a"].Reviewers; len(got) != 1 || got[0] != "u3" {
+			t.Errorf("Expected u3 to take over pr-a on retry, got %v", got)
+		}
+		if _, ok := userRepo.Handled[2]; !ok {
+			t.Error("Absence must be handled once every review is handed over")
+		}
+		if _, ok := userRepo.Handled[authorAbsence.ID]; !ok {
+			t.Error("Absence of the author must be handled on retry")
+		}
+	})
+}
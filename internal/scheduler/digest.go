@@ -0,0 +1,107 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/repository"
+)
+
+const digestLockName = "review_digest"
+
+// DigestJob sends every active reviewer at most one digest per local day with
+// the OPEN pull requests still waiting for them. Users inside their quiet hours
+// are retried on the next run. The job runs under a shared lock so several
+// replicas never send the same digest twice.
+type DigestJob struct {
+	locker   repository.LockerInterface
+	userRepo repository.UserRepositoryInterface
+	prRepo   repository.PullRequestRepositoryInterface
+	log      *slog.Logger
+}
+
+func NewDigestJob(
+	locker repository.LockerInterface,
+	userRepo repository.UserRepositoryInterface,
+	prRepo repository.PullRequestRepositoryInterface,
+	log *slog.Logger,
+) *DigestJob {
+	return &DigestJob{
+		locker:   locker,
+		userRepo: userRepo,
+		prRepo:   prRepo,
+		log:      log,
+	}
+}
+
+func (j *DigestJob) Run(ctx context.Context, now time.Time) error {
+	acquired, err := j.locker.TryWithLock(ctx, digestLockName, func(ctx context.Context) error {
+		return j.send(ctx, now)
+	})
+	if err != nil {
+		return err
+	}
+	if !acquired {
+		j.log.DebugContext(ctx, "digest is being sent by another replica")
+	}
+	return nil
+}
+
+func (j *DigestJob) send(ctx context.Context, now time.Time) error {
+	recipients, err := j.userRepo.GetDigestRecipients(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range recipients {
+		if !digestDue(r, now) || r.QuietHours.Contains(now) {
+			continue
+		}
+
+		prs, err := j.prRepo.GetByReviewerID(ctx, r.UserID)
+		if err != nil {
+			return err
+		}
+
+		var pending []models.PendingReview
+		for _, pr := range prs {
+			if pr.Status != models.PRStatusOpen {
+				continue
+			}
+			item := models.PendingReview{PRID: pr.ID, PRName: pr.Name, AuthorID: pr.AuthorID}
+			if pr.AssignedAt != nil {
+				item.AssignedAt = *pr.AssignedAt
+				item.WaitingMinutes = int(now.Sub(*pr.AssignedAt).Minutes())
+			}
+			pending = append(pending, item)
+		}
+		if len(pending) == 0 {
+			continue
+		}
+
+		event := models.NewEvent(models.EventReviewDigest, models.EventPayload{
+			ReviewerID: r.UserID,
+			Pending:    pending,
+		})
+		if err := j.userRepo.RecordDigest(ctx, r.UserID, now, event); err != nil {
+			return err
+		}
+		j.log.InfoContext(ctx, "review digest queued", "user_id", r.UserID, "pending", len(pending))
+	}
+
+	return nil
+}
+
+// digestDue reports whether the recipient has not had a digest yet today in
+// their own timezone.
+func digestDue(r models.DigestRecipient, now time.Time) bool {
+	if r.LastDigestAt == nil {
+		return true
+	}
+	loc := r.QuietHours.Location()
+	ly, lm, ld := r.LastDigestAt.In(loc).Date()
+	ny, nm, nd := now.In(loc).Date()
+	return time.Date(ly, lm, ld, 0, 0, 0, 0, time.UTC).Before(time.Date(ny, nm, nd, 0, 0, 0, 0, time.UTC))
+}
@@ -0,0 +1,85 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/repository/inmemory"
+)
+
+func TestDigestJob_Simple(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+	now := time.Date(2025, 1, 2, 23, 30, 0, 0, time.UTC)
+
+	userRepo := inmemory.NewTestUserRepo()
+	userRepo.Users["u1"] = &models.User{ID: "u1", TeamName: "backend", IsActive: true}
+	userRepo.Users["u2"] = &models.User{ID: "u2", TeamName: "backend", IsActive: true}
+	userRepo.Users["u3"] = &models.User{ID: "u3", TeamName: "backend", IsActive: true}
+	userRepo.Users["u4"] = &models.User{ID: "u4", TeamName: "backend", IsActive: true}
+	userRepo.QuietHours["u3"] = models.QuietHours{From: "22:00", To: "07:00", Timezone: "UTC"}
+
+	prRepo := inmemory.NewTestPRRepo()
+	prRepo.Prs["pr-1"] = &models.PullRequest{
+		ID: "pr-1", Name: "Fix bug", AuthorID: "u1", Status: models.PRStatusOpen,
+		Reviewers: []string{"u2", "u3"}, CreatedAt: now.Add(-90 * time.Minute),
+	}
+	prRepo.Prs["pr-2"] = &models.PullRequest{
+		ID: "pr-2", AuthorID: "u1", Status: models.PRStatusMerged, Reviewers: []string{"u4"},
+	}
+
+	locker := inmemory.NewLocker()
+	job := NewDigestJob(locker, userRepo, prRepo, logger)
+
+	if err := job.Run(ctx, now); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	t.Run("Pending reviews are sent", func(t *testing.T) {
+		if len(userRepo.Events) != 1 {
+			t.Fatalf("Expected 1 digest, got %d", len(userRepo.Events))
+		}
+		event := userRepo.Events[0]
+		if event.Type != models.EventReviewDigest || event.Payload.ReviewerID != "u2" {
+			t.Fatalf("Unexpected digest: %+v", event)
+		}
+		if p := event.Payload.Pending; len(p) != 1 || p[0].PRID != "pr-1" || p[0].WaitingMinutes != 90 {
+			t.Errorf("Unexpected pending reviews: %+v", p)
+		}
+	})
+
+	t.Run("Quiet hours and merged PRs are skipped", func(t *testing.T) {
+		if _, ok := userRepo.LastDigestAt["u3"]; ok {
+			t.Error("u3 must not get a digest during quiet hours")
+		}
+		if _, ok := userRepo.LastDigestAt["u4"]; ok {
+			t.Error("u4 has nothing pending")
+		}
+	})
+
+	t.Run("One digest per day", func(t *testing.T) {
+		_ = job.Run(ctx, now.Add(20*time.Minute))
+		if len(userRepo.Events) != 1 {
+			t.Fatalf("Expected no new digests, got %d", len(userRepo.Events))
+		}
+
+		_ = job.Run(ctx, now.Add(8*time.Hour))
+		if len(userRepo.Events) != 3 {
+			t.Errorf("Expected digests for u2 and u3 next day, got %d", len(userRepo.Events))
+		}
+	})
+
+	t.Run("Skipped while another replica holds the lock", func(t *testing.T) {
+		later := now.Add(48 * time.Hour)
+		_, _ = locker.TryWithLock(ctx, digestLockName, func(ctx context.Context) error {
+			return job.Run(ctx, later)
+		})
+		if len(userRepo.Events) != 3 {
+			t.Errorf("Expected no digests without the lock, got %d", len(userRepo.Events))
+		}
+	})
+}
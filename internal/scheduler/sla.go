@@ -0,0 +1,104 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/repository"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/services"
+)
+
+const slaLockName = "review_sla"
+
+// SLAJob flags assignments that missed the team review SLA and, for teams with
+// sla_auto_reassign, hands them to another reviewer. The job runs under a shared
+// lock so several replicas never escalate the same review twice.
+type SLAJob struct {
+	locker    repository.LockerInterface
+	prRepo    repository.PullRequestRepositoryInterface
+	teamRepo  repository.TeamRepositoryInterface
+	prService services.PullRequestServiceInterface
+	log       *slog.Logger
+}
+
+func NewSLAJob(
+	locker repository.LockerInterface,
+	prRepo repository.PullRequestRepositoryInterface,
+	teamRepo repository.TeamRepositoryInterface,
+	prService services.PullRequestServiceInterface,
+	log *slog.Logger,
+) *SLAJob {
+	return &SLAJob{
+		locker:    locker,
+		prRepo:    prRepo,
+		teamRepo:  teamRepo,
+		prService: prService,
+		log:       log,
+	}
+}
+
+func (j *SLAJob) Run(ctx context.Context, now time.Time) error {
+	acquired, err := j.locker.TryWithLock(ctx, slaLockName, func(ctx context.Context) error {
+		return j.escalate(ctx, now)
+	})
+	if err != nil {
+		return err
+	}
+	if !acquired {
+		j.log.DebugContext(ctx, "review sla is being checked by another replica")
+	}
+	return nil
+}
+
+// escalate flags newly overdue assignments and then escalates every flagged one of
+// teams with sla_auto_reassign, so assignments a previous run failed to reassign
+// are retried. A failed reassignment is logged and does not stop the others.
+func (j *SLAJob) escalate(ctx context.Context, now time.Time) error {
+	marked, err := j.prRepo.MarkOverdue(ctx, now)
+	if err != nil {
+		return err
+	}
+	for _, o := range marked {
+		j.log.InfoContext(ctx, "review is overdue", "pr_id", o.PRID, "reviewer_id", o.ReviewerID, "due_at", o.DueAt)
+	}
+
+	overdue, err := j.prRepo.GetOverdue(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	autoReassign := make(map[string]bool)
+	for _, o := range overdue {
+		enabled, ok := autoReassign[o.TeamName]
+		if !ok {
+			settings, err := j.teamRepo.GetSettings(ctx, o.TeamName)
+			if err != nil {
+				j.log.ErrorContext(ctx, "failed to get team settings", "team_name", o.TeamName, "err", err)
+				continue
+			}
+			enabled = settings.SLAAutoReassign
+			autoReassign[o.TeamName] = enabled
+		}
+		if !enabled {
+			continue
+		}
+
+		_, newReviewerID, err := j.prService.ReassignReviewer(ctx, o.PRID, o.ReviewerID, "")
+		if err != nil {
+			if errors.Is(err, models.ErrNoCandidates) {
+				j.log.WarnContext(ctx, "no candidates to escalate overdue review", "pr_id", o.PRID)
+			} else {
+				j.log.ErrorContext(ctx, "failed to escalate overdue review", "pr_id", o.PRID,
+					"reviewer_id", o.ReviewerID, "err", err)
+			}
+			continue
+		}
+		j.log.InfoContext(ctx, "overdue review reassigned", "pr_id", o.PRID,
+			"old_reviewer", o.ReviewerID, "new_reviewer", newReviewerID)
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/repository/inmemory"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/services"
+)
+
+func TestSLAJob_Simple(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+	now := time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC)
+
+	userRepo := inmemory.NewTestUserRepo()
+	userRepo.Users["u1"] = &models.User{ID: "u1", TeamName: "backend", IsActive: true}
+	userRepo.Users["u2"] = &models.User{ID: "u2", TeamName: "backend", IsActive: true}
+	userRepo.Users["u3"] = &models.User{ID: "u3", TeamName: "backend", IsActive: true}
+	userRepo.Users["f1"] = &models.User{ID: "f1", TeamName: "frontend", IsActive: true}
+	userRepo.Users["f2"] = &models.User{ID: "f2", TeamName: "frontend", IsActive: true}
+
+	teamRepo := inmemory.NewTestTeamRepo()
+	teamRepo.Teams["backend"] = &models.Team{Name: "backend"}
+	teamRepo.Teams["frontend"] = &models.Team{Name: "frontend"}
+	teamRepo.Settings["backend"] = &models.TeamSettings{ReviewersCount: 1, ReviewSLAMinutes: 60, SLAAutoReassign: true}
+	teamRepo.Settings["frontend"] = &models.TeamSettings{ReviewersCount: 1, ReviewSLAMinutes: 60}
+
+	prRepo := inmemory.NewTestPRRepo()
+	prRepo.Prs["pr-1"] = &models.PullRequest{ID: "pr-1", AuthorID: "u1", Status: models.PRStatusOpen, Reviewers: []string{"u2"}}
+	prRepo.Prs["pr-2"] = &models.PullRequest{ID: "pr-2", AuthorID: "f1", Status: models.PRStatusOpen, Reviewers: []string{"f2"}}
+	prRepo.Overdue = []models.OverdueReview{
+		{PRID: "pr-1", ReviewerID: "u2", TeamName: "backend", DueAt: now.Add(-time.Minute)},
+		{PRID: "pr-2", ReviewerID: "f2", TeamName: "frontend", DueAt: now.Add(-time.Minute)},
+		{PRID: "pr-3", ReviewerID: "u3", TeamName: "backend", DueAt: now.Add(time.Hour)},
+	}
+
+	txm := inmemory.NewTxManager(inmemory.NewStore())
+	prService := services.NewPullRequestService(
+		prRepo, userRepo, teamRepo, inmemory.NewTestRepositoryRepo(), inmemory.NewTestCodeOwnerRepo(),
+		txm, services.NewRandomSelector(), logger,
+	)
+	locker := inmemory.NewLocker()
+	job := NewSLAJob(locker, prRepo, teamRepo, prService, logger)
+
+	if err := job.Run(ctx, now); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	t.Run("Auto reassign", func(t *testing.T) {
+		if got := prRepo.Prs["pr-1"].Reviewers; len(got) != 1 || got[0] != "u3" {
+			t.Errorf("Expected overdue reviewer to be replaced by u3, got %v", got)
+		}
+	})
+
+	t.Run("Only flagged without auto reassign", func(t *testing.T) {
+		overdue, _ := prService.GetOverdueReviews(ctx, "")
+		if len(overdue) != 1 || overdue[0].PRID != "pr-2" {
+			t.Errorf("Expected only pr-2 to stay overdue, got %+v", overdue)
+		}
+		if got := prRepo.Prs["pr-2"].Reviewers; got[0] != "f2" {
+			t.Errorf("Reviewer must not change, got %v", got)
+		}
+	})
+
+	t.Run("Failures are skipped and flagged reviews retried", func(t *testing.T) {
+		prRepo.Prs["pr-4"] = &models.PullRequest{ID: "pr-4", AuthorID: "u1", Status: models.PRStatusOpen, Reviewers: []string{"u2"}}
+		prRepo.Overdue = append(prRepo.Overdue,
+			models.OverdueReview{PRID: "ghost", ReviewerID: "u2", TeamName: "backend", OverdueAt: now.Add(-time.Hour)},
+			models.OverdueReview{PRID: "pr-4", ReviewerID: "u2", TeamName: "backend", OverdueAt: now.Add(-time.Hour)},
+		)
+
+		if err := job.Run(ctx, now); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got := prRepo.Prs["pr-4"].Reviewers; len(got) != 1 || got[0] != "u3" {
+			t.Errorf("Expected the earlier flagged reviewer to be replaced by u3, got %v", got)
+		}
+	})
+
+	t.Run("Skipped while another replica holds the lock", func(t *testing.T) {
+		prRepo.Prs["pr-5"] = &models.PullRequest{ID: "pr-5", AuthorID: "u1", Status: models.PRStatusOpen, Reviewers: []string{"u2"}}
+		prRepo.Overdue = append(prRepo.Overdue,
+			models.OverdueReview{PRID: "pr-5", ReviewerID: "u2", TeamName: "backend", OverdueAt: now.Add(-time.Hour)})
+
+		_, _ = locker.TryWithLock(ctx, slaLockName, func(ctx context.Context) error {
+			return job.Run(ctx, now)
+		})
+		if got := prRepo.Prs["pr-5"].Reviewers; len(got) != 1 || got[0] != "u2" {
+			t.Errorf("Expected no reassignment without the lock, got %v", got)
+		}
+	})
+}
@@ -0,0 +1,208 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/codeowners"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/repository"
+)
+
+type CodeOwnerService struct {
+	repo     repository.CodeOwnerRepositoryInterface
+	userRepo repository.UserRepositoryInterface
+	teamRepo repository.TeamRepositoryInterface
+	log      *slog.Logger
+}
+
+func NewCodeOwnerService(
+	repo repository.CodeOwnerRepositoryInterface,
+	userRepo repository.UserRepositoryInterface,
+	teamRepo repository.TeamRepositoryInterface,
+	log *slog.Logger,
+) *CodeOwnerService {
+	return &CodeOwnerService{
+		repo:     repo,
+		userRepo: userRepo,
+		teamRepo: teamRepo,
+		log:      log,
+	}
+}
+
+// GetRules returns the rules of a repository; an empty repositoryID selects the
+// default rules used by repositories without their own.
+func (s *CodeOwnerService) GetRules(ctx context.Context, repositoryID string) ([]models.CodeOwnerRule, error) {
+	rules, err := s.repo.GetRules(ctx, repositoryID)
+	if err != nil {
+		s.log.ErrorContext(ctx, "failed to get code owner rules", "repository_id", repositoryID, "err", err)
+		return nil, err
+	}
+	if rules == nil {
+		return []models.CodeOwnerRule{}, nil
+	}
+	return rules, nil
+}
+
+// SetRules replaces the whole rule set, the order of rules matters.
+func (s *CodeOwnerService) SetRules(
+	ctx context.Context,
+	repositoryID string,
+	rules []models.CodeOwnerRule,
+) ([]models.CodeOwnerRule, error) {
+	s.log.InfoContext(ctx, "setting code owner rules", "repository_id", repositoryID, "rules", len(rules))
+
+	for i := range rules {
+		if _, err := codeowners.Compile(rules[i].Pattern); err != nil {
+			s.log.WarnContext(ctx, "invalid code owner pattern", "pattern", rules[i].Pattern, "err", err)
+			return nil, models.ErrInvalidCodeOwners
+		}
+		if rules[i].Users == nil {
+			rules[i].Users = []string{}
+		}
+		if rules[i].Teams == nil {
+			rules[i].Teams = []string{}
+		}
+	}
+	if rules == nil {
+		rules = []models.CodeOwnerRule{}
+	}
+
+	if err := s.repo.ReplaceRules(ctx, repositoryID, rules); err != nil {
+		s.log.ErrorContext(ctx, "failed to save code owner rules", "err", err)
+		return nil, err
+	}
+	return rules, nil
+}
+
+// ImportCodeOwners converts a CODEOWNERS file into the repository's rules. Lines
+// with invalid patterns are dropped and owners that can't be resolved are left
+// out of their rule; both are reported as problems. A dry run stores nothing.
+func (s *CodeOwnerService) ImportCodeOwners(
+	ctx context.Context,
+	repositoryID string,
+	file io.Reader,
+	dryRun bool,
+) (*models.CodeOwnersImport, error) {
+	entries, err := codeowners.Parse(file)
+	if err != nil {
+		s.log.WarnContext(ctx, "failed to parse CODEOWNERS", "err", err)
+		return nil, models.ErrInvalidCodeOwners
+	}
+	s.log.InfoContext(ctx, "importing code owners", "repository_id", repositoryID,
+		"entries", len(entries), "dry_run", dryRun)
+
+	result := &models.CodeOwnersImport{
+		RepositoryID: repositoryID,
+		DryRun:       dryRun,
+		Rules:        []models.CodeOwnerRule{},
+		Problems:     []models.CodeOwnersProblem{},
+	}
+	for _, entry := range entries {
+		if _, err := codeowners.Compile(entry.Pattern); err != nil {
+			result.Problems = append(result.Problems, models.CodeOwnersProblem{
+				Line:    entry.Line,
+				Message: "invalid pattern",
+			})
+			continue
+		}
+
+		rule := models.CodeOwnerRule{Pattern: entry.Pattern, Users: []string{}, Teams: []string{}}
+		for _, owner := range entry.Owners {
+			userID, teamName, err := s.resolveOwner(ctx, owner)
+			switch {
+			case errors.Is(err, models.ErrNotFound):
+				result.Problems = append(result.Problems, models.CodeOwnersProblem{
+					Line:    entry.Line,
+					Owner:   owner,
+					Message: ownerProblem(owner),
+				})
+			case err != nil:
+				s.log.ErrorContext(ctx, "failed to resolve code owner", "owner", owner, "err", err)
+				return nil, err
+			case teamName != "":
+				rule.Teams = append(rule.Teams, teamName)
+			default:
+				rule.Users = append(rule.Users, userID)
+			}
+		}
+		result.Rules = append(result.Rules, rule)
+	}
+
+	if dryRun {
+		return result, nil
+	}
+	if err := s.repo.ReplaceRules(ctx, repositoryID, result.Rules); err != nil {
+		s.log.ErrorContext(ctx, "failed to save code owner rules", "err", err)
+		return nil, err
+	}
+	return result, nil
+}
+
+// resolveOwner maps "@org/team" to a team (by full name, then by the name after
+// the slash), "@login" to a user with that GitHub or GitLab login or ID, and an
+// email address to a user with a linked email.
+func (s *CodeOwnerService) resolveOwner(ctx context.Context, owner string) (userID, teamName string, err error) {
+	if isTeamOwner(owner) {
+		name := strings.TrimPrefix(owner, "@")
+		candidates := []string{name}
+		if i := strings.LastIndex(name, "/"); i >= 0 {
+			candidates = append(candidates, name[i+1:])
+		}
+		for _, candidate := range candidates {
+			team, err := s.teamRepo.GetTeam(ctx, candidate)
+			if err == nil {
+				return "", team.Name, nil
+			}
+			if !errors.Is(err, models.ErrNotFound) {
+				return "", "", err
+			}
+		}
+		return "", "", models.ErrNotFound
+	}
+
+	login, ok := strings.CutPrefix(owner, "@")
+	if !ok {
+		if !strings.Contains(owner, "@") {
+			return "", "", models.ErrNotFound
+		}
+		user, err := s.userRepo.GetUserByIdentity(ctx, models.ProviderEmail, strings.ToLower(owner))
+		if err != nil {
+			return "", "", err
+		}
+		return user.ID, "", nil
+	}
+
+	for _, provider := range []string{models.ProviderGitHub, models.ProviderGitLab} {
+		user, err := s.userRepo.GetUserByIdentity(ctx, provider, login)
+		if err == nil {
+			return user.ID, "", nil
+		}
+		if !errors.Is(err, models.ErrNotFound) {
+			return "", "", err
+		}
+	}
+	user, err := s.userRepo.GetUser(ctx, login)
+	if err != nil {
+		return "", "", err
+	}
+	return user.ID, "", nil
+}
+
+func ownerProblem(owner string) string {
+	switch {
+	case isTeamOwner(owner):
+		return "unknown team"
+	case strings.Contains(owner, "@"):
+		return "unknown user"
+	default:
+		return "invalid owner"
+	}
+}
+
+func isTeamOwner(owner string) bool {
+	return strings.HasPrefix(owner, "@") && strings.Contains(owner, "/")
+}
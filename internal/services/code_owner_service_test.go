@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/repository/inmemory"
+)
+
+func TestCodeOwnerService_Simple(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	repo := inmemory.NewTestCodeOwnerRepo()
+	userRepo := inmemory.NewTestUserRepo()
+	teamRepo := inmemory.NewTestTeamRepo()
+
+	userRepo.Users["u1"] = &models.User{ID: "u1", TeamName: "backend", IsActive: true}
+	userRepo.Users["u2"] = &models.User{ID: "u2", TeamName: "backend", IsActive: true}
+	userRepo.Identities[models.ProviderGitHub+"/octocat"] = "u1"
+	userRepo.Identities[models.ProviderEmail+"/u2@example.com"] = "u2"
+	teamRepo.Teams["backend"] = &models.Team{Name: "backend"}
+
+	service := NewCodeOwnerService(repo, userRepo, teamRepo, logger)
+	ctx := context.Background()
+
+	file := strings.Join([]string{
+		"# Owners",
+		"*.go @octocat @acme/backend",
+		"[Docs]",
+		"/docs/ U2@example.com @ghost @acme/ghosts",
+		"/ @octocat",
+		"*.md docs-team",
+	}, "\n")
+
+	t.Run("Dry run reports unknown owners", func(t *testing.T) {
+		result, err := service.ImportCodeOwners(ctx, "acme/api", strings.NewReader(file), true)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(result.Rules) != 3 {
+			t.Fatalf("Expected 3 rules, got %+v", result.Rules)
+		}
+		if r := result.Rules[0]; r.Users[0] != "u1" || r.Teams[0] != "backend" {
+			t.Errorf("Unexpected first rule: %+v", r)
+		}
+		if r := result.Rules[1]; len(r.Users) != 1 || r.Users[0] != "u2" || len(r.Teams) != 0 {
+			t.Errorf("Unexpected second rule: %+v", r)
+		}
+
+		want := []models.CodeOwnersProblem{
+			{Line: 4, Owner: "@ghost", Message: "unknown user"},
+			{Line: 4, Owner: "@acme/ghosts", Message: "unknown team"},
+			{Line: 5, Message: "invalid pattern"},
+			{Line: 6, Owner: "docs-team", Message: "invalid owner"},
+		}
+		if len(result.Problems) != len(want) {
+			t.Fatalf("Expected %d problems, got %+v", len(want), result.Problems)
+		}
+		for i := range want {
+			if result.Problems[i] != want[i] {
+				t.Errorf("Expected %+v, got %+v", want[i], result.Problems[i])
+			}
+		}
+		if len(repo.Rules["acme/api"]) != 0 {
+			t.Errorf("Dry run should not store rules")
+		}
+	})
+
+	t.Run("Import stores repository rules", func(t *testing.T) {
+		if _, err := service.ImportCodeOwners(ctx, "acme/api", strings.NewReader(file), false); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		rules, _ := service.GetRules(ctx, "acme/api")
+		if len(rules) != 3 || rules[0].Pattern != "*.go" {
+			t.Errorf("Unexpected stored rules: %+v", rules)
+		}
+		rules, _ = service.GetRules(ctx, "")
+		if len(rules) != 0 {
+			t.Errorf("Default rules should be untouched, got %+v", rules)
+		}
+	})
+
+	t.Run("Invalid pattern is rejected", func(t *testing.T) {
+		_, err := service.SetRules(ctx, "", []models.CodeOwnerRule{{Pattern: "/"}})
+		if !errors.Is(err, models.ErrInvalidCodeOwners) {
+			t.Errorf("Expected ErrInvalidCodeOwners, got %v", err)
+		}
+	})
+}
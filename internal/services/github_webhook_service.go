@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/repository"
+)
+
+type githubPullRequestEvent struct {
+	Action      string `json:"action"`
+	Number      int    `json:"number"`
+	PullRequest struct {
+		Title  string `json:"title"`
+		Draft  bool   `json:"draft"`
+		Merged bool   `json:"merged"`
+		User   struct {
+			Login string `json:"login"`
+		} `json:"user"`
+	} `json:"pull_request"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+type GitHubWebhookService struct {
+	secret  []byte
+	applier *prEventApplier
+	log     *slog.Logger
+}
+
+func NewGitHubWebhookService(
+	secret string,
+	prService PullRequestServiceInterface,
+	userRepo repository.UserRepositoryInterface,
+	log *slog.Logger,
+) *GitHubWebhookService {
+	return &GitHubWebhookService{
+		secret: []byte(secret),
+		applier: &prEventApplier{
+			prService: prService,
+			userRepo:  userRepo,
+			log:       log,
+		},
+		log: log,
+	}
+}
+
+// VerifySignature checks the X-Hub-Signature-256 header against the payload.
+func (s *GitHubWebhookService) VerifySignature(payload []byte, signature string) bool {
+	if len(s.secret) == 0 {
+		return false
+	}
+
+	hexSum, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return false
+	}
+	got, err := hex.DecodeString(hexSum)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write(payload)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+// HandleEvent applies a GitHub event to our PRs. It reports false for events it ignores.
+func (s *GitHubWebhookService) HandleEvent(ctx context.Context, eventType string, payload []byte) (bool, error) {
+	if eventType != "pull_request" {
+		s.log.DebugContext(ctx, "ignoring github event", "event", eventType)
+		return false, nil
+	}
+
+	var event githubPullRequestEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		s.log.WarnContext(ctx, "failed to decode github payload", "err", err)
+		return false, fmt.Errorf("%w: %v", models.ErrInvalidPayload, err)
+	}
+
+	prEvent := externalPREvent{
+		Provider:       models.ProviderGitHub,
+		PRID:           GitHubPRID(event.Repository.FullName, event.Number),
+		RepositoryID:   event.Repository.FullName,
+		Title:          event.PullRequest.Title,
+		AuthorProvider: models.ProviderGitHub,
+		AuthorLogin:    event.PullRequest.User.Login,
+		Draft:          event.PullRequest.Draft,
+	}
+
+	switch event.Action {
+	case "opened":
+		prEvent.Action = prActionOpen
+	case "closed":
+		prEvent.Action = prActionClose
+		if event.PullRequest.Merged {
+			prEvent.Action = prActionMerge
+		}
+	case "reopened":
+		prEvent.Action = prActionReopen
+	case "ready_for_review":
+		prEvent.Action = prActionReady
+	default:
+		s.log.DebugContext(ctx, "ignoring github pull_request action", "action", event.Action)
+		return false, nil
+	}
+
+	if err := s.applier.apply(ctx, prEvent); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func GitHubPRID(repoFullName string, number int) string {
+	return fmt.Sprintf("%s#%d", repoFullName, number)
+}
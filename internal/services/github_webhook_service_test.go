@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/repository/inmemory"
+)
+
+func loadFixture(t *testing.T, parts ...string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(append([]string{"testdata"}, parts...)...))
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	return data
+}
+
+func TestGitHubWebhookService_Simple(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	userRepo := inmemory.NewTestUserRepo()
+	userRepo.Users["u1"] = &models.User{ID: "u1", TeamName: "backend", IsActive: true}
+	userRepo.Users["u2"] = &models.User{ID: "u2", TeamName: "backend", IsActive: true}
+	userRepo.Users["u3"] = &models.User{ID: "u3", TeamName: "backend", IsActive: true}
+	_ = userRepo.LinkIdentity(ctx, &models.UserIdentity{Provider: models.ProviderGitHub, Login: "alice-gh", UserID: "u1"})
+
+	teamRepo := inmemory.NewTestTeamRepo()
+	teamRepo.Teams["backend"] = &models.Team{Name: "backend"}
+
+	prRepo := inmemory.NewTestPRRepo()
+	prService := NewPullRequestService(
+		prRepo, userRepo, teamRepo, inmemory.NewTestRepositoryRepo(), inmemory.NewTestCodeOwnerRepo(),
+		inmemory.NewTxManager(inmemory.NewStore()), NewRandomSelector(), logger,
+	)
+	service := NewGitHubWebhookService("s3cret", prService, userRepo, logger)
+
+	handle := func(t *testing.T, fixture string) {
+		t.Helper()
+		handled, err := service.HandleEvent(ctx, "pull_request", loadFixture(t, "github", fixture))
+		if err != nil {
+			t.Fatalf("Unexpected error for %s: %v", fixture, err)
+		}
+		if !handled {
+			t.Fatalf("Expected %s to be handled", fixture)
+		}
+	}
+
+	t.Run("Signature", func(t *testing.T) {
+		payload := loadFixture(t, "github", "pull_request_opened.json")
+		mac := hmac.New(sha256.New, []byte("s3cret"))
+		mac.Write(payload)
+		signature := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+		if !service.VerifySignature(payload, signature) {
+			t.Error("Expected valid signature")
+		}
+		if service.VerifySignature(append(payload, ' '), signature) {
+			t.Error("Expected tampered payload to fail")
+		}
+		if service.VerifySignature(payload, "sha1=deadbeef") {
+			t.Error("Expected wrong scheme to fail")
+		}
+		if NewGitHubWebhookService("", prService, userRepo, logger).VerifySignature(payload, signature) {
+			t.Error("Expected empty secret to reject everything")
+		}
+	})
+
+	t.Run("Opened creates PR", func(t *testing.T) {
+		handle(t, "pull_request_opened.json")
+		handle(t, "pull_request_opened.json")
+
+		pr, err := prRepo.GetByID(ctx, "octo-org/payments#42")
+		if err != nil {
+			t.Fatalf("PR was not created: %v", err)
+		}
+		if pr.AuthorID != "u1" || pr.Name != "Add refund endpoint" {
+			t.Errorf("Unexpected PR: %+v", pr)
+		}
+		if len(pr.Reviewers) != 2 {
+			t.Errorf("Expected 2 reviewers, got %v", pr.Reviewers)
+		}
+	})
+
+	t.Run("Closed, reopened and merged", func(t *testing.T) {
+		handle(t, "pull_request_closed.json")
+		pr, _ := prRepo.GetByID(ctx, "octo-org/payments#42")
+		if pr.Status != models.PRStatusClosed {
+			t.Errorf("Expected CLOSED, got %s", pr.Status)
+		}
+
+		handle(t, "pull_request_reopened.json")
+
+		// GitHub has already merged the PR, so the missing approval must not block it.
+		teamRepo.Settings["backend"] = &models.TeamSettings{ReviewersCount: 2, RequiredApprovals: 1}
+		defer delete(teamRepo.Settings, "backend")
+
+		handle(t, "pull_request_closed_merged.json")
+		pr, _ = prRepo.GetByID(ctx, "octo-org/payments#42")
+		if pr.Status != models.PRStatusMerged {
+			t.Errorf("Expected MERGED, got %s", pr.Status)
+		}
+		handle(t, "pull_request_closed_merged.json")
+	})
+
+	t.Run("Draft and ready for review", func(t *testing.T) {
+		handle(t, "pull_request_opened_draft.json")
+		pr, _ := prRepo.GetByID(ctx, "octo-org/payments#43")
+		if pr.Status != models.PRStatusDraft || len(pr.Reviewers) != 0 {
+			t.Fatalf("Expected DRAFT without reviewers, got %s with %v", pr.Status, pr.Reviewers)
+		}
+
+		handle(t, "pull_request_ready_for_review.json")
+		pr, _ = prRepo.GetByID(ctx, "octo-org/payments#43")
+		if pr.Status != models.PRStatusOpen || len(pr.Reviewers) != 2 {
+			t.Errorf("Expected OPEN with reviewers, got %s with %v", pr.Status, pr.Reviewers)
+		}
+	})
+
+	t.Run("Conflicting status is reported", func(t *testing.T) {
+		if _, err := prService.ClosePullRequest(ctx, "octo-org/payments#43"); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		_, err := service.HandleEvent(ctx, "pull_request", loadFixture(t, "github", "pull_request_ready_for_review.json"))
+		if !errors.Is(err, models.ErrInvalidStatus) {
+			t.Errorf("Expected ErrInvalidStatus, got %v", err)
+		}
+	})
+
+	t.Run("Ignored events", func(t *testing.T) {
+		handled, err := service.HandleEvent(ctx, "pull_request", loadFixture(t, "github", "pull_request_labeled.json"))
+		if err != nil || handled {
+			t.Errorf("Expected labeled action to be ignored, got %v, %v", handled, err)
+		}
+
+		handled, err = service.HandleEvent(ctx, "push", []byte(`{}`))
+		if err != nil || handled {
+			t.Errorf("Expected push event to be ignored, got %v, %v", handled, err)
+		}
+	})
+
+	t.Run("Unknown author", func(t *testing.T) {
+		delete(userRepo.Identities, "github/alice-gh")
+		payload := loadFixture(t, "github", "pull_request_opened_draft.json")
+
+		_, err := service.HandleEvent(ctx, "pull_request", payload)
+		if !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+
+		_, err = service.HandleEvent(ctx, "pull_request", []byte(`{"action":`))
+		if !errors.Is(err, models.ErrInvalidPayload) {
+			t.Errorf("Expected ErrInvalidPayload, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/repository"
+)
+
+const gitlabMergeRequestHook = "Merge Request Hook"
+
+type gitlabMergeRequestEvent struct {
+	ObjectKind string `json:"object_kind"`
+	User       struct {
+		Username string `json:"username"`
+	} `json:"user"`
+	Project struct {
+		PathWithNamespace string `json:"path_with_namespace"`
+	} `json:"project"`
+	ObjectAttributes struct {
+		IID            int    `json:"iid"`
+		AuthorID       int64  `json:"author_id"`
+		Title          string `json:"title"`
+		Action         string `json:"action"`
+		Draft          bool   `json:"draft"`
+		WorkInProgress bool   `json:"work_in_progress"`
+	} `json:"object_attributes"`
+	Changes struct {
+		Draft *struct {
+			Previous bool `json:"previous"`
+			Current  bool `json:"current"`
+		} `json:"draft"`
+	} `json:"changes"`
+}
+
+type GitLabWebhookService struct {
+	token      string
+	applier    *prEventApplier
+	deliveries repository.WebhookDeliveryRepositoryInterface
+	log        *slog.Logger
+}
+
+func NewGitLabWebhookService(
+	token string,
+	prService PullRequestServiceInterface,
+	userRepo repository.UserRepositoryInterface,
+	deliveries repository.WebhookDeliveryRepositoryInterface,
+	log *slog.Logger,
+) *GitLabWebhookService {
+	return &GitLabWebhookService{
+		token: token,
+		applier: &prEventApplier{
+			prService: prService,
+			userRepo:  userRepo,
+			log:       log,
+		},
+		deliveries: deliveries,
+		log:        log,
+	}
+}
+
+// VerifyToken checks the X-Gitlab-Token header against the configured secret token.
+func (s *GitLabWebhookService) VerifyToken(token string) bool {
+	if s.token == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(s.token), []byte(token)) == 1
+}
+
+// HandleEvent applies a GitLab event once per event UUID. It reports false for
+// ignored events and redeliveries.
+func (s *GitLabWebhookService) HandleEvent(ctx context.Context, eventType, eventUUID string, payload []byte) (bool, error) {
+	if eventType != gitlabMergeRequestHook {
+		s.log.DebugContext(ctx, "ignoring gitlab event", "event", eventType)
+		return false, nil
+	}
+
+	var event gitlabMergeRequestEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		s.log.WarnContext(ctx, "failed to decode gitlab payload", "err", err)
+		return false, fmt.Errorf("%w: %v", models.ErrInvalidPayload, err)
+	}
+
+	prEvent := externalPREvent{
+		Provider:       models.ProviderGitLab,
+		PRID:           GitLabPRID(event.Project.PathWithNamespace, event.ObjectAttributes.IID),
+		RepositoryID:   event.Project.PathWithNamespace,
+		Title:          event.ObjectAttributes.Title,
+		AuthorProvider: models.ProviderGitLabID,
+		AuthorLogin:    strconv.FormatInt(event.ObjectAttributes.AuthorID, 10),
+		Actor:          event.User.Username,
+		Draft:          event.ObjectAttributes.Draft || event.ObjectAttributes.WorkInProgress,
+	}
+
+	switch event.ObjectAttributes.Action {
+	case "open":
+		prEvent.Action = prActionOpen
+	case "update":
+		if event.Changes.Draft == nil || !event.Changes.Draft.Previous || event.Changes.Draft.Current {
+			s.log.DebugContext(ctx, "ignoring gitlab update without ready transition", "pr_id", prEvent.PRID)
+			return false, nil
+		}
+		prEvent.Action = prActionReady
+	case "merge":
+		prEvent.Action = prActionMerge
+	case "close":
+		prEvent.Action = prActionClose
+	case "reopen":
+		prEvent.Action = prActionReopen
+	default:
+		s.log.DebugContext(ctx, "ignoring gitlab merge request action", "action", event.ObjectAttributes.Action)
+		return false, nil
+	}
+
+	if eventUUID != "" {
+		fresh, err := s.deliveries.MarkReceived(ctx, models.ProviderGitLab, eventUUID)
+		if err != nil {
+			s.log.ErrorContext(ctx, "failed to record gitlab delivery", "err", err)
+			return false, err
+		}
+		if !fresh {
+			s.log.InfoContext(ctx, "skipping gitlab redelivery", "event_uuid", eventUUID)
+			return false, nil
+		}
+	}
+
+	if err := s.applier.apply(ctx, prEvent); err != nil {
+		if eventUUID != "" {
+			if forgetErr := s.deliveries.Forget(ctx, models.ProviderGitLab, eventUUID); forgetErr != nil {
+				s.log.ErrorContext(ctx, "failed to forget gitlab delivery", "err", forgetErr)
+			}
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+func GitLabPRID(projectPath string, iid int) string {
+	return fmt.Sprintf("%s!%d", projectPath, iid)
+}
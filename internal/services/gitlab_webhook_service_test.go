@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/repository/inmemory"
+)
+
+func TestGitLabWebhookService_Simple(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	userRepo := inmemory.NewTestUserRepo()
+	userRepo.Users["u1"] = &models.User{ID: "u1", TeamName: "backend", IsActive: true}
+	userRepo.Users["u2"] = &models.User{ID: "u2", TeamName: "backend", IsActive: true}
+	userRepo.Users["u3"] = &models.User{ID: "u3", TeamName: "backend", IsActive: true}
+	_ = userRepo.LinkIdentity(ctx, &models.UserIdentity{Provider: models.ProviderGitLabID, Login: "1187", UserID: "u1"})
+
+	teamRepo := inmemory.NewTestTeamRepo()
+	teamRepo.Teams["backend"] = &models.Team{Name: "backend"}
+
+	prRepo := inmemory.NewTestPRRepo()
+	deliveries := inmemory.NewTestWebhookDeliveryRepo()
+	prService := NewPullRequestService(
+		prRepo, userRepo, teamRepo, inmemory.NewTestRepositoryRepo(), inmemory.NewTestCodeOwnerRepo(),
+		inmemory.NewTxManager(inmemory.NewStore()), NewRandomSelector(), logger,
+	)
+	service := NewGitLabWebhookService("tok3n", prService, userRepo, deliveries, logger)
+
+	handle := func(t *testing.T, fixture, uuid string) bool {
+		t.Helper()
+		handled, err := service.HandleEvent(ctx, "Merge Request Hook", uuid, loadFixture(t, "gitlab", fixture))
+		if err != nil {
+			t.Fatalf("Unexpected error for %s: %v", fixture, err)
+		}
+		return handled
+	}
+
+	t.Run("Token", func(t *testing.T) {
+		if !service.VerifyToken("tok3n") {
+			t.Error("Expected valid token")
+		}
+		if service.VerifyToken("wrong") || service.VerifyToken("") {
+			t.Error("Expected wrong token to fail")
+		}
+	})
+
+	t.Run("Open is idempotent by event UUID", func(t *testing.T) {
+		if !handle(t, "merge_request_open.json", "uuid-1") {
+			t.Fatal("Expected open to be handled")
+		}
+		if handle(t, "merge_request_open.json", "uuid-1") {
+			t.Error("Expected redelivery to be skipped")
+		}
+
+		pr, err := prRepo.GetByID(ctx, "fintech/ledger!7")
+		if err != nil {
+			t.Fatalf("PR was not created: %v", err)
+		}
+		if pr.AuthorID != "u1" || len(pr.Reviewers) != 2 {
+			t.Errorf("Unexpected PR: %+v", pr)
+		}
+	})
+
+	t.Run("Close, reopen and merge", func(t *testing.T) {
+		handle(t, "merge_request_close.json", "uuid-2")
+		pr, _ := prRepo.GetByID(ctx, "fintech/ledger!7")
+		if pr.Status != models.PRStatusClosed {
+			t.Errorf("Expected CLOSED, got %s", pr.Status)
+		}
+
+		handle(t, "merge_request_reopen.json", "uuid-3")
+
+		// GitLab has already merged the MR, so the missing approval must not block it.
+		teamRepo.Settings["backend"] = &models.TeamSettings{ReviewersCount: 2, RequiredApprovals: 1}
+		defer delete(teamRepo.Settings, "backend")
+
+		handle(t, "merge_request_merge.json", "uuid-4")
+		pr, _ = prRepo.GetByID(ctx, "fintech/ledger!7")
+		if pr.Status != models.PRStatusMerged {
+			t.Errorf("Expected MERGED, got %s", pr.Status)
+		}
+
+		if handle(t, "merge_request_reopen.json", "uuid-3") {
+			t.Error("Expected old reopen redelivery to be skipped")
+		}
+	})
+
+	t.Run("Draft update", func(t *testing.T) {
+		handle(t, "merge_request_open_draft.json", "uuid-5")
+		pr, _ := prRepo.GetByID(ctx, "fintech/ledger!8")
+		if pr.Status != models.PRStatusDraft {
+			t.Fatalf("Expected DRAFT, got %s", pr.Status)
+		}
+
+		if handle(t, "merge_request_update_title.json", "uuid-6") {
+			t.Error("Expected title update to be ignored")
+		}
+
+		handle(t, "merge_request_update_ready.json", "uuid-7")
+		pr, _ = prRepo.GetByID(ctx, "fintech/ledger!8")
+		if pr.Status != models.PRStatusOpen || len(pr.Reviewers) != 2 {
+			t.Errorf("Expected OPEN with reviewers, got %s with %v", pr.Status, pr.Reviewers)
+		}
+	})
+
+	t.Run("Failed delivery can be retried", func(t *testing.T) {
+		delete(userRepo.Identities, "gitlab_id/1187")
+		var body map[string]any
+		if err := json.Unmarshal(loadFixture(t, "gitlab", "merge_request_open.json"), &body); err != nil {
+			t.Fatalf("failed to decode fixture: %v", err)
+		}
+		body["object_attributes"].(map[string]any)["iid"] = 9
+		payload, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("failed to encode payload: %v", err)
+		}
+
+		_, err = service.HandleEvent(ctx, "Merge Request Hook", "uuid-8", payload)
+		if !errors.Is(err, models.ErrNotFound) {
+			t.Fatalf("Expected ErrNotFound, got %v", err)
+		}
+
+		_ = userRepo.LinkIdentity(ctx, &models.UserIdentity{Provider: models.ProviderGitLabID, Login: "1187", UserID: "u1"})
+		handled, err := service.HandleEvent(ctx, "Merge Request Hook", "uuid-8", payload)
+		if err != nil || !handled {
+			t.Errorf("Expected retried delivery to be handled, got %v, %v", handled, err)
+		}
+	})
+
+	t.Run("Author comes from author_id, not the acting user", func(t *testing.T) {
+		_ = userRepo.LinkIdentity(ctx, &models.UserIdentity{Provider: models.ProviderGitLab, Login: "bob-gl", UserID: "u1"})
+		_ = userRepo.LinkIdentity(ctx, &models.UserIdentity{Provider: models.ProviderGitLabID, Login: "2042", UserID: "u2"})
+		var body map[string]any
+		if err := json.Unmarshal(loadFixture(t, "gitlab", "merge_request_open.json"), &body); err != nil {
+			t.Fatalf("failed to decode fixture: %v", err)
+		}
+		attrs := body["object_attributes"].(map[string]any)
+		attrs["iid"] = 10
+		attrs["author_id"] = 2042
+		payload, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("failed to encode payload: %v", err)
+		}
+
+		if _, err := service.HandleEvent(ctx, "Merge Request Hook", "uuid-10", payload); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		pr, err := prRepo.GetByID(ctx, "fintech/ledger!10")
+		if err != nil {
+			t.Fatalf("PR was not created: %v", err)
+		}
+		if pr.AuthorID != "u2" {
+			t.Errorf("Expected author u2, got %s", pr.AuthorID)
+		}
+	})
+
+	t.Run("Other hooks are ignored", func(t *testing.T) {
+		handled, err := service.HandleEvent(ctx, "Push Hook", "uuid-9", []byte(`{}`))
+		if err != nil || handled {
+			t.Errorf("Expected push hook to be ignored, got %v, %v", handled, err)
+		}
+	})
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"io"
 
 	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
 )
@@ -9,20 +10,76 @@ import (
 type TeamServiceInterface interface {
 	CreateTeam(ctx context.Context, team *models.Team) (*models.Team, error)
 	GetTeam(ctx context.Context, name string) (*models.Team, error)
+	SetSettings(ctx context.Context, teamName string, settings *models.TeamSettings) (*models.TeamSettings, error)
+}
+
+type RepositoryServiceInterface interface {
+	CreateRepository(ctx context.Context, repo *models.Repository) (*models.Repository, error)
+	UpdateRepository(ctx context.Context, repo *models.Repository) (*models.Repository, error)
+	GetRepository(ctx context.Context, id string) (*models.Repository, error)
+	ListRepositories(ctx context.Context, teamName string) ([]models.Repository, error)
 }
 
 type UserServiceInterface interface {
 	SetUserIsActive(ctx context.Context, userID string, isActive bool) (*models.User, error)
 	DeactivateUsers(ctx context.Context, userIDs []string) error
+	LinkIdentity(ctx context.Context, identity *models.UserIdentity) error
+	SetChatHandle(ctx context.Context, userID, handle string) error
+	SetQuietHours(ctx context.Context, userID string, quiet models.QuietHours) error
+	CreateAbsence(ctx context.Context, absence *models.Absence) (*models.Absence, error)
+	ListAbsences(ctx context.Context, userID string) ([]models.Absence, error)
+	CancelAbsence(ctx context.Context, id int64) error
+	ImportAbsences(ctx context.Context, feed io.Reader) (*models.AbsenceImportResult, error)
 }
 
 type PullRequestServiceInterface interface {
-	CreatePullRequest(ctx context.Context, id, name, authorID string) (*models.PullRequest, error)
+	CreatePullRequest(
+		ctx context.Context,
+		id, name, authorID, repositoryID string,
+		draft bool,
+		files []string,
+	) (*models.PullRequest, error)
 	MergePullRequest(ctx context.Context, id string) (*models.PullRequest, error)
-	ReassignReviewer(ctx context.Context, prID, oldUserID string) (pr *models.PullRequest, newReviewerID string, err error)
+	// RecordUpstreamMerge marks a PR merged on the code host, skipping the approval
+	// and status checks the host has already made.
+	RecordUpstreamMerge(ctx context.Context, id string) (*models.PullRequest, error)
+	ClosePullRequest(ctx context.Context, id string) (*models.PullRequest, error)
+	ReopenPullRequest(ctx context.Context, id string) (*models.PullRequest, error)
+	MarkReady(ctx context.Context, id string) (*models.PullRequest, error)
+	ReassignReviewer(ctx context.Context, prID, oldUserID, newUserID string) (pr *models.PullRequest, newReviewerID string, err error)
+	AddReviewer(ctx context.Context, prID, userID string) (*models.PullRequest, error)
+	RemoveReviewer(ctx context.Context, prID, userID string) (*models.PullRequest, error)
+	GetPullRequest(ctx context.Context, id string) (*models.PullRequest, error)
 	GetReviewerPRs(ctx context.Context, userID string) ([]*models.PullRequestShort, error)
+	SubmitReview(ctx context.Context, prID, reviewerID, verdict string) (*models.Review, error)
+	GetReviews(ctx context.Context, prID string) ([]models.Review, error)
+	GetOverdueReviews(ctx context.Context, teamName string) ([]models.OverdueReview, error)
+}
+
+type GitHubWebhookServiceInterface interface {
+	VerifySignature(payload []byte, signature string) bool
+	HandleEvent(ctx context.Context, eventType string, payload []byte) (bool, error)
+}
+
+type GitLabWebhookServiceInterface interface {
+	VerifyToken(token string) bool
+	HandleEvent(ctx context.Context, eventType, eventUUID string, payload []byte) (bool, error)
+}
+
+type NotificationServiceInterface interface {
+	CreateSubscription(ctx context.Context, sub *models.Subscription) (*models.Subscription, error)
+	ListSubscriptions(ctx context.Context) ([]models.Subscription, error)
+	DeleteSubscription(ctx context.Context, id int64) error
+	ListDeadLetters(ctx context.Context) ([]models.Delivery, error)
+	ReplayDeadLetter(ctx context.Context, id int64) error
 }
 
 type StatsServiceInterface interface {
 	GetTopReviewers(ctx context.Context) ([]*models.ReviewerStat, error)
 }
+
+type CodeOwnerServiceInterface interface {
+	GetRules(ctx context.Context, repositoryID string) ([]models.CodeOwnerRule, error)
+	SetRules(ctx context.Context, repositoryID string, rules []models.CodeOwnerRule) ([]models.CodeOwnerRule, error)
+	ImportCodeOwners(ctx context.Context, repositoryID string, file io.Reader, dryRun bool) (*models.CodeOwnersImport, error)
+}
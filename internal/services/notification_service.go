@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/repository"
+)
+
+type NotificationService struct {
+	repo repository.NotificationRepositoryInterface
+	log  *slog.Logger
+}
+
+func NewNotificationService(repo repository.NotificationRepositoryInterface, log *slog.Logger) *NotificationService {
+	return &NotificationService{
+		repo: repo,
+		log:  log,
+	}
+}
+
+func (s *NotificationService) Publish(ctx context.Context, event models.Event) error {
+	if err := s.repo.EnqueueDeliveries(ctx, event); err != nil {
+		s.log.ErrorContext(ctx, "failed to enqueue event", "event_id", event.ID, "type", event.Type, "err", err)
+		return err
+	}
+	return nil
+}
+
+func (s *NotificationService) CreateSubscription(ctx context.Context, sub *models.Subscription) (*models.Subscription, error) {
+	s.log.InfoContext(ctx, "creating subscription", "url", sub.URL, "event_types", sub.EventTypes)
+
+	for _, eventType := range sub.EventTypes {
+		if !isKnownEventType(eventType) {
+			s.log.WarnContext(ctx, "unknown event type", "type", eventType)
+			return nil, models.ErrInvalidEventType
+		}
+	}
+	if sub.EventTypes == nil {
+		sub.EventTypes = []string{}
+	}
+
+	if err := s.repo.CreateSubscription(ctx, sub); err != nil {
+		s.log.ErrorContext(ctx, "failed to create subscription", "err", err)
+		return nil, err
+	}
+	return sub, nil
+}
+
+func (s *NotificationService) ListSubscriptions(ctx context.Context) ([]models.Subscription, error) {
+	subs, err := s.repo.ListSubscriptions(ctx)
+	if err != nil {
+		s.log.ErrorContext(ctx, "failed to list subscriptions", "err", err)
+		return nil, err
+	}
+	if subs == nil {
+		return []models.Subscription{}, nil
+	}
+	return subs, nil
+}
+
+func (s *NotificationService) DeleteSubscription(ctx context.Context, id int64) error {
+	s.log.InfoContext(ctx, "deleting subscription", "subscription_id", id)
+	if err := s.repo.DeleteSubscription(ctx, id); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			s.log.WarnContext(ctx, "subscription not found", "subscription_id", id)
+		} else {
+			s.log.ErrorContext(ctx, "failed to delete subscription", "err", err)
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *NotificationService) ListDeadLetters(ctx context.Context) ([]models.Delivery, error) {
+	deliveries, err := s.repo.ListDeadLetters(ctx)
+	if err != nil {
+		s.log.ErrorContext(ctx, "failed to list dead letters", "err", err)
+		return nil, err
+	}
+	if deliveries == nil {
+		return []models.Delivery{}, nil
+	}
+	return deliveries, nil
+}
+
+func (s *NotificationService) ReplayDeadLetter(ctx context.Context, id int64) error {
+	s.log.InfoContext(ctx, "replaying dead letter", "delivery_id", id)
+	if err := s.repo.ReplayDeadLetter(ctx, id, time.Now()); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			s.log.WarnContext(ctx, "dead letter not found", "delivery_id", id)
+		} else {
+			s.log.ErrorContext(ctx, "failed to replay dead letter", "err", err)
+		}
+		return err
+	}
+	return nil
+}
+
+func isKnownEventType(eventType string) bool {
+	switch eventType {
+	case models.EventPRCreated, models.EventReviewerAssigned, models.EventReviewerReassigned,
+		models.EventReviewerRemoved, models.EventPRMerged, models.EventUserDeactivated, models.EventReviewDigest:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/repository/inmemory"
+)
+
+func TestNotificationService_Simple(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	repo := inmemory.NewTestNotificationRepo()
+	service := NewNotificationService(repo, logger)
+
+	t.Run("Subscriptions", func(t *testing.T) {
+		_, err := service.CreateSubscription(ctx, &models.Subscription{URL: "http://a", EventTypes: []string{"pr.deleted"}})
+		if !errors.Is(err, models.ErrInvalidEventType) {
+			t.Errorf("Expected ErrInvalidEventType, got %v", err)
+		}
+
+		all, err := service.CreateSubscription(ctx, &models.Subscription{URL: "http://all"})
+		if err != nil || all.ID == 0 {
+			t.Fatalf("Unexpected result: %+v, %v", all, err)
+		}
+		_, _ = service.CreateSubscription(ctx, &models.Subscription{
+			URL:        "http://merged",
+			EventTypes: []string{models.EventPRMerged},
+		})
+
+		subs, _ := service.ListSubscriptions(ctx)
+		if len(subs) != 2 {
+			t.Errorf("Expected 2 subscriptions, got %d", len(subs))
+		}
+	})
+
+	t.Run("Publish respects event types", func(t *testing.T) {
+		_ = service.Publish(ctx, models.NewEvent(models.EventPRCreated, models.EventPayload{PRID: "pr-1"}))
+		_ = service.Publish(ctx, models.NewEvent(models.EventPRMerged, models.EventPayload{PRID: "pr-1"}))
+
+		if len(repo.Deliveries) != 3 {
+			t.Errorf("Expected 3 deliveries, got %d", len(repo.Deliveries))
+		}
+	})
+
+	t.Run("Dead letters", func(t *testing.T) {
+		d := repo.Deliveries[0]
+		_ = repo.MarkFailed(ctx, d.ID, "boom", d.NextAttemptAt, true)
+
+		dead, _ := service.ListDeadLetters(ctx)
+		if len(dead) != 1 || dead[0].LastError != "boom" {
+			t.Fatalf("Unexpected dead letters: %+v", dead)
+		}
+
+		if err := service.ReplayDeadLetter(ctx, d.ID); err != nil {
+			t.Fatalf("Replay failed: %v", err)
+		}
+		if d.Status != models.DeliveryPending || d.Attempts != 0 {
+			t.Errorf("Expected replayed delivery to be pending, got %+v", d)
+		}
+		if err := service.ReplayDeadLetter(ctx, d.ID); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound for not dead delivery, got %v", err)
+		}
+	})
+}
@@ -4,34 +4,61 @@ import (
 	"context"
 	"errors"
 	"log/slog"
-	"math/rand"
 	"slices"
 	"time"
 
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/codeowners"
 	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
 	"github.com/yohnnn/pr_reviewer_assignment_service/internal/repository"
 )
 
+// maxReassignAttempts bounds retries of a reassignment whose PR keeps changing
+// concurrently.
+const maxReassignAttempts = 5
+
 type PullRequestService struct {
-	prRepo   repository.PullRequestRepositoryInterface
-	userRepo repository.UserRepositoryInterface
-	log      *slog.Logger
+	prRepo    repository.PullRequestRepositoryInterface
+	userRepo  repository.UserRepositoryInterface
+	teamRepo  repository.TeamRepositoryInterface
+	repoRepo  repository.RepositoryRepositoryInterface
+	ownerRepo repository.CodeOwnerRepositoryInterface
+	txm       repository.TxManager
+	scopes    *reviewScopes
+	selector  ReviewerSelector
+	log       *slog.Logger
 }
 
 func NewPullRequestService(
 	prRepo repository.PullRequestRepositoryInterface,
 	userRepo repository.UserRepositoryInterface,
+	teamRepo repository.TeamRepositoryInterface,
+	repoRepo repository.RepositoryRepositoryInterface,
+	ownerRepo repository.CodeOwnerRepositoryInterface,
+	txm repository.TxManager,
+	selector ReviewerSelector,
 	log *slog.Logger,
 ) *PullRequestService {
 	return &PullRequestService{
-		prRepo:   prRepo,
-		userRepo: userRepo,
-		log:      log,
+		prRepo:    prRepo,
+		userRepo:  userRepo,
+		teamRepo:  teamRepo,
+		repoRepo:  repoRepo,
+		ownerRepo: ownerRepo,
+		txm:       txm,
+		scopes:    newReviewScopes(userRepo, teamRepo, repoRepo, selector, log),
+		selector:  selector,
+		log:       log,
 	}
 }
 
-func (s *PullRequestService) CreatePullRequest(ctx context.Context, id, name, authorID string) (*models.PullRequest, error) {
-	s.log.InfoContext(ctx, "creating PR", "PR_id", id)
+// CreatePullRequest assigns owners of the changed files first, see pickReviewers.
+func (s *PullRequestService) CreatePullRequest(
+	ctx context.Context,
+	id, name, authorID, repositoryID string,
+	draft bool,
+	files []string,
+) (*models.PullRequest, error) {
+	s.log.InfoContext(ctx, "creating PR", "PR_id", id, "repository_id", repositoryID, "draft", draft, "files", len(files))
 	user, err := s.userRepo.GetUser(ctx, authorID)
 
 	if err != nil {
@@ -43,42 +70,40 @@ func (s *PullRequestService) CreatePullRequest(ctx context.Context, id, name, au
 		return nil, err
 	}
 
-	activeUsers, err := s.userRepo.GetActiveUsersByTeam(ctx, user.TeamName)
-
-	if err != nil {
-		s.log.ErrorContext(ctx, "failed to get active users", "err", err)
-		return nil, err
-	}
-
-	candidates := make([]string, 0, len(activeUsers))
-	for _, user := range activeUsers {
-		if user.ID != authorID {
-			candidates = append(candidates, user.ID)
-		}
-	}
-
-	var reviewers []string
-	if len(candidates) <= 2 {
-		reviewers = candidates
+	status := models.PRStatusOpen
+	reviewers := []string{}
+	var fallback []string
+	if draft {
+		status = models.PRStatusDraft
 	} else {
-		idx1 := rand.Intn(len(candidates))
-		idx2 := rand.Intn(len(candidates))
-		for idx1 == idx2 {
-			idx2 = rand.Intn(len(candidates))
+		reviewers, fallback, err = s.pickReviewers(ctx, id, user, repositoryID, files)
+		if err != nil {
+			return nil, err
 		}
-		reviewers = []string{candidates[idx1], candidates[idx2]}
 	}
 
 	pr := &models.PullRequest{
-		ID:        id,
-		Name:      name,
-		AuthorID:  authorID,
-		Status:    "OPEN",
-		CreatedAt: time.Now(),
-		Reviewers: reviewers,
+		ID:           id,
+		Name:         name,
+		AuthorID:     authorID,
+		Status:       status,
+		CreatedAt:    time.Now(),
+		Reviewers:    reviewers,
+		Files:        files,
+		RepositoryID: repositoryID,
+
+		FallbackReviewers: fallback,
 	}
 
-	if err := s.prRepo.CreatePR(ctx, pr); err != nil {
+	events := []models.Event{models.NewEvent(models.EventPRCreated, models.EventPayload{
+		PRID:        pr.ID,
+		PRName:      pr.Name,
+		AuthorID:    pr.AuthorID,
+		ReviewerIDs: pr.Reviewers,
+	})}
+	events = append(events, assignedEvents(pr, reviewers, fallback)...)
+
+	if err := s.prRepo.CreatePR(ctx, pr, events...); err != nil {
 		if errors.Is(err, models.ErrAlreadyExists) {
 			s.log.WarnContext(ctx, "pr already exists", "pr_id", id)
 		} else {
@@ -91,11 +116,44 @@ func (s *PullRequestService) CreatePullRequest(ctx context.Context, id, name, au
 
 func (s *PullRequestService) MergePullRequest(ctx context.Context, id string) (*models.PullRequest, error) {
 	s.log.InfoContext(ctx, "merging PR", "PR_id", id)
-	pr, err := s.prRepo.MergePR(ctx, id)
+	// The status and approvals are checked in the merge transaction, so a review
+	// submitted concurrently is either seen by the check or waits for the merge.
+	return s.merge(ctx, id, s.checkMergeable)
+}
+
+func (s *PullRequestService) RecordUpstreamMerge(ctx context.Context, id string) (*models.PullRequest, error) {
+	s.log.InfoContext(ctx, "recording upstream merge", "pr_id", id)
+	return s.merge(ctx, id, nil)
+}
+
+// merge stores the merge if guard allows it; the pr.merged event is written only
+// for a PR that was not merged yet.
+func (s *PullRequestService) merge(ctx context.Context, id string, guard repository.MergeGuard) (*models.PullRequest, error) {
+	current, err := s.prRepo.GetByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, models.ErrNotFound) {
 			s.log.WarnContext(ctx, "pr not found", "pr_id", id)
 		} else {
+			s.log.ErrorContext(ctx, "failed to get pr", "err", err)
+		}
+		return nil, err
+	}
+
+	var events []models.Event
+	if current.Status != models.PRStatusMerged {
+		events = append(events, models.NewEvent(models.EventPRMerged, models.EventPayload{
+			PRID:        current.ID,
+			PRName:      current.Name,
+			AuthorID:    current.AuthorID,
+			ReviewerIDs: current.Reviewers,
+		}))
+	}
+
+	pr, err := s.prRepo.MergePR(ctx, id, guard, events...)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			s.log.WarnContext(ctx, "pr not found", "pr_id", id)
+		} else if !errors.Is(err, models.ErrNotApproved) && !errors.Is(err, models.ErrInvalidStatus) {
 			s.log.ErrorContext(ctx, "failed to merge pr", "err", err)
 		}
 		return nil, err
@@ -103,33 +161,107 @@ func (s *PullRequestService) MergePullRequest(ctx context.Context, id string) (*
 	return pr, nil
 }
 
-func (s *PullRequestService) ReassignReviewer(ctx context.Context, prID, oldUserID string) (*models.PullRequest, string, error) {
-	s.log.InfoContext(ctx, "reassigning reviewer", "pr_id", prID, "old_reviewer", oldUserID)
+// ReassignReviewer replaces oldUserID with newUserID, or with a randomly selected
+// candidate when newUserID is empty. Each read-decide-write sequence runs in one
+// transaction. The replacement is stored only if the PR did not change since it
+// was read; otherwise the sequence is retried, up to maxReassignAttempts times.
+func (s *PullRequestService) ReassignReviewer(
+	ctx context.Context,
+	prID, oldUserID, newUserID string,
+) (*models.PullRequest, string, error) {
+	s.log.InfoContext(ctx, "reassigning reviewer", "pr_id", prID, "old_reviewer", oldUserID, "new_reviewer", newUserID)
 
-	pr, err := s.prRepo.GetByID(ctx, prID)
-	if err != nil {
-		return nil, "", err
+	for attempt := 1; ; attempt++ {
+		var (
+			pr            *models.PullRequest
+			newReviewerID string
+			fromFallback  bool
+		)
+		err := s.txm.WithinTx(ctx, func(ctx context.Context) error {
+			var err error
+			pr, err = s.prRepo.GetByID(ctx, prID)
+			if err != nil {
+				return err
+			}
+
+			newReviewerID, fromFallback, err = s.decideReassign(ctx, pr, oldUserID, newUserID)
+			if err != nil {
+				return err
+			}
+
+			payload := models.EventPayload{
+				PRID:          pr.ID,
+				PRName:        pr.Name,
+				AuthorID:      pr.AuthorID,
+				OldReviewerID: oldUserID,
+				NewReviewerID: newReviewerID,
+			}
+			if fromFallback {
+				payload.FallbackReviewerIDs = []string{newReviewerID}
+			}
+			event := models.NewEvent(models.EventReviewerReassigned, payload)
+
+			return s.prRepo.ReassignReviewer(ctx, pr, oldUserID, newReviewerID, fromFallback, event)
+		})
+		if errors.Is(err, models.ErrPRChanged) && attempt < maxReassignAttempts {
+			s.log.InfoContext(ctx, "pr changed during reassign, retrying", "pr_id", prID, "attempt", attempt)
+			continue
+		}
+		if err != nil {
+			return nil, "", err
+		}
+
+		for i, r := range pr.Reviewers {
+			if r == oldUserID {
+				pr.Reviewers[i] = newReviewerID
+				break
+			}
+		}
+		pr.FallbackReviewers = slices.DeleteFunc(pr.FallbackReviewers, func(id string) bool { return id == oldUserID })
+		if fromFallback {
+			pr.FallbackReviewers = append(pr.FallbackReviewers, newReviewerID)
+		}
+
+		return pr, newReviewerID, nil
 	}
+}
 
-	if pr.Status == "MERGED" {
-		s.log.WarnContext(ctx, "cannot reassign on merged pr", "pr_id", prID)
-		return nil, "", models.ErrPRMerged
+// decideReassign checks that oldUserID may be replaced on pr and chooses the
+// replacement. It reports whether the replacement comes from a fallback team.
+func (s *PullRequestService) decideReassign(
+	ctx context.Context,
+	pr *models.PullRequest,
+	oldUserID, newUserID string,
+) (string, bool, error) {
+	if pr.Status == models.PRStatusMerged {
+		s.log.WarnContext(ctx, "cannot reassign on merged pr", "pr_id", pr.ID)
+		return "", false, models.ErrPRMerged
+	}
+
+	if pr.Status != models.PRStatusOpen {
+		s.log.WarnContext(ctx, "cannot reassign on not open pr", "pr_id", pr.ID, "status", pr.Status)
+		return "", false, models.ErrInvalidStatus
 	}
 
 	isReviewer := slices.Contains(pr.Reviewers, oldUserID)
 	if !isReviewer {
-		s.log.WarnContext(ctx, "user is not a reviewer", "pr_id", prID, "user_id", oldUserID)
-		return nil, "", models.ErrNotAssigned
+		s.log.WarnContext(ctx, "user is not a reviewer", "pr_id", pr.ID, "user_id", oldUserID)
+		return "", false, models.ErrNotAssigned
 	}
 
 	author, err := s.userRepo.GetUser(ctx, pr.AuthorID)
 	if err != nil {
-		return nil, "", err
+		return "", false, err
 	}
 
-	activeUsers, err := s.userRepo.GetActiveUsersByTeam(ctx, author.TeamName)
+	scope, err := s.scopes.scopeFor(ctx, author, pr.RepositoryID)
 	if err != nil {
-		return nil, "", err
+		return "", false, err
+	}
+
+	activeUsers, err := s.scopes.candidates(ctx, scope)
+	if err != nil {
+		return "", false, err
 	}
 
 	currentReviewersMap := make(map[string]bool)
@@ -137,7 +269,7 @@ func (s *PullRequestService) ReassignReviewer(ctx context.Context, prID, oldUser
 		currentReviewersMap[r] = true
 	}
 
-	candidates := make([]string, 0, len(activeUsers))
+	candidates := make([]models.User, 0, len(activeUsers))
 	for _, u := range activeUsers {
 		if u.ID == pr.AuthorID {
 			continue
@@ -148,28 +280,433 @@ func (s *PullRequestService) ReassignReviewer(ctx context.Context, prID, oldUser
 		if currentReviewersMap[u.ID] {
 			continue
 		}
-		candidates = append(candidates, u.ID)
+		candidates = append(candidates, u)
+	}
+
+	var newReviewerID string
+	fromFallback := false
+	if newUserID != "" {
+		fromFallback, err = s.checkReplacement(ctx, scope, pr, candidates, newUserID)
+		if err != nil {
+			return "", false, err
+		}
+		newReviewerID = newUserID
+	} else {
+		selected, err := s.selector.Select(ctx, scope.teamName, candidates, 1)
+		if err != nil {
+			s.log.ErrorContext(ctx, "failed to select reviewer", "err", err)
+			return "", false, err
+		}
+
+		if len(selected) == 0 {
+			selected, err = s.scopes.fallbackReviewers(ctx, scope, pr.AuthorID, pr.Reviewers, 1)
+			if err != nil {
+				s.log.ErrorContext(ctx, "failed to select fallback reviewer", "err", err)
+				return "", false, err
+			}
+			fromFallback = true
+		}
+
+		if len(selected) == 0 {
+			s.log.WarnContext(ctx, "no candidates for reassign", "pr_id", pr.ID)
+			return "", false, models.ErrNoCandidates
+		}
+		newReviewerID = selected[0]
+	}
+
+	return newReviewerID, fromFallback, nil
+}
+
+// checkReplacement verifies that userID could be chosen automatically: it is one
+// of the home candidates or an available member of a fallback team. It reports
+// whether the user comes from a fallback team.
+func (s *PullRequestService) checkReplacement(
+	ctx context.Context,
+	scope *reviewScope,
+	pr *models.PullRequest,
+	candidates []models.User,
+	userID string,
+) (bool, error) {
+	user, err := s.userRepo.GetUser(ctx, userID)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			s.log.WarnContext(ctx, "user not found", "user_id", userID)
+		}
+		return false, err
+	}
+
+	switch {
+	case userID == pr.AuthorID:
+		s.log.WarnContext(ctx, "author cannot be a reviewer", "pr_id", pr.ID, "user_id", userID)
+		return false, models.ErrAuthorReviewer
+	case slices.Contains(pr.Reviewers, userID):
+		s.log.WarnContext(ctx, "reviewer already assigned", "pr_id", pr.ID, "user_id", userID)
+		return false, models.ErrAlreadyAssigned
+	case !user.IsActive:
+		s.log.WarnContext(ctx, "reviewer is not active", "pr_id", pr.ID, "user_id", userID)
+		return false, models.ErrReviewerInactive
+	}
+
+	isUser := func(u models.User) bool { return u.ID == userID }
+	if slices.ContainsFunc(candidates, isUser) {
+		return false, nil
+	}
+	if slices.Contains(scope.settings.FallbackTeams, user.TeamName) {
+		available, err := s.scopes.availableUsers(ctx, user.TeamName, scope.settings)
+		if err != nil {
+			return false, err
+		}
+		if slices.ContainsFunc(available, isUser) {
+			return true, nil
+		}
+	}
+
+	s.log.WarnContext(ctx, "user cannot review pr", "pr_id", pr.ID, "user_id", userID, "team_name", scope.teamName)
+	return false, models.ErrNotEligible
+}
+
+// AddReviewer assigns a specific active user who is neither the author nor
+// already a reviewer and is not absent during the review window of the PR.
+func (s *PullRequestService) AddReviewer(ctx context.Context, prID, userID string) (*models.PullRequest, error) {
+	s.log.InfoContext(ctx, "adding reviewer", "pr_id", prID, "user_id", userID)
+
+	pr, err := s.editableReviewers(ctx, prID)
+	if err != nil {
+		return nil, err
+	}
+
+	if userID == pr.AuthorID {
+		s.log.WarnContext(ctx, "author cannot be a reviewer", "pr_id", prID, "user_id", userID)
+		return nil, models.ErrAuthorReviewer
+	}
+	if slices.Contains(pr.Reviewers, userID) {
+		s.log.WarnContext(ctx, "reviewer already assigned", "pr_id", prID, "user_id", userID)
+		return nil, models.ErrAlreadyAssigned
+	}
+
+	user, err := s.userRepo.GetUser(ctx, userID)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			s.log.WarnContext(ctx, "user not found", "user_id", userID)
+		} else {
+			s.log.ErrorContext(ctx, "failed to get user", "err", err)
+		}
+		return nil, err
+	}
+	if !user.IsActive {
+		s.log.WarnContext(ctx, "reviewer is not active", "pr_id", prID, "user_id", userID)
+		return nil, models.ErrReviewerInactive
 	}
 
-	if len(candidates) == 0 {
-		s.log.WarnContext(ctx, "no candidates for reassign", "pr_id", prID)
-		return nil, "", models.ErrNoCandidates
+	author, err := s.userRepo.GetUser(ctx, pr.AuthorID)
+	if err != nil {
+		s.log.ErrorContext(ctx, "failed to get author", "err", err)
+		return nil, err
+	}
+	scope, err := s.scopes.scopeFor(ctx, author, pr.RepositoryID)
+	if err != nil {
+		s.log.ErrorContext(ctx, "failed to resolve review scope", "err", err)
+		return nil, err
+	}
+	available, err := s.scopes.isAvailable(ctx, user, scope.settings)
+	if err != nil {
+		s.log.ErrorContext(ctx, "failed to check reviewer availability", "err", err)
+		return nil, err
+	}
+	if !available {
+		s.log.WarnContext(ctx, "reviewer is absent", "pr_id", prID, "user_id", userID)
+		return nil, models.ErrNotEligible
+	}
+
+	event := models.NewEvent(models.EventReviewerAssigned, models.EventPayload{
+		PRID:        pr.ID,
+		PRName:      pr.Name,
+		AuthorID:    pr.AuthorID,
+		ReviewerIDs: []string{userID},
+	})
+	if err := s.prRepo.AddReviewer(ctx, prID, userID, event); err != nil {
+		s.logReviewerChangeError(ctx, prID, err)
+		return nil, err
+	}
+
+	pr.Reviewers = append(pr.Reviewers, userID)
+	return pr, nil
+}
+
+// RemoveReviewer unassigns a reviewer without picking a replacement.
+func (s *PullRequestService) RemoveReviewer(ctx context.Context, prID, userID string) (*models.PullRequest, error) {
+	s.log.InfoContext(ctx, "removing reviewer", "pr_id", prID, "user_id", userID)
+
+	pr, err := s.editableReviewers(ctx, prID)
+	if err != nil {
+		return nil, err
 	}
 
-	newReviewerID := candidates[rand.Intn(len(candidates))]
+	if !slices.Contains(pr.Reviewers, userID) {
+		s.log.WarnContext(ctx, "user is not a reviewer", "pr_id", prID, "user_id", userID)
+		return nil, models.ErrNotAssigned
+	}
 
-	if err := s.prRepo.ReassignReviewer(ctx, prID, oldUserID, newReviewerID); err != nil {
-		return nil, "", err
+	event := models.NewEvent(models.EventReviewerRemoved, models.EventPayload{
+		PRID:          pr.ID,
+		PRName:        pr.Name,
+		AuthorID:      pr.AuthorID,
+		OldReviewerID: userID,
+	})
+	if err := s.prRepo.RemoveReviewer(ctx, prID, userID, event); err != nil {
+		s.logReviewerChangeError(ctx, prID, err)
+		return nil, err
 	}
 
-	for i, r := range pr.Reviewers {
-		if r == oldUserID {
-			pr.Reviewers[i] = newReviewerID
-			break
+	isUser := func(reviewerID string) bool { return reviewerID == userID }
+	pr.Reviewers = slices.DeleteFunc(pr.Reviewers, isUser)
+	pr.FallbackReviewers = slices.DeleteFunc(pr.FallbackReviewers, isUser)
+	return pr, nil
+}
+
+// editableReviewers returns the PR if its reviewers may be changed by hand: it
+// is OPEN or DRAFT.
+func (s *PullRequestService) editableReviewers(ctx context.Context, prID string) (*models.PullRequest, error) {
+	pr, err := s.prRepo.GetByID(ctx, prID)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			s.log.WarnContext(ctx, "pr not found", "pr_id", prID)
+		} else {
+			s.log.ErrorContext(ctx, "failed to get pr", "err", err)
 		}
+		return nil, err
 	}
 
-	return pr, newReviewerID, nil
+	if err := pr.Status.ReviewersEditable(); err != nil {
+		s.log.WarnContext(ctx, "cannot change reviewers", "pr_id", prID, "status", pr.Status)
+		return nil, err
+	}
+	return pr, nil
+}
+
+// logReviewerChangeError logs a failed reviewer change. The repository rechecks
+// the status under a lock, so a PR merged or closed meanwhile is not a failure.
+func (s *PullRequestService) logReviewerChangeError(ctx context.Context, prID string, err error) {
+	if errors.Is(err, models.ErrPRMerged) || errors.Is(err, models.ErrInvalidStatus) {
+		s.log.WarnContext(ctx, "cannot change reviewers", "pr_id", prID, "err", err)
+		return
+	}
+	s.log.ErrorContext(ctx, "failed to change reviewers", "pr_id", prID, "err", err)
+}
+
+func (s *PullRequestService) ClosePullRequest(ctx context.Context, id string) (*models.PullRequest, error) {
+	s.log.InfoContext(ctx, "closing PR", "pr_id", id)
+	return s.changeStatus(ctx, id, models.PRStatusClosed, models.PRStatusOpen, models.PRStatusDraft)
+}
+
+func (s *PullRequestService) ReopenPullRequest(ctx context.Context, id string) (*models.PullRequest, error) {
+	s.log.InfoContext(ctx, "reopening PR", "pr_id", id)
+	return s.changeStatus(ctx, id, models.PRStatusOpen, models.PRStatusClosed)
+}
+
+func (s *PullRequestService) MarkReady(ctx context.Context, id string) (*models.PullRequest, error) {
+	s.log.InfoContext(ctx, "marking PR ready for review", "pr_id", id)
+	return s.changeStatus(ctx, id, models.PRStatusOpen, models.PRStatusDraft)
+}
+
+// changeStatus moves the PR to status if its current status is one of from.
+// A PR that becomes OPEN without reviewers gets them assigned.
+func (s *PullRequestService) changeStatus(
+	ctx context.Context,
+	id string,
+	status models.PRStatus,
+	from ...models.PRStatus,
+) (*models.PullRequest, error) {
+	pr, err := s.prRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			s.log.WarnContext(ctx, "pr not found", "pr_id", id)
+		} else {
+			s.log.ErrorContext(ctx, "failed to get pr", "err", err)
+		}
+		return nil, err
+	}
+
+	if err := pr.Status.TransitionFrom(from); err != nil {
+		s.log.WarnContext(ctx, "invalid status transition", "pr_id", id, "from", pr.Status, "to", status)
+		return nil, err
+	}
+
+	var reviewers, fallback []string
+	if status == models.PRStatusOpen && len(pr.Reviewers) == 0 {
+		author, err := s.userRepo.GetUser(ctx, pr.AuthorID)
+		if err != nil {
+			s.log.ErrorContext(ctx, "failed to get author", "err", err)
+			return nil, err
+		}
+
+		reviewers, fallback, err = s.pickReviewers(ctx, id, author, pr.RepositoryID, pr.Files)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	events := assignedEvents(pr, reviewers, fallback)
+	updated, err := s.prRepo.UpdateStatus(ctx, id, status, from, reviewers, fallback, events...)
+	if err != nil {
+		if errors.Is(err, models.ErrPRMerged) || errors.Is(err, models.ErrInvalidStatus) {
+			s.log.WarnContext(ctx, "pr status changed concurrently", "pr_id", id, "to", status)
+		} else {
+			s.log.ErrorContext(ctx, "failed to update pr status", "pr_id", id, "err", err)
+		}
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+func assignedEvents(pr *models.PullRequest, reviewers, fallback []string) []models.Event {
+	if len(reviewers) == 0 {
+		return nil
+	}
+	return []models.Event{models.NewEvent(models.EventReviewerAssigned, models.EventPayload{
+		PRID:                pr.ID,
+		PRName:              pr.Name,
+		AuthorID:            pr.AuthorID,
+		ReviewerIDs:         reviewers,
+		FallbackReviewerIDs: fallback,
+	})}
+}
+
+// pickReviewers selects available code owners of files first, fills the
+// remaining seats from the review scope of the repository and then from the
+// fallback teams. The second result lists reviewers from fallback teams.
+func (s *PullRequestService) pickReviewers(
+	ctx context.Context,
+	prID string,
+	author *models.User,
+	repositoryID string,
+	files []string,
+) ([]string, []string, error) {
+	scope, err := s.scopes.scopeFor(ctx, author, repositoryID)
+	if err != nil {
+		s.log.ErrorContext(ctx, "failed to get review scope", "err", err)
+		return nil, nil, err
+	}
+	settings := scope.settings
+
+	owners, err := s.codeOwners(ctx, author, settings, repositoryID, files)
+	if err != nil {
+		s.log.ErrorContext(ctx, "failed to resolve code owners", "err", err)
+		return nil, nil, err
+	}
+
+	reviewers, err := s.selector.Select(ctx, scope.teamName, owners, settings.ReviewersCount)
+	if err != nil {
+		s.log.ErrorContext(ctx, "failed to select code owners", "err", err)
+		return nil, nil, err
+	}
+
+	if len(reviewers) < settings.ReviewersCount {
+		activeUsers, err := s.scopes.candidates(ctx, scope)
+		if err != nil {
+			s.log.ErrorContext(ctx, "failed to get active users", "err", err)
+			return nil, nil, err
+		}
+
+		candidates := make([]models.User, 0, len(activeUsers))
+		for _, user := range activeUsers {
+			if user.ID != author.ID && !slices.Contains(reviewers, user.ID) {
+				candidates = append(candidates, user)
+			}
+		}
+
+		rest, err := s.selector.Select(ctx, scope.teamName, candidates, settings.ReviewersCount-len(reviewers))
+		if err != nil {
+			s.log.ErrorContext(ctx, "failed to select reviewers", "err", err)
+			return nil, nil, err
+		}
+		reviewers = append(reviewers, rest...)
+	}
+
+	var fallback []string
+	if len(reviewers) < settings.ReviewersCount {
+		fallback, err = s.scopes.fallbackReviewers(ctx, scope, author.ID, reviewers, settings.ReviewersCount-len(reviewers))
+		if err != nil {
+			s.log.ErrorContext(ctx, "failed to select fallback reviewers", "err", err)
+			return nil, nil, err
+		}
+		reviewers = append(reviewers, fallback...)
+	}
+
+	if len(reviewers) < settings.MinReviewers {
+		s.log.WarnContext(ctx, "not enough reviewers", "pr_id", prID,
+			"available", len(reviewers), "min_reviewers", settings.MinReviewers)
+		return nil, nil, models.ErrNotEnoughReviewers
+	}
+
+	return reviewers, fallback, nil
+}
+
+// codeOwners returns available owners of files other than the author. Owner
+// teams contribute all their available members. Repositories without their own
+// rules use the default ones.
+func (s *PullRequestService) codeOwners(
+	ctx context.Context,
+	author *models.User,
+	settings *models.TeamSettings,
+	repositoryID string,
+	files []string,
+) ([]models.User, error) {
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	rules, err := s.ownerRepo.GetRules(ctx, repositoryID)
+	if err != nil {
+		return nil, err
+	}
+	if len(rules) == 0 && repositoryID != "" {
+		if rules, err = s.ownerRepo.GetRules(ctx, ""); err != nil {
+			return nil, err
+		}
+	}
+	userIDs, teamNames, err := codeowners.Owners(rules, files)
+	if err != nil {
+		return nil, err
+	}
+
+	ownerUsers := make(map[string]bool, len(userIDs))
+	ownerTeams := make(map[string]bool, len(teamNames))
+	for _, name := range teamNames {
+		ownerTeams[name] = true
+	}
+
+	// Individual owners are looked up through their team to respect availability.
+	lookup := slices.Clone(teamNames)
+	for _, id := range userIDs {
+		ownerUsers[id] = true
+		user, err := s.userRepo.GetUser(ctx, id)
+		if err != nil {
+			if errors.Is(err, models.ErrNotFound) {
+				s.log.WarnContext(ctx, "unknown code owner", "user_id", id)
+				continue
+			}
+			return nil, err
+		}
+		if !slices.Contains(lookup, user.TeamName) {
+			lookup = append(lookup, user.TeamName)
+		}
+	}
+
+	var result []models.User
+	for _, teamName := range lookup {
+		available, err := s.scopes.availableUsers(ctx, teamName, settings)
+		if err != nil {
+			return nil, err
+		}
+		for _, user := range available {
+			if user.ID != author.ID && (ownerTeams[teamName] || ownerUsers[user.ID]) {
+				result = append(result, user)
+			}
+		}
+	}
+	return result, nil
 }
 
 func (s *PullRequestService) GetReviewerPRs(ctx context.Context, userID string) ([]*models.PullRequestShort, error) {
@@ -185,3 +722,162 @@ func (s *PullRequestService) GetReviewerPRs(ctx context.Context, userID string)
 
 	return prs, nil
 }
+
+func (s *PullRequestService) SubmitReview(ctx context.Context, prID, reviewerID, verdict string) (*models.Review, error) {
+	s.log.InfoContext(ctx, "submitting review", "pr_id", prID, "reviewer_id", reviewerID, "verdict", verdict)
+
+	switch verdict {
+	case models.VerdictApproved, models.VerdictChangesRequested, models.VerdictCommented:
+	default:
+		s.log.WarnContext(ctx, "invalid verdict", "verdict", verdict)
+		return nil, models.ErrInvalidVerdict
+	}
+
+	pr, err := s.prRepo.GetByID(ctx, prID)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			s.log.WarnContext(ctx, "pr not found", "pr_id", prID)
+		} else {
+			s.log.ErrorContext(ctx, "failed to get pr", "err", err)
+		}
+		return nil, err
+	}
+
+	if pr.Status == models.PRStatusMerged {
+		s.log.WarnContext(ctx, "cannot review merged pr", "pr_id", prID)
+		return nil, models.ErrPRMerged
+	}
+
+	if pr.Status != models.PRStatusOpen {
+		s.log.WarnContext(ctx, "cannot review not open pr", "pr_id", prID, "status", pr.Status)
+		return nil, models.ErrInvalidStatus
+	}
+
+	if !slices.Contains(pr.Reviewers, reviewerID) {
+		s.log.WarnContext(ctx, "user is not a reviewer", "pr_id", prID, "user_id", reviewerID)
+		return nil, models.ErrNotAssigned
+	}
+
+	review := &models.Review{
+		PRID:        prID,
+		ReviewerID:  reviewerID,
+		Verdict:     verdict,
+		SubmittedAt: time.Now(),
+	}
+
+	if err := s.prRepo.AddReview(ctx, review); err != nil {
+		s.log.ErrorContext(ctx, "failed to save review", "err", err)
+		return nil, err
+	}
+
+	return review, nil
+}
+
+func (s *PullRequestService) GetPullRequest(ctx context.Context, id string) (*models.PullRequest, error) {
+	pr, err := s.prRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			s.log.WarnContext(ctx, "pr not found", "pr_id", id)
+		} else {
+			s.log.ErrorContext(ctx, "failed to get pr", "err", err)
+		}
+		return nil, err
+	}
+	return pr, nil
+}
+
+func (s *PullRequestService) GetReviews(ctx context.Context, prID string) ([]models.Review, error) {
+	if _, err := s.prRepo.GetByID(ctx, prID); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			s.log.WarnContext(ctx, "pr not found", "pr_id", prID)
+		} else {
+			s.log.ErrorContext(ctx, "failed to get pr", "err", err)
+		}
+		return nil, err
+	}
+
+	reviews, err := s.prRepo.GetReviews(ctx, prID)
+	if err != nil {
+		s.log.ErrorContext(ctx, "failed to get reviews", "pr_id", prID, "err", err)
+		return nil, err
+	}
+
+	if reviews == nil {
+		return []models.Review{}, nil
+	}
+
+	return reviews, nil
+}
+
+func (s *PullRequestService) GetOverdueReviews(ctx context.Context, teamName string) ([]models.OverdueReview, error) {
+	overdue, err := s.prRepo.GetOverdue(ctx, teamName)
+	if err != nil {
+		s.log.ErrorContext(ctx, "failed to get overdue reviews", "team_name", teamName, "err", err)
+		return nil, err
+	}
+
+	if overdue == nil {
+		return []models.OverdueReview{}, nil
+	}
+
+	return overdue, nil
+}
+
+// checkMergeable lets merged PRs through, so merging again is a no-op.
+func (s *PullRequestService) checkMergeable(ctx context.Context, pr *models.PullRequest) error {
+	switch pr.Status {
+	case models.PRStatusMerged:
+		return nil
+	case models.PRStatusOpen:
+		return s.checkApprovals(ctx, pr)
+	default:
+		s.log.WarnContext(ctx, "cannot merge pr", "pr_id", pr.ID, "status", pr.Status)
+		return models.ErrInvalidStatus
+	}
+}
+
+// checkApprovals counts assigned reviewers whose latest verdict is APPROVED.
+func (s *PullRequestService) checkApprovals(ctx context.Context, pr *models.PullRequest) error {
+	author, err := s.userRepo.GetUser(ctx, pr.AuthorID)
+	if err != nil {
+		s.log.ErrorContext(ctx, "failed to get author", "err", err)
+		return err
+	}
+
+	scope, err := s.scopes.scopeFor(ctx, author, pr.RepositoryID)
+	if err != nil {
+		s.log.ErrorContext(ctx, "failed to get review scope", "err", err)
+		return err
+	}
+	settings := scope.settings
+
+	if settings.RequiredApprovals == 0 {
+		return nil
+	}
+
+	reviews, err := s.prRepo.GetReviews(ctx, pr.ID)
+	if err != nil {
+		s.log.ErrorContext(ctx, "failed to get reviews", "err", err)
+		return err
+	}
+
+	latest := make(map[string]string)
+	for _, rev := range reviews {
+		latest[rev.ReviewerID] = rev.Verdict
+	}
+
+	approvals := 0
+	for _, reviewerID := range pr.Reviewers {
+		if latest[reviewerID] == models.VerdictApproved {
+			approvals++
+		}
+	}
+
+	if approvals < settings.RequiredApprovals {
+		s.log.WarnContext(ctx, "not enough approvals", "pr_id", pr.ID,
+			"approvals", approvals, "required", settings.RequiredApprovals)
+		return models.ErrNotApproved
+	}
+
+	return nil
+}
@@ -5,12 +5,49 @@ import (
 	"errors"
 	"log/slog"
 	"os"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/repository"
 	"github.com/yohnnn/pr_reviewer_assignment_service/internal/repository/inmemory"
 )
 
+// racingPRRepo runs change right before the first reassignment or merge is stored,
+// like a concurrent request would.
+type racingPRRepo struct {
+	*inmemory.TestPRRepo
+	change func()
+}
+
+func (r *racingPRRepo) ReassignReviewer(
+	ctx context.Context,
+	snapshot *models.PullRequest,
+	oldID, newID string,
+	fromFallback bool,
+	events ...models.Event,
+) error {
+	if r.change != nil {
+		r.change()
+		r.change = nil
+	}
+	return r.TestPRRepo.ReassignReviewer(ctx, snapshot, oldID, newID, fromFallback, events...)
+}
+
+func (r *racingPRRepo) MergePR(
+	ctx context.Context,
+	id string,
+	guard repository.MergeGuard,
+	events ...models.Event,
+) (*models.PullRequest, error) {
+	if r.change != nil {
+		r.change()
+		r.change = nil
+	}
+	return r.TestPRRepo.MergePR(ctx, id, guard, events...)
+}
+
 func TestPullRequestService_Simple(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 
@@ -20,13 +57,22 @@ func TestPullRequestService_Simple(t *testing.T) {
 	userRepo.Users["u3"] = &models.User{ID: "u3", TeamName: "backend", IsActive: true}
 	userRepo.Users["u4"] = &models.User{ID: "u4", TeamName: "backend", IsActive: true}
 
+	teamRepo := inmemory.NewTestTeamRepo()
+	teamRepo.Teams["backend"] = &models.Team{Name: "backend"}
+
 	prRepo := inmemory.NewTestPRRepo()
 
-	service := NewPullRequestService(prRepo, userRepo, logger)
+	repoRepo := inmemory.NewTestRepositoryRepo()
+
+	ownerRepo := inmemory.NewTestCodeOwnerRepo()
+
+	txm := inmemory.NewTxManager(inmemory.NewStore())
+
+	service := NewPullRequestService(prRepo, userRepo, teamRepo, repoRepo, ownerRepo, txm, NewRandomSelector(), logger)
 	ctx := context.Background()
 
 	t.Run("Create PR", func(t *testing.T) {
-		pr, err := service.CreatePullRequest(ctx, "pr-1", "Fix bug", "u1")
+		pr, err := service.CreatePullRequest(ctx, "pr-1", "Fix bug", "u1", "", false, nil)
 
 		if err != nil {
 			t.Fatalf("Unexpected error: %v", err)
@@ -48,7 +94,7 @@ func TestPullRequestService_Simple(t *testing.T) {
 		pr, _ := prRepo.GetByID(ctx, "pr-1")
 		oldRev := pr.Reviewers[0]
 
-		prUpdated, newRev, err := service.ReassignReviewer(ctx, "pr-1", oldRev)
+		prUpdated, newRev, err := service.ReassignReviewer(ctx, "pr-1", oldRev, "")
 
 		if err != nil {
 			t.Fatalf("Reassign failed: %v", err)
@@ -68,7 +114,77 @@ func TestPullRequestService_Simple(t *testing.T) {
 		}
 	})
 
+	t.Run("Merge requires approvals", func(t *testing.T) {
+		teamRepo.Settings["backend"] = &models.TeamSettings{ReviewersCount: 2, RequiredApprovals: 1}
+		defer delete(teamRepo.Settings, "backend")
+
+		_, err := service.MergePullRequest(ctx, "pr-1")
+		if !errors.Is(err, models.ErrNotApproved) {
+			t.Fatalf("Expected ErrNotApproved, got %v", err)
+		}
+
+		_, err = service.SubmitReview(ctx, "pr-1", "u1", models.VerdictApproved)
+		if !errors.Is(err, models.ErrNotAssigned) {
+			t.Errorf("Expected ErrNotAssigned for author, got %v", err)
+		}
+
+		_, err = service.SubmitReview(ctx, "pr-1", "u2", "LGTM")
+		if !errors.Is(err, models.ErrInvalidVerdict) {
+			t.Errorf("Expected ErrInvalidVerdict, got %v", err)
+		}
+
+		pr, _ := prRepo.GetByID(ctx, "pr-1")
+		reviewer := pr.Reviewers[0]
+
+		if _, err := service.SubmitReview(ctx, "pr-1", reviewer, models.VerdictChangesRequested); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := service.MergePullRequest(ctx, "pr-1"); !errors.Is(err, models.ErrNotApproved) {
+			t.Errorf("Expected ErrNotApproved after changes requested, got %v", err)
+		}
+
+		if _, err := service.SubmitReview(ctx, "pr-1", reviewer, models.VerdictApproved); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		reviews, _ := service.GetReviews(ctx, "pr-1")
+		if len(reviews) != 2 {
+			t.Errorf("Expected 2 reviews, got %d", len(reviews))
+		}
+	})
+
+	t.Run("Approvals are checked when the merge is stored", func(t *testing.T) {
+		teamRepo.Settings["backend"] = &models.TeamSettings{ReviewersCount: 2, RequiredApprovals: 1}
+		defer delete(teamRepo.Settings, "backend")
+
+		pr, err := service.CreatePullRequest(ctx, "pr-revoked", "Approval revoked", "u1", "", false, nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		reviewer := pr.Reviewers[0]
+		if _, err := service.SubmitReview(ctx, "pr-revoked", reviewer, models.VerdictApproved); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		racing := &racingPRRepo{TestPRRepo: prRepo, change: func() {
+			_ = prRepo.AddReview(ctx, &models.Review{
+				PRID: "pr-revoked", ReviewerID: reviewer, Verdict: models.VerdictChangesRequested, SubmittedAt: time.Now(),
+			})
+		}}
+		racingService := NewPullRequestService(racing, userRepo, teamRepo, repoRepo, ownerRepo, txm, NewRandomSelector(), logger)
+
+		if _, err := racingService.MergePullRequest(ctx, "pr-revoked"); !errors.Is(err, models.ErrNotApproved) {
+			t.Errorf("Expected ErrNotApproved for a revoked approval, got %v", err)
+		}
+		if stored, _ := prRepo.GetByID(ctx, "pr-revoked"); stored.Status != models.PRStatusOpen {
+			t.Errorf("Expected PR to stay OPEN, got %s", stored.Status)
+		}
+	})
+
 	t.Run("Merge PR", func(t *testing.T) {
+		teamRepo.Settings["backend"] = &models.TeamSettings{ReviewersCount: 2, RequiredApprovals: 1}
+		defer delete(teamRepo.Settings, "backend")
+
 		pr, err := service.MergePullRequest(ctx, "pr-1")
 
 		if err != nil {
@@ -87,14 +203,356 @@ func TestPullRequestService_Simple(t *testing.T) {
 		}
 	})
 
+	t.Run("Team reviewer count", func(t *testing.T) {
+		teamRepo.Settings["backend"] = &models.TeamSettings{ReviewersCount: 3, MinReviewers: 3}
+		defer delete(teamRepo.Settings, "backend")
+
+		pr, err := service.CreatePullRequest(ctx, "pr-3", "Three reviewers", "u1", "", false, nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(pr.Reviewers) != 3 {
+			t.Errorf("Expected 3 reviewers, got %d", len(pr.Reviewers))
+		}
+
+		userRepo.Users["u4"].IsActive = false
+		defer func() { userRepo.Users["u4"].IsActive = true }()
+
+		_, err = service.CreatePullRequest(ctx, "pr-4", "Too few reviewers", "u1", "", false, nil)
+		if !errors.Is(err, models.ErrNotEnoughReviewers) {
+			t.Errorf("Expected ErrNotEnoughReviewers, got %v", err)
+		}
+	})
+
+	t.Run("Draft, close and reopen", func(t *testing.T) {
+		pr, err := service.CreatePullRequest(ctx, "pr-draft", "WIP", "u1", "", true, nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if pr.Status != models.PRStatusDraft || len(pr.Reviewers) != 0 {
+			t.Fatalf("Expected DRAFT without reviewers, got %s with %v", pr.Status, pr.Reviewers)
+		}
+
+		if _, err := service.MergePullRequest(ctx, "pr-draft"); !errors.Is(err, models.ErrInvalidStatus) {
+			t.Errorf("Expected ErrInvalidStatus on draft merge, got %v", err)
+		}
+
+		pr, err = service.MarkReady(ctx, "pr-draft")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if pr.Status != models.PRStatusOpen || len(pr.Reviewers) != 2 {
+			t.Errorf("Expected OPEN with 2 reviewers, got %s with %v", pr.Status, pr.Reviewers)
+		}
+
+		if _, err := service.MarkReady(ctx, "pr-draft"); !errors.Is(err, models.ErrInvalidStatus) {
+			t.Errorf("Expected ErrInvalidStatus on second ready, got %v", err)
+		}
+
+		pr, err = service.ClosePullRequest(ctx, "pr-draft")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if pr.Status != models.PRStatusClosed {
+			t.Errorf("Expected CLOSED, got %s", pr.Status)
+		}
+
+		if _, _, err := service.ReassignReviewer(ctx, "pr-draft", pr.Reviewers[0], ""); !errors.Is(err, models.ErrInvalidStatus) {
+			t.Errorf("Expected ErrInvalidStatus on closed reassign, got %v", err)
+		}
+
+		pr, err = service.ReopenPullRequest(ctx, "pr-draft")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if pr.Status != models.PRStatusOpen {
+			t.Errorf("Expected OPEN, got %s", pr.Status)
+		}
+	})
+
 	t.Run("Reassign on MERGED PR", func(t *testing.T) {
 		pr, _ := prRepo.GetByID(ctx, "pr-1")
 		reviewer := pr.Reviewers[0]
 
-		_, _, err := service.ReassignReviewer(ctx, "pr-1", reviewer)
+		_, _, err := service.ReassignReviewer(ctx, "pr-1", reviewer, "")
 
 		if !errors.Is(err, models.ErrPRMerged) {
 			t.Errorf("Expected ErrPRMerged, got %v", err)
 		}
+
+		if _, err := service.ClosePullRequest(ctx, "pr-1"); !errors.Is(err, models.ErrPRMerged) {
+			t.Errorf("Expected ErrPRMerged on close, got %v", err)
+		}
+	})
+
+	t.Run("Events are written with the change", func(t *testing.T) {
+		prRepo.Events = nil
+		_, _ = service.CreatePullRequest(ctx, "pr-events", "Emit", "u1", "", false, nil)
+		_, _ = service.MergePullRequest(ctx, "pr-events")
+		_, _ = service.MergePullRequest(ctx, "pr-events")
+
+		want := []string{models.EventPRCreated, models.EventReviewerAssigned, models.EventPRMerged}
+		if len(prRepo.Events) != len(want) {
+			t.Fatalf("Expected %d events, got %+v", len(want), prRepo.Events)
+		}
+		for i, event := range prRepo.Events {
+			if event.Type != want[i] {
+				t.Errorf("Expected event %s, got %s", want[i], event.Type)
+			}
+			if event.Payload.PRID != "pr-events" {
+				t.Errorf("Unexpected payload: %+v", event.Payload)
+			}
+		}
+	})
+
+	t.Run("Code owners are picked first", func(t *testing.T) {
+		userRepo.Users["f1"] = &models.User{ID: "f1", TeamName: "frontend", IsActive: true}
+		userRepo.Users["f2"] = &models.User{ID: "f2", TeamName: "frontend", IsActive: true}
+		teamRepo.Settings["backend"] = &models.TeamSettings{ReviewersCount: 2}
+		ownerRepo.Rules[""] = []models.CodeOwnerRule{
+			{Pattern: "*.sql", Users: []string{"f1"}},
+			{Pattern: "/web/", Teams: []string{"frontend"}},
+		}
+
+		pr, err := service.CreatePullRequest(ctx, "pr-owned", "Migration", "u1", "", false, []string{"db/001.sql"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(pr.Reviewers) != 2 || pr.Reviewers[0] != "f1" || userRepo.Users[pr.Reviewers[1]].TeamName != "backend" {
+			t.Errorf("Expected f1 and a backend reviewer, got %v", pr.Reviewers)
+		}
+
+		pr, _ = service.CreatePullRequest(ctx, "pr-web", "UI", "u1", "", false, []string{"web/app.js"})
+		if len(pr.Reviewers) != 2 || userRepo.Users[pr.Reviewers[0]].TeamName != "frontend" ||
+			userRepo.Users[pr.Reviewers[1]].TeamName != "frontend" {
+			t.Errorf("Expected both frontend owners, got %v", pr.Reviewers)
+		}
+	})
+
+	t.Run("Repository rules replace the default ones", func(t *testing.T) {
+		ownerRepo.Rules["acme/api"] = []models.CodeOwnerRule{
+			{Pattern: "*.sql", Users: []string{"f2"}},
+		}
+
+		pr, err := service.CreatePullRequest(ctx, "pr-repo", "Migration", "u1", "acme/api", false, []string{"db/002.sql"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if pr.RepositoryID != "acme/api" || pr.Reviewers[0] != "f2" {
+			t.Errorf("Expected f2 from acme/api rules, got %+v", pr)
+		}
+
+		pr, _ = service.CreatePullRequest(ctx, "pr-other", "Migration", "u1", "acme/web", false, []string{"db/003.sql"})
+		if pr.Reviewers[0] != "f1" {
+			t.Errorf("Expected f1 from default rules, got %v", pr.Reviewers)
+		}
+	})
+
+	t.Run("Registered repository uses its pool and settings", func(t *testing.T) {
+		teamRepo.Teams["platform"] = &models.Team{Name: "platform"}
+		userRepo.Users["p1"] = &models.User{ID: "p1", TeamName: "platform", IsActive: true}
+		userRepo.Users["p2"] = &models.User{ID: "p2", TeamName: "platform", IsActive: true}
+		userRepo.Users["p3"] = &models.User{ID: "p3", TeamName: "platform", IsActive: true}
+		repoRepo.Repositories["acme/billing"] = &models.Repository{
+			ID:        "acme/billing",
+			TeamName:  "platform",
+			Reviewers: []string{"p2", "p3"},
+			Settings:  &models.RepositorySettings{ReviewersCount: 1, MinReviewers: 1, RequiredApprovals: 1},
+		}
+
+		pr, err := service.CreatePullRequest(ctx, "pr-billing", "Invoices", "u1", "acme/billing", false, nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(pr.Reviewers) != 1 || (pr.Reviewers[0] != "p2" && pr.Reviewers[0] != "p3") {
+			t.Fatalf("Expected one reviewer from the pool, got %v", pr.Reviewers)
+		}
+
+		_, newReviewer, err := service.ReassignReviewer(ctx, "pr-billing", pr.Reviewers[0], "")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if newReviewer != "p2" && newReviewer != "p3" {
+			t.Errorf("Expected replacement from the pool, got %s", newReviewer)
+		}
+
+		if _, err := service.MergePullRequest(ctx, "pr-billing"); !errors.Is(err, models.ErrNotApproved) {
+			t.Errorf("Expected ErrNotApproved from repository settings, got %v", err)
+		}
+
+		userRepo.Users["p2"].IsActive = false
+		userRepo.Users["p3"].IsActive = false
+		_, err = service.CreatePullRequest(ctx, "pr-billing-2", "Refunds", "u1", "acme/billing", false, nil)
+		if !errors.Is(err, models.ErrNotEnoughReviewers) {
+			t.Errorf("Expected ErrNotEnoughReviewers outside the pool, got %v", err)
+		}
+	})
+
+	t.Run("Fallback teams fill empty seats", func(t *testing.T) {
+		teamRepo.Teams["mobile"] = &models.Team{Name: "mobile"}
+		teamRepo.Settings["mobile"] = &models.TeamSettings{ReviewersCount: 2, FallbackTeams: []string{"platform", "backend"}}
+		userRepo.Users["m1"] = &models.User{ID: "m1", TeamName: "mobile", IsActive: true}
+		userRepo.Users["m2"] = &models.User{ID: "m2", TeamName: "mobile", IsActive: false}
+
+		pr, err := service.CreatePullRequest(ctx, "pr-mobile", "Push", "m1", "", false, nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(pr.Reviewers) != 2 || pr.Reviewers[0] != "p1" || userRepo.Users[pr.Reviewers[1]].TeamName != "backend" {
+			t.Fatalf("Expected p1 and a backend reviewer, got %v", pr.Reviewers)
+		}
+		if len(pr.FallbackReviewers) != 2 {
+			t.Errorf("Expected both reviewers flagged as fallback, got %v", pr.FallbackReviewers)
+		}
+		last := prRepo.Events[len(prRepo.Events)-1]
+		if last.Type != models.EventReviewerAssigned || len(last.Payload.FallbackReviewerIDs) != 2 {
+			t.Errorf("Expected fallback reviewers in the assigned event, got %+v", last)
+		}
+
+		pr, newReviewer, err := service.ReassignReviewer(ctx, "pr-mobile", "p1", "")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if userRepo.Users[newReviewer].TeamName != "backend" {
+			t.Errorf("Expected a backend replacement, got %s", newReviewer)
+		}
+		if slices.Contains(pr.FallbackReviewers, "p1") || !slices.Contains(pr.FallbackReviewers, newReviewer) {
+			t.Errorf("Unexpected fallback reviewers: %v", pr.FallbackReviewers)
+		}
+
+		userRepo.Users["m2"].IsActive = true
+		pr, newReviewer, err = service.ReassignReviewer(ctx, "pr-mobile", newReviewer, "")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if newReviewer != "m2" || slices.Contains(pr.FallbackReviewers, newReviewer) {
+			t.Errorf("Expected home reviewer m2 without flag, got %s, %v", newReviewer, pr.FallbackReviewers)
+		}
+	})
+
+	t.Run("Add and remove reviewers by hand", func(t *testing.T) {
+		userRepo.Users["u5"] = &models.User{ID: "u5", TeamName: "backend", IsActive: false}
+		userRepo.Users["u6"] = &models.User{ID: "u6", TeamName: "backend", IsActive: true}
+		now := time.Now()
+		_ = userRepo.CreateAbsence(ctx, &models.Absence{UserID: "u6", StartsAt: now.Add(-time.Hour), EndsAt: now.Add(72 * time.Hour)})
+		prRepo.Prs["pr-manual"] = &models.PullRequest{
+			ID:        "pr-manual",
+			AuthorID:  "u1",
+			Status:    models.PRStatusOpen,
+			Reviewers: []string{"u2"},
+		}
+
+		for userID, want := range map[string]error{
+			"u1":      models.ErrAuthorReviewer,
+			"u2":      models.ErrAlreadyAssigned,
+			"u5":      models.ErrReviewerInactive,
+			"u6":      models.ErrNotEligible,
+			"missing": models.ErrNotFound,
+		} {
+			if _, err := service.AddReviewer(ctx, "pr-manual", userID); !errors.Is(err, want) {
+				t.Errorf("Adding %s: expected %v, got %v", userID, want, err)
+			}
+		}
+
+		pr, err := service.AddReviewer(ctx, "pr-manual", "u3")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !slices.Equal(pr.Reviewers, []string{"u2", "u3"}) {
+			t.Errorf("Expected u2 and u3, got %v", pr.Reviewers)
+		}
+
+		pr, err = service.RemoveReviewer(ctx, "pr-manual", "u2")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !slices.Equal(pr.Reviewers, []string{"u3"}) {
+			t.Errorf("Expected only u3, got %v", pr.Reviewers)
+		}
+		last := prRepo.Events[len(prRepo.Events)-1]
+		if last.Type != models.EventReviewerRemoved || last.Payload.OldReviewerID != "u2" {
+			t.Errorf("Expected removed event for u2, got %+v", last)
+		}
+
+		if _, err := service.RemoveReviewer(ctx, "pr-manual", "u2"); !errors.Is(err, models.ErrNotAssigned) {
+			t.Errorf("Expected ErrNotAssigned, got %v", err)
+		}
+
+		prRepo.Prs["pr-manual"].Status = models.PRStatusMerged
+		if _, err := service.AddReviewer(ctx, "pr-manual", "u4"); !errors.Is(err, models.ErrPRMerged) {
+			t.Errorf("Expected ErrPRMerged, got %v", err)
+		}
+		if _, err := service.RemoveReviewer(ctx, "pr-manual", "u3"); !errors.Is(err, models.ErrPRMerged) {
+			t.Errorf("Expected ErrPRMerged, got %v", err)
+		}
+
+		// A reviewer added to a draft stays, and the draft gets no automatic picks when ready.
+		prRepo.Prs["pr-manual-draft"] = &models.PullRequest{ID: "pr-manual-draft", AuthorID: "u1", Status: models.PRStatusDraft}
+		if _, err := service.AddReviewer(ctx, "pr-manual-draft", "u3"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		pr, err = service.MarkReady(ctx, "pr-manual-draft")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !slices.Equal(pr.Reviewers, []string{"u3"}) {
+			t.Errorf("Expected only u3 after ready, got %v", pr.Reviewers)
+		}
+	})
+
+	t.Run("Reassign to a named user", func(t *testing.T) {
+		userRepo.Users["w1"] = &models.User{ID: "w1", TeamName: "web", IsActive: true}
+		pr, _ := prRepo.GetByID(ctx, "pr-mobile")
+		kept := pr.Reviewers[1]
+
+		for userID, want := range map[string]error{
+			"m1":      models.ErrAuthorReviewer,
+			kept:      models.ErrAlreadyAssigned,
+			"p2":      models.ErrReviewerInactive,
+			"w1":      models.ErrNotEligible,
+			"missing": models.ErrNotFound,
+		} {
+			if _, _, err := service.ReassignReviewer(ctx, "pr-mobile", "m2", userID); !errors.Is(err, want) {
+				t.Errorf("Reassigning to %s: expected %v, got %v", userID, want, err)
+			}
+		}
+
+		pr, newReviewer, err := service.ReassignReviewer(ctx, "pr-mobile", "m2", "p1")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if newReviewer != "p1" || !slices.Contains(pr.Reviewers, "p1") || !slices.Contains(pr.FallbackReviewers, "p1") {
+			t.Errorf("Expected p1 as fallback reviewer, got %s, %+v", newReviewer, pr)
+		}
+
+		pr, _ = prRepo.GetByID(ctx, "pr-billing")
+		if _, _, err := service.ReassignReviewer(ctx, "pr-billing", pr.Reviewers[0], "p1"); !errors.Is(err, models.ErrNotEligible) {
+			t.Errorf("Expected ErrNotEligible outside the pool, got %v", err)
+		}
+	})
+
+	t.Run("Reassign retries when PR changes concurrently", func(t *testing.T) {
+		racing := &racingPRRepo{TestPRRepo: prRepo}
+		racingService := NewPullRequestService(racing, userRepo, teamRepo, repoRepo, ownerRepo, txm, NewRandomSelector(), logger)
+		prRepo.Prs["pr-race"] = &models.PullRequest{
+			ID:        "pr-race",
+			AuthorID:  "u1",
+			Status:    models.PRStatusOpen,
+			Reviewers: []string{"u2", "u3"},
+		}
+
+		racing.change = func() { prRepo.Prs["pr-race"].Reviewers[1] = "u4" }
+		pr, newReviewer, err := racingService.ReassignReviewer(ctx, "pr-race", "u2", "")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if newReviewer != "u3" || !slices.Equal(pr.Reviewers, []string{"u3", "u4"}) {
+			t.Errorf("Expected u3 to replace u2 next to u4, got %s, %v", newReviewer, pr.Reviewers)
+		}
+
+		racing.change = func() { prRepo.Prs["pr-race"].Status = models.PRStatusMerged }
+		if _, _, err := racingService.ReassignReviewer(ctx, "pr-race", "u3", ""); !errors.Is(err, models.ErrPRMerged) {
+			t.Errorf("Expected ErrPRMerged after concurrent merge, got %v", err)
+		}
 	})
 }
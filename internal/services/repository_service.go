@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/repository"
+)
+
+type RepositoryService struct {
+	repo     repository.RepositoryRepositoryInterface
+	teamRepo repository.TeamRepositoryInterface
+	log      *slog.Logger
+}
+
+func NewRepositoryService(
+	repo repository.RepositoryRepositoryInterface,
+	teamRepo repository.TeamRepositoryInterface,
+	log *slog.Logger,
+) *RepositoryService {
+	return &RepositoryService{
+		repo:     repo,
+		teamRepo: teamRepo,
+		log:      log,
+	}
+}
+
+func (s *RepositoryService) CreateRepository(ctx context.Context, repo *models.Repository) (*models.Repository, error) {
+	s.log.InfoContext(ctx, "creating repository", "repository_id", repo.ID, "team_name", repo.TeamName,
+		"reviewers", repo.Reviewers)
+
+	if err := s.validate(ctx, repo); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateRepository(ctx, repo); err != nil {
+		if errors.Is(err, models.ErrAlreadyExists) {
+			s.log.WarnContext(ctx, "repository already exists", "repository_id", repo.ID)
+		} else {
+			s.log.ErrorContext(ctx, "failed to create repository", "err", err)
+		}
+		return nil, err
+	}
+	return repo, nil
+}
+
+func (s *RepositoryService) UpdateRepository(ctx context.Context, repo *models.Repository) (*models.Repository, error) {
+	s.log.InfoContext(ctx, "updating repository", "repository_id", repo.ID, "team_name", repo.TeamName,
+		"reviewers", repo.Reviewers)
+
+	if err := s.validate(ctx, repo); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateRepository(ctx, repo); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			s.log.WarnContext(ctx, "repository not found", "repository_id", repo.ID)
+		} else {
+			s.log.ErrorContext(ctx, "failed to update repository", "err", err)
+		}
+		return nil, err
+	}
+	return repo, nil
+}
+
+func (s *RepositoryService) GetRepository(ctx context.Context, id string) (*models.Repository, error) {
+	repo, err := s.repo.GetRepository(ctx, id)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			s.log.WarnContext(ctx, "repository not found", "repository_id", id)
+		} else {
+			s.log.ErrorContext(ctx, "failed to get repository", "err", err)
+		}
+		return nil, err
+	}
+	return repo, nil
+}
+
+func (s *RepositoryService) ListRepositories(ctx context.Context, teamName string) ([]models.Repository, error) {
+	if _, err := s.teamRepo.GetTeam(ctx, teamName); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			s.log.WarnContext(ctx, "team not found", "team", teamName)
+		} else {
+			s.log.ErrorContext(ctx, "failed to get team", "err", err)
+		}
+		return nil, err
+	}
+
+	repos, err := s.repo.ListRepositories(ctx, teamName)
+	if err != nil {
+		s.log.ErrorContext(ctx, "failed to list repositories", "team_name", teamName, "err", err)
+		return nil, err
+	}
+	if repos == nil {
+		return []models.Repository{}, nil
+	}
+	return repos, nil
+}
+
+// validate checks that the team exists, the reviewer pool consists of its members
+// and the settings are consistent, like team settings.
+func (s *RepositoryService) validate(ctx context.Context, repo *models.Repository) error {
+	if settings := repo.Settings; settings != nil {
+		if settings.ReviewersCount < 1 ||
+			settings.MinReviewers < 0 || settings.MinReviewers > settings.ReviewersCount ||
+			settings.RequiredApprovals < 0 || settings.RequiredApprovals > settings.ReviewersCount {
+			s.log.WarnContext(ctx, "invalid repository settings", "repository_id", repo.ID)
+			return models.ErrInvalidSettings
+		}
+	}
+
+	team, err := s.teamRepo.GetTeam(ctx, repo.TeamName)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			s.log.WarnContext(ctx, "team not found", "team", repo.TeamName)
+		} else {
+			s.log.ErrorContext(ctx, "failed to get team", "err", err)
+		}
+		return err
+	}
+
+	if repo.Reviewers == nil {
+		repo.Reviewers = []string{}
+	}
+	slices.Sort(repo.Reviewers)
+	repo.Reviewers = slices.Compact(repo.Reviewers)
+	for _, userID := range repo.Reviewers {
+		isMember := slices.ContainsFunc(team.Members, func(m models.TeamMember) bool { return m.UserID == userID })
+		if !isMember {
+			s.log.WarnContext(ctx, "reviewer is not a team member", "user_id", userID, "team_name", repo.TeamName)
+			return models.ErrInvalidReviewerPool
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/repository/inmemory"
+)
+
+func TestRepositoryService_Simple(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	repo := inmemory.NewTestRepositoryRepo()
+	teamRepo := inmemory.NewTestTeamRepo()
+	teamRepo.Teams["backend"] = &models.Team{
+		Name: "backend",
+		Members: []models.TeamMember{
+			{UserID: "u1", IsActive: true},
+			{UserID: "u2", IsActive: true},
+		},
+	}
+
+	service := NewRepositoryService(repo, teamRepo, logger)
+	ctx := context.Background()
+
+	t.Run("Create and list", func(t *testing.T) {
+		created, err := service.CreateRepository(ctx, &models.Repository{
+			ID:        "acme/api",
+			TeamName:  "backend",
+			Reviewers: []string{"u2", "u1", "u2"},
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(created.Reviewers) != 2 || created.Reviewers[0] != "u1" {
+			t.Errorf("Expected deduplicated sorted pool, got %v", created.Reviewers)
+		}
+
+		if _, err := service.CreateRepository(ctx, &models.Repository{ID: "acme/api", TeamName: "backend"}); !errors.Is(err, models.ErrAlreadyExists) {
+			t.Errorf("Expected ErrAlreadyExists, got %v", err)
+		}
+
+		repos, err := service.ListRepositories(ctx, "backend")
+		if err != nil || len(repos) != 1 || repos[0].ID != "acme/api" {
+			t.Errorf("Unexpected repositories: %+v, %v", repos, err)
+		}
+	})
+
+	t.Run("Reviewers must be team members", func(t *testing.T) {
+		_, err := service.UpdateRepository(ctx, &models.Repository{
+			ID:        "acme/api",
+			TeamName:  "backend",
+			Reviewers: []string{"stranger"},
+		})
+		if !errors.Is(err, models.ErrInvalidReviewerPool) {
+			t.Errorf("Expected ErrInvalidReviewerPool, got %v", err)
+		}
+	})
+
+	t.Run("Invalid settings", func(t *testing.T) {
+		_, err := service.UpdateRepository(ctx, &models.Repository{
+			ID:       "acme/api",
+			TeamName: "backend",
+			Settings: &models.RepositorySettings{ReviewersCount: 1, MinReviewers: 2},
+		})
+		if !errors.Is(err, models.ErrInvalidSettings) {
+			t.Errorf("Expected ErrInvalidSettings, got %v", err)
+		}
+	})
+
+	t.Run("Unknown team or repository", func(t *testing.T) {
+		if _, err := service.CreateRepository(ctx, &models.Repository{ID: "acme/web", TeamName: "frontend"}); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound for team, got %v", err)
+		}
+		if _, err := service.UpdateRepository(ctx, &models.Repository{ID: "acme/web", TeamName: "backend"}); !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound for repository, got %v", err)
+		}
+	})
+}
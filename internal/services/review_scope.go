@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/repository"
+)
+
+// defaultReviewWindow is how long a reviewer is expected to stay available for a
+// team without a review SLA.
+const defaultReviewWindow = 24 * time.Hour
+
+// reviewScope is where a PR draws reviewers from: the repository's team, pool and
+// settings when the repository is registered, the author's team otherwise.
+type reviewScope struct {
+	teamName string
+	pool     []string
+	settings *models.TeamSettings
+}
+
+// reviewScopes resolves review scopes and their available users for every path
+// that picks reviewers: creation, reassignment and deactivation.
+type reviewScopes struct {
+	userRepo repository.UserRepositoryInterface
+	teamRepo repository.TeamRepositoryInterface
+	repoRepo repository.RepositoryRepositoryInterface
+	selector ReviewerSelector
+	log      *slog.Logger
+}
+
+func newReviewScopes(
+	userRepo repository.UserRepositoryInterface,
+	teamRepo repository.TeamRepositoryInterface,
+	repoRepo repository.RepositoryRepositoryInterface,
+	selector ReviewerSelector,
+	log *slog.Logger,
+) *reviewScopes {
+	return &reviewScopes{
+		userRepo: userRepo,
+		teamRepo: teamRepo,
+		repoRepo: repoRepo,
+		selector: selector,
+		log:      log,
+	}
+}
+
+// scopeFor resolves the review scope of a PR. Unregistered repositories, such as
+// ones only known from webhooks, fall back to the author's team.
+func (s *reviewScopes) scopeFor(
+	ctx context.Context,
+	author *models.User,
+	repositoryID string,
+) (*reviewScope, error) {
+	var repo *models.Repository
+	teamName := author.TeamName
+	if repositoryID != "" {
+		found, err := s.repoRepo.GetRepository(ctx, repositoryID)
+		if err != nil && !errors.Is(err, models.ErrNotFound) {
+			return nil, err
+		}
+		if err == nil {
+			repo = found
+			teamName = repo.TeamName
+		}
+	}
+
+	settings, err := s.teamRepo.GetSettings(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	scope := &reviewScope{teamName: teamName, settings: settings}
+	if repo != nil {
+		scope.pool = repo.Reviewers
+		scope.settings = repo.Settings.Apply(settings)
+	}
+	return scope, nil
+}
+
+// candidates returns available members of the scope's team, limited to its pool.
+func (s *reviewScopes) candidates(ctx context.Context, scope *reviewScope) ([]models.User, error) {
+	users, err := s.availableUsers(ctx, scope.teamName, scope.settings)
+	if err != nil || len(scope.pool) == 0 {
+		return users, err
+	}
+	return slices.DeleteFunc(users, func(u models.User) bool {
+		return !slices.Contains(scope.pool, u.ID)
+	}), nil
+}
+
+// isAvailable reports whether user is active and not absent during the review
+// window of settings.
+func (s *reviewScopes) isAvailable(
+	ctx context.Context,
+	user *models.User,
+	settings *models.TeamSettings,
+) (bool, error) {
+	available, err := s.availableUsers(ctx, user.TeamName, settings)
+	if err != nil {
+		return false, err
+	}
+	return slices.ContainsFunc(available, func(u models.User) bool { return u.ID == user.ID }), nil
+}
+
+// availableUsers returns active team members who are not absent now or during the
+// expected review window: the team review SLA, or defaultReviewWindow without one.
+func (s *reviewScopes) availableUsers(
+	ctx context.Context,
+	teamName string,
+	settings *models.TeamSettings,
+) ([]models.User, error) {
+	window := defaultReviewWindow
+	if settings.ReviewSLAMinutes > 0 {
+		window = time.Duration(settings.ReviewSLAMinutes) * time.Minute
+	}
+	now := time.Now()
+	return s.userRepo.GetActiveUsersByTeam(ctx, teamName, now, now.Add(window))
+}
+
+// fallbackReviewers draws up to count available users from the scope's fallback
+// teams in order, skipping the author and users in exclude.
+func (s *reviewScopes) fallbackReviewers(
+	ctx context.Context,
+	scope *reviewScope,
+	authorID string,
+	exclude []string,
+	count int,
+) ([]string, error) {
+	var result []string
+	for _, teamName := range scope.settings.FallbackTeams {
+		if len(result) >= count {
+			break
+		}
+
+		available, err := s.availableUsers(ctx, teamName, scope.settings)
+		if err != nil {
+			return nil, err
+		}
+
+		candidates := make([]models.User, 0, len(available))
+		for _, user := range available {
+			if user.ID != authorID && !slices.Contains(exclude, user.ID) && !slices.Contains(result, user.ID) {
+				candidates = append(candidates, user)
+			}
+		}
+
+		selected, err := s.selector.Select(ctx, teamName, candidates, count-len(result))
+		if err != nil {
+			return nil, err
+		}
+		if len(selected) > 0 {
+			s.log.InfoContext(ctx, "using fallback team", "team_name", scope.teamName,
+				"fallback_team", teamName, "reviewers", selected)
+		}
+		result = append(result, selected...)
+	}
+	return result, nil
+}
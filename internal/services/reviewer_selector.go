@@ -0,0 +1,236 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/repository"
+)
+
+const (
+	StrategyRandom      = "random"
+	StrategyRoundRobin  = "round_robin"
+	StrategyLeastLoaded = "least_loaded"
+	StrategyWeighted    = "weighted"
+)
+
+// ReviewerSelector picks up to n reviewers out of already filtered candidates.
+type ReviewerSelector interface {
+	Select(ctx context.Context, teamName string, candidates []models.User, n int) ([]string, error)
+}
+
+// ReviewLoadProvider returns the number of OPEN pull requests each user currently reviews.
+type ReviewLoadProvider interface {
+	OpenReviewCounts(ctx context.Context, users []models.User) (map[string]int, error)
+}
+
+func NewReviewerSelector(strategy string, loads ReviewLoadProvider) (ReviewerSelector, error) {
+	switch strategy {
+	case "", StrategyRandom:
+		return NewRandomSelector(), nil
+	case StrategyRoundRobin:
+		return NewRoundRobinSelector(), nil
+	case StrategyLeastLoaded:
+		return NewLeastLoadedSelector(loads), nil
+	case StrategyWeighted:
+		return NewWeightedSelector(loads), nil
+	default:
+		return nil, fmt.Errorf("unknown reviewer strategy %q", strategy)
+	}
+}
+
+type RandomSelector struct{}
+
+func NewRandomSelector() *RandomSelector {
+	return &RandomSelector{}
+}
+
+func (s *RandomSelector) Select(_ context.Context, _ string, candidates []models.User, n int) ([]string, error) {
+	ids := userIDs(candidates)
+	rand.Shuffle(len(ids), func(i, j int) {
+		ids[i], ids[j] = ids[j], ids[i]
+	})
+	return ids[:min(n, len(ids))], nil
+}
+
+type RoundRobinSelector struct {
+	mu      sync.Mutex
+	cursors map[string]int
+}
+
+func NewRoundRobinSelector() *RoundRobinSelector {
+	return &RoundRobinSelector{cursors: make(map[string]int)}
+}
+
+func (s *RoundRobinSelector) Select(_ context.Context, teamName string, candidates []models.User, n int) ([]string, error) {
+	ids := userIDs(candidates)
+	slices.Sort(ids)
+	n = min(n, len(ids))
+	if n == 0 {
+		return []string{}, nil
+	}
+
+	s.mu.Lock()
+	start := s.cursors[teamName] % len(ids)
+	s.cursors[teamName] = start + n
+	s.mu.Unlock()
+
+	result := make([]string, 0, n)
+	for i := range n {
+		result = append(result, ids[(start+i)%len(ids)])
+	}
+	return result, nil
+}
+
+type LeastLoadedSelector struct {
+	loads ReviewLoadProvider
+}
+
+func NewLeastLoadedSelector(loads ReviewLoadProvider) *LeastLoadedSelector {
+	return &LeastLoadedSelector{loads: loads}
+}
+
+func (s *LeastLoadedSelector) Select(ctx context.Context, _ string, candidates []models.User, n int) ([]string, error) {
+	counts, err := s.loads.OpenReviewCounts(ctx, candidates)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := userIDs(candidates)
+	rand.Shuffle(len(ids), func(i, j int) {
+		ids[i], ids[j] = ids[j], ids[i]
+	})
+	sort.SliceStable(ids, func(i, j int) bool {
+		return counts[ids[i]] < counts[ids[j]]
+	})
+	return ids[:min(n, len(ids))], nil
+}
+
+type WeightedSelector struct {
+	loads ReviewLoadProvider
+}
+
+func NewWeightedSelector(loads ReviewLoadProvider) *WeightedSelector {
+	return &WeightedSelector{loads: loads}
+}
+
+func (s *WeightedSelector) Select(ctx context.Context, _ string, candidates []models.User, n int) ([]string, error) {
+	counts, err := s.loads.OpenReviewCounts(ctx, candidates)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := userIDs(candidates)
+	weights := make([]float64, len(ids))
+	for i, id := range ids {
+		weights[i] = 1 / float64(1+counts[id])
+	}
+
+	result := make([]string, 0, min(n, len(ids)))
+	for len(result) < n && len(ids) > 0 {
+		var total float64
+		for _, w := range weights {
+			total += w
+		}
+
+		idx := len(ids) - 1
+		point := rand.Float64() * total
+		for i, w := range weights {
+			if point < w {
+				idx = i
+				break
+			}
+			point -= w
+		}
+
+		result = append(result, ids[idx])
+		ids = slices.Delete(ids, idx, idx+1)
+		weights = slices.Delete(weights, idx, idx+1)
+	}
+	return result, nil
+}
+
+type TeamSelector struct {
+	fallback ReviewerSelector
+	teams    map[string]ReviewerSelector
+}
+
+func NewTeamSelector(fallback ReviewerSelector, teams map[string]ReviewerSelector) *TeamSelector {
+	return &TeamSelector{fallback: fallback, teams: teams}
+}
+
+func (s *TeamSelector) Select(ctx context.Context, teamName string, candidates []models.User, n int) ([]string, error) {
+	if selector, ok := s.teams[teamName]; ok {
+		return selector.Select(ctx, teamName, candidates, n)
+	}
+	return s.fallback.Select(ctx, teamName, candidates, n)
+}
+
+// teamStrategies has the "team=strategy,team2=strategy" form.
+func NewConfiguredSelector(defaultStrategy, teamStrategies string, loads ReviewLoadProvider) (ReviewerSelector, error) {
+	fallback, err := NewReviewerSelector(defaultStrategy, loads)
+	if err != nil {
+		return nil, err
+	}
+
+	teams := make(map[string]ReviewerSelector)
+	for _, pair := range strings.Split(teamStrategies, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		team, strategy, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid team strategy %q, expected team=strategy", pair)
+		}
+		selector, err := NewReviewerSelector(strings.TrimSpace(strategy), loads)
+		if err != nil {
+			return nil, err
+		}
+		teams[strings.TrimSpace(team)] = selector
+	}
+
+	return NewTeamSelector(fallback, teams), nil
+}
+
+type TeamReviewLoad struct {
+	userRepo repository.UserRepositoryInterface
+}
+
+func NewTeamReviewLoad(userRepo repository.UserRepositoryInterface) *TeamReviewLoad {
+	return &TeamReviewLoad{userRepo: userRepo}
+}
+
+func (l *TeamReviewLoad) OpenReviewCounts(ctx context.Context, users []models.User) (map[string]int, error) {
+	counts := make(map[string]int, len(users))
+	loaded := make(map[string]bool)
+	for _, u := range users {
+		if loaded[u.TeamName] {
+			continue
+		}
+		loaded[u.TeamName] = true
+
+		teamCounts, err := l.userRepo.GetOpenReviewCounts(ctx, u.TeamName)
+		if err != nil {
+			return nil, err
+		}
+		for id, count := range teamCounts {
+			counts[id] = count
+		}
+	}
+	return counts, nil
+}
+
+func userIDs(users []models.User) []string {
+	ids := make([]string, len(users))
+	for i, u := range users {
+		ids[i] = u.ID
+	}
+	return ids
+}
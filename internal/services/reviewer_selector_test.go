@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/repository/inmemory"
+)
+
+type stubLoads map[string]int
+
+func (l stubLoads) OpenReviewCounts(_ context.Context, _ []models.User) (map[string]int, error) {
+	return l, nil
+}
+
+func TestReviewerSelector_Simple(t *testing.T) {
+	ctx := context.Background()
+	candidates := []models.User{{ID: "u1"}, {ID: "u2"}, {ID: "u3"}}
+	loads := stubLoads{"u1": 5, "u2": 0, "u3": 2}
+
+	t.Run("Random never exceeds n", func(t *testing.T) {
+		got, err := NewRandomSelector().Select(ctx, "backend", candidates, 2)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(got) != 2 || got[0] == got[1] {
+			t.Errorf("Expected 2 distinct reviewers, got %v", got)
+		}
+	})
+
+	t.Run("Round robin rotates", func(t *testing.T) {
+		selector := NewRoundRobinSelector()
+		first, _ := selector.Select(ctx, "backend", candidates, 1)
+		second, _ := selector.Select(ctx, "backend", candidates, 1)
+		if first[0] != "u1" || second[0] != "u2" {
+			t.Errorf("Expected u1 then u2, got %v then %v", first, second)
+		}
+	})
+
+	t.Run("Least loaded picks idle reviewers", func(t *testing.T) {
+		got, err := NewLeastLoadedSelector(loads).Select(ctx, "backend", candidates, 2)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got[0] != "u2" || got[1] != "u3" {
+			t.Errorf("Expected [u2 u3], got %v", got)
+		}
+	})
+
+	t.Run("Least loaded breaks ties randomly", func(t *testing.T) {
+		userRepo := inmemory.NewTestUserRepo()
+		userRepo.Users["u1"] = &models.User{ID: "u1", TeamName: "backend", IsActive: true}
+		userRepo.Users["u2"] = &models.User{ID: "u2", TeamName: "backend", IsActive: true}
+		userRepo.Users["u3"] = &models.User{ID: "u3", TeamName: "backend", IsActive: true}
+		userRepo.ReviewCounts["u1"] = 3
+
+		team := []models.User{*userRepo.Users["u1"], *userRepo.Users["u2"], *userRepo.Users["u3"]}
+		selector := NewLeastLoadedSelector(NewTeamReviewLoad(userRepo))
+
+		seen := make(map[string]bool)
+		for range 50 {
+			got, err := selector.Select(ctx, "backend", team, 1)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got[0] == "u1" {
+				t.Fatal("Busy reviewer u1 should not be picked")
+			}
+			seen[got[0]] = true
+		}
+		if !seen["u2"] || !seen["u3"] {
+			t.Errorf("Expected both idle reviewers to be picked, got %v", seen)
+		}
+	})
+
+	t.Run("Weighted returns distinct reviewers", func(t *testing.T) {
+		got, err := NewWeightedSelector(loads).Select(ctx, "backend", candidates, 3)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(got) != 3 {
+			t.Errorf("Expected 3 reviewers, got %v", got)
+		}
+	})
+
+	t.Run("Configured per-team strategy", func(t *testing.T) {
+		selector, err := NewConfiguredSelector("random", "backend=least_loaded", loads)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		got, _ := selector.Select(ctx, "backend", candidates, 1)
+		if got[0] != "u2" {
+			t.Errorf("Expected least loaded u2, got %v", got)
+		}
+
+		if _, err := NewConfiguredSelector("unknown", "", loads); err == nil {
+			t.Error("Expected error for unknown strategy")
+		}
+	})
+}
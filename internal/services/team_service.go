@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"log/slog"
+	"slices"
 
 	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
 	"github.com/yohnnn/pr_reviewer_assignment_service/internal/repository"
@@ -47,5 +48,63 @@ func (s *TeamService) GetTeam(ctx context.Context, name string) (*models.Team, e
 		return nil, err
 	}
 
+	settings, err := s.repo.GetSettings(ctx, name)
+	if err != nil {
+		s.log.ErrorContext(ctx, "failed to get team settings", "err", err)
+		return nil, err
+	}
+	team.Settings = settings
+
 	return team, nil
 }
+
+func (s *TeamService) SetSettings(ctx context.Context, teamName string, settings *models.TeamSettings) (*models.TeamSettings, error) {
+	s.log.InfoContext(ctx, "updating team settings", "team_name", teamName,
+		"reviewers_count", settings.ReviewersCount, "min_reviewers", settings.MinReviewers,
+		"required_approvals", settings.RequiredApprovals, "fallback_teams", settings.FallbackTeams)
+
+	if settings.ReviewersCount < 1 ||
+		settings.MinReviewers < 0 || settings.MinReviewers > settings.ReviewersCount ||
+		settings.RequiredApprovals < 0 || settings.RequiredApprovals > settings.ReviewersCount ||
+		settings.ReviewSLAMinutes < 0 {
+		s.log.WarnContext(ctx, "invalid team settings", "team_name", teamName)
+		return nil, models.ErrInvalidSettings
+	}
+
+	if err := s.checkFallbackTeams(ctx, teamName, settings.FallbackTeams); err != nil {
+		return nil, err
+	}
+	if settings.FallbackTeams == nil {
+		settings.FallbackTeams = []string{}
+	}
+
+	if err := s.repo.UpsertSettings(ctx, teamName, settings); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			s.log.WarnContext(ctx, "team not found", "team", teamName)
+		} else {
+			s.log.ErrorContext(ctx, "failed to update team settings", "err", err)
+		}
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+// checkFallbackTeams rejects the team itself, duplicates and unknown teams.
+func (s *TeamService) checkFallbackTeams(ctx context.Context, teamName string, fallbackTeams []string) error {
+	for i, name := range fallbackTeams {
+		if name == teamName || slices.Contains(fallbackTeams[:i], name) {
+			s.log.WarnContext(ctx, "invalid fallback team", "team_name", teamName, "fallback_team", name)
+			return models.ErrInvalidSettings
+		}
+		if _, err := s.repo.GetTeam(ctx, name); err != nil {
+			if errors.Is(err, models.ErrNotFound) {
+				s.log.WarnContext(ctx, "fallback team not found", "team_name", teamName, "fallback_team", name)
+				return models.ErrInvalidSettings
+			}
+			s.log.ErrorContext(ctx, "failed to get fallback team", "err", err)
+			return err
+		}
+	}
+	return nil
+}
@@ -64,4 +64,46 @@ func TestTeamService_Simple(t *testing.T) {
 			t.Errorf("Expected ErrNotFound, got %v", err)
 		}
 	})
+
+	t.Run("Team settings", func(t *testing.T) {
+		team, err := service.GetTeam(ctx, "backend")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if team.Settings.ReviewersCount != 2 {
+			t.Errorf("Expected default of 2 reviewers, got %d", team.Settings.ReviewersCount)
+		}
+
+		_, err = service.SetSettings(ctx, "backend", &models.TeamSettings{ReviewersCount: 1, MinReviewers: 2})
+		if !errors.Is(err, models.ErrInvalidSettings) {
+			t.Errorf("Expected ErrInvalidSettings, got %v", err)
+		}
+
+		if _, err := service.SetSettings(ctx, "backend", &models.TeamSettings{ReviewersCount: 3, MinReviewers: 1}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		team, _ = service.GetTeam(ctx, "backend")
+		if team.Settings.ReviewersCount != 3 || team.Settings.MinReviewers != 1 {
+			t.Errorf("Settings were not saved, got %+v", team.Settings)
+		}
+	})
+
+	t.Run("Fallback teams", func(t *testing.T) {
+		repo.Teams["platform"] = &models.Team{Name: "platform"}
+
+		for _, fallback := range [][]string{{"backend"}, {"platform", "platform"}, {"ghosts"}} {
+			_, err := service.SetSettings(ctx, "backend", &models.TeamSettings{ReviewersCount: 2, FallbackTeams: fallback})
+			if !errors.Is(err, models.ErrInvalidSettings) {
+				t.Errorf("Expected ErrInvalidSettings for %v, got %v", fallback, err)
+			}
+		}
+
+		settings, err := service.SetSettings(ctx, "backend", &models.TeamSettings{
+			ReviewersCount: 2,
+			FallbackTeams:  []string{"platform"},
+		})
+		if err != nil || len(settings.FallbackTeams) != 1 {
+			t.Errorf("Unexpected result: %+v, %v", settings, err)
+		}
+	})
 }
@@ -3,21 +3,39 @@ package services
 import (
 	"context"
 	"errors"
+	"io"
 	"log/slog"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/ical"
 	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
 	"github.com/yohnnn/pr_reviewer_assignment_service/internal/repository"
 )
 
 type UserService struct {
-	repo repository.UserRepositoryInterface
-	log  *slog.Logger
+	repo     repository.UserRepositoryInterface
+	txm      repository.TxManager
+	scopes   *reviewScopes
+	selector ReviewerSelector
+	log      *slog.Logger
 }
 
-func NewUserService(repo repository.UserRepositoryInterface, log *slog.Logger) *UserService {
+func NewUserService(
+	repo repository.UserRepositoryInterface,
+	teamRepo repository.TeamRepositoryInterface,
+	repoRepo repository.RepositoryRepositoryInterface,
+	txm repository.TxManager,
+	selector ReviewerSelector,
+	log *slog.Logger,
+) *UserService {
 	return &UserService{
-		repo: repo,
-		log:  log,
+		repo:     repo,
+		txm:      txm,
+		scopes:   newReviewScopes(repo, teamRepo, repoRepo, selector, log),
+		selector: selector,
+		log:      log,
 	}
 }
 
@@ -48,7 +66,7 @@ func (s *UserService) SetUserIsActive(ctx context.Context, userID string, isActi
 		}
 	}
 
-	if err := s.repo.DeactivateUsers(ctx, []string{userID}); err != nil {
+	if err := s.deactivate(ctx, []string{userID}); err != nil {
 		s.log.ErrorContext(ctx, "failed to deactivate user", "err", err)
 		return nil, err
 	}
@@ -60,9 +78,274 @@ func (s *UserService) SetUserIsActive(ctx context.Context, userID string, isActi
 
 func (s *UserService) DeactivateUsers(ctx context.Context, userIDs []string) error {
 	s.log.InfoContext(ctx, "deactivating users", "user_ids", userIDs)
-	if err := s.repo.DeactivateUsers(ctx, userIDs); err != nil {
+	if err := s.deactivate(ctx, userIDs); err != nil {
 		s.log.ErrorContext(ctx, "failed to deactivate users", "err", err)
 		return err
 	}
 	return nil
 }
+
+func (s *UserService) LinkIdentity(ctx context.Context, identity *models.UserIdentity) error {
+	s.log.InfoContext(ctx, "linking identity", "user_id", identity.UserID,
+		"provider", identity.Provider, "login", identity.Login)
+	if identity.Provider == models.ProviderEmail {
+		identity.Login = strings.ToLower(identity.Login)
+	}
+	if err := s.repo.LinkIdentity(ctx, identity); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			s.log.WarnContext(ctx, "user not found", "user_id", identity.UserID)
+		} else {
+			s.log.ErrorContext(ctx, "failed to link identity", "err", err)
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *UserService) SetChatHandle(ctx context.Context, userID, handle string) error {
+	s.log.InfoContext(ctx, "setting chat handle", "user_id", userID, "chat_handle", handle)
+	if err := s.repo.SetChatHandle(ctx, userID, handle); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			s.log.WarnContext(ctx, "user not found", "user_id", userID)
+		} else {
+			s.log.ErrorContext(ctx, "failed to set chat handle", "err", err)
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *UserService) SetQuietHours(ctx context.Context, userID string, quiet models.QuietHours) error {
+	s.log.InfoContext(ctx, "setting quiet hours", "user_id", userID,
+		"from", quiet.From, "to", quiet.To, "timezone", quiet.Timezone)
+
+	if quiet.Timezone == "" {
+		quiet.Timezone = "UTC"
+	}
+	if err := quiet.Validate(); err != nil {
+		s.log.WarnContext(ctx, "invalid quiet hours", "user_id", userID, "err", err)
+		return models.ErrInvalidQuietHours
+	}
+
+	if err := s.repo.SetQuietHours(ctx, userID, quiet); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			s.log.WarnContext(ctx, "user not found", "user_id", userID)
+		} else {
+			s.log.ErrorContext(ctx, "failed to set quiet hours", "err", err)
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *UserService) CreateAbsence(ctx context.Context, absence *models.Absence) (*models.Absence, error) {
+	s.log.InfoContext(ctx, "creating absence", "user_id", absence.UserID,
+		"starts_at", absence.StartsAt, "ends_at", absence.EndsAt)
+
+	if !absence.EndsAt.After(absence.StartsAt) || !absence.EndsAt.After(time.Now()) {
+		s.log.WarnContext(ctx, "invalid absence period", "user_id", absence.UserID)
+		return nil, models.ErrInvalidAbsence
+	}
+
+	if err := s.repo.CreateAbsence(ctx, absence); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			s.log.WarnContext(ctx, "user not found", "user_id", absence.UserID)
+		} else {
+			s.log.ErrorContext(ctx, "failed to create absence", "err", err)
+		}
+		return nil, err
+	}
+	return absence, nil
+}
+
+func (s *UserService) ListAbsences(ctx context.Context, userID string) ([]models.Absence, error) {
+	if _, err := s.repo.GetUser(ctx, userID); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			s.log.WarnContext(ctx, "user not found", "user_id", userID)
+		} else {
+			s.log.ErrorContext(ctx, "failed to get user", "err", err)
+		}
+		return nil, err
+	}
+
+	absences, err := s.repo.ListAbsences(ctx, userID)
+	if err != nil {
+		s.log.ErrorContext(ctx, "failed to list absences", "user_id", userID, "err", err)
+		return nil, err
+	}
+	if absences == nil {
+		return []models.Absence{}, nil
+	}
+	return absences, nil
+}
+
+func (s *UserService) CancelAbsence(ctx context.Context, id int64) error {
+	s.log.InfoContext(ctx, "cancelling absence", "absence_id", id)
+	if err := s.repo.DeleteAbsence(ctx, id); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			s.log.WarnContext(ctx, "absence not found", "absence_id", id)
+		} else {
+			s.log.ErrorContext(ctx, "failed to cancel absence", "err", err)
+		}
+		return err
+	}
+	return nil
+}
+
+// ImportAbsences creates or updates absences from an iCalendar feed by event UID.
+// Cancelled events remove the absence; events that ended or whose user is unknown
+// are reported as skipped. The feed is applied in one transaction, so a failure
+// leaves no partial import.
+func (s *UserService) ImportAbsences(ctx context.Context, feed io.Reader) (*models.AbsenceImportResult, error) {
+	events, err := ical.Parse(feed)
+	if err != nil {
+		s.log.WarnContext(ctx, "failed to parse calendar", "err", err)
+		return nil, models.ErrInvalidCalendar
+	}
+	s.log.InfoContext(ctx, "importing absences", "events", len(events))
+
+	var result *models.AbsenceImportResult
+	err = s.txm.WithinTx(ctx, func(ctx context.Context) error {
+		result, err = s.applyAbsences(ctx, events)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *UserService) applyAbsences(ctx context.Context, events []ical.Event) (*models.AbsenceImportResult, error) {
+	result := &models.AbsenceImportResult{Skipped: []models.SkippedAbsence{}}
+	now := time.Now()
+	for _, e := range events {
+		if e.Err != nil {
+			s.log.WarnContext(ctx, "skipping unreadable calendar event", "uid", e.UID, "err", e.Err)
+			result.Skipped = append(result.Skipped, models.SkippedAbsence{UID: e.UID, Reason: "invalid event: " + e.Err.Error()})
+			continue
+		}
+		if e.Cancelled() {
+			if err := s.repo.DeleteAbsenceByUID(ctx, e.UID); err != nil {
+				if errors.Is(err, models.ErrNotFound) {
+					continue
+				}
+				s.log.ErrorContext(ctx, "failed to cancel absence", "uid", e.UID, "err", err)
+				return nil, err
+			}
+			result.Cancelled++
+			continue
+		}
+
+		if !e.End.After(e.Start) {
+			result.Skipped = append(result.Skipped, models.SkippedAbsence{UID: e.UID, Reason: "invalid period"})
+			continue
+		}
+		if !e.End.After(now) {
+			result.Skipped = append(result.Skipped, models.SkippedAbsence{UID: e.UID, Reason: "already ended"})
+			continue
+		}
+
+		user, err := s.calendarUser(ctx, e)
+		if err != nil {
+			if errors.Is(err, models.ErrNotFound) {
+				result.Skipped = append(result.Skipped, models.SkippedAbsence{UID: e.UID, Reason: "unknown user"})
+				continue
+			}
+			s.log.ErrorContext(ctx, "failed to resolve calendar user", "uid", e.UID, "err", err)
+			return nil, err
+		}
+
+		created, err := s.repo.UpsertAbsence(ctx, &models.Absence{
+			UserID:   user.ID,
+			StartsAt: e.Start,
+			EndsAt:   e.End,
+			Reason:   e.Summary,
+			UID:      e.UID,
+		})
+		if err != nil {
+			s.log.ErrorContext(ctx, "failed to save absence", "uid", e.UID, "err", err)
+			return nil, err
+		}
+		if created {
+			result.Created++
+		} else {
+			result.Updated++
+		}
+	}
+
+	return result, nil
+}
+
+// calendarUser matches attendees, then the organizer, against email identities
+// or, for values without "@", user IDs.
+func (s *UserService) calendarUser(ctx context.Context, e ical.Event) (*models.User, error) {
+	for _, addr := range append(slices.Clone(e.Attendees), e.Organizer) {
+		if addr == "" {
+			continue
+		}
+
+		var (
+			user *models.User
+			err  error
+		)
+		if strings.Contains(addr, "@") {
+			user, err = s.repo.GetUserByIdentity(ctx, models.ProviderEmail, strings.ToLower(addr))
+		} else {
+			user, err = s.repo.GetUser(ctx, addr)
+		}
+		if err == nil {
+			return user, nil
+		}
+		if !errors.Is(err, models.ErrNotFound) {
+			return nil, err
+		}
+	}
+	return nil, models.ErrNotFound
+}
+
+// deactivate runs in one transaction, so the replacements are picked from the
+// users, teams and repositories as they are when the change is stored.
+func (s *UserService) deactivate(ctx context.Context, userIDs []string) error {
+	event := models.NewEvent(models.EventUserDeactivated, models.EventPayload{UserIDs: userIDs})
+	return s.txm.WithinTx(ctx, func(ctx context.Context) error {
+		return s.repo.DeactivateUsers(ctx, userIDs, s.pickReplacement, event)
+	})
+}
+
+// pickReplacement draws from the review scope of the PR and then its fallback
+// teams in order, like creation and reassignment do, so a registered repository's
+// team and pool are respected.
+func (s *UserService) pickReplacement(ctx context.Context, review models.ReviewToUpdate) (string, bool, error) {
+	author, err := s.repo.GetUser(ctx, review.AuthorID)
+	if err != nil {
+		return "", false, err
+	}
+	scope, err := s.scopes.scopeFor(ctx, author, review.RepositoryID)
+	if err != nil {
+		return "", false, err
+	}
+	available, err := s.scopes.candidates(ctx, scope)
+	if err != nil {
+		return "", false, err
+	}
+	candidates := slices.DeleteFunc(available, func(u models.User) bool {
+		return u.ID == review.AuthorID || slices.Contains(review.Reviewers, u.ID)
+	})
+
+	selected, err := s.selector.Select(ctx, scope.teamName, candidates, 1)
+	if err != nil {
+		return "", false, err
+	}
+	if len(selected) > 0 {
+		return selected[0], false, nil
+	}
+
+	selected, err = s.scopes.fallbackReviewers(ctx, scope, review.AuthorID, review.Reviewers, 1)
+	if err != nil {
+		return "", false, err
+	}
+	if len(selected) == 0 {
+		return "", false, nil
+	}
+	return selected[0], true, nil
+}
@@ -5,19 +5,39 @@ import (
 	"errors"
 	"log/slog"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/repository"
 	"github.com/yohnnn/pr_reviewer_assignment_service/internal/repository/inmemory"
 )
 
+// failingUpsertRepo fails to save the absence with UID failUID.
+type failingUpsertRepo struct {
+	repository.UserRepositoryInterface
+	failUID string
+}
+
+func (r *failingUpsertRepo) UpsertAbsence(ctx context.Context, absence *models.Absence) (bool, error) {
+	if absence.UID == r.failUID {
+		return false, errors.New("connection reset")
+	}
+	return r.UserRepositoryInterface.UpsertAbsence(ctx, absence)
+}
+
 func TestUserService_Simple(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 	repo := inmemory.NewTestUserRepo()
 
 	repo.Users["u1"] = &models.User{ID: "u1", Name: "Vasya", IsActive: true}
 
-	service := NewUserService(repo, logger)
+	teamRepo := inmemory.NewTestTeamRepo()
+	repoRepo := inmemory.NewTestRepositoryRepo()
+	txm := inmemory.NewTxManager(inmemory.NewStore())
+
+	service := NewUserService(repo, teamRepo, repoRepo, txm, NewRandomSelector(), logger)
 	ctx := context.Background()
 
 	t.Run("Deactivate existing user", func(t *testing.T) {
@@ -67,5 +87,206 @@ func TestUserService_Simple(t *testing.T) {
 		if u3.IsActive {
 			t.Error("u3 must be inactive")
 		}
+
+		last := repo.Events[len(repo.Events)-1]
+		if last.Type != models.EventUserDeactivated || len(last.Payload.UserIDs) != 2 {
+			t.Errorf("Expected user.deactivated event, got %+v", last)
+		}
+	})
+
+	t.Run("Mass deactivate spreads replacements by load", func(t *testing.T) {
+		loaded := NewUserService(repo, teamRepo, repoRepo, txm, NewLeastLoadedSelector(NewTeamReviewLoad(repo)), logger)
+		teamRepo.Teams["balance"] = &models.Team{Name: "balance"}
+
+		for _, id := range []string{"a1", "r1", "r2", "c1", "c2"} {
+			repo.Users[id] = &models.User{ID: id, TeamName: "balance", IsActive: true}
+		}
+		repo.ReviewCounts["r1"] = 2
+		repo.ReviewCounts["r2"] = 2
+		repo.OpenReviews = []models.ReviewToUpdate{
+			{PRID: "pr1", OldReviewerID: "r1", AuthorID: "a1", TeamName: "balance"},
+			{PRID: "pr2", OldReviewerID: "r1", AuthorID: "a1", TeamName: "balance"},
+			{PRID: "pr3", OldReviewerID: "r2", AuthorID: "a1", TeamName: "balance"},
+			{PRID: "pr4", OldReviewerID: "r2", AuthorID: "a1", TeamName: "balance"},
+		}
+
+		if err := loaded.DeactivateUsers(ctx, []string{"r1", "r2"}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		got := map[string]int{}
+		for _, id := range repo.Replacements {
+			got[id]++
+		}
+		if len(repo.Replacements) != 4 || got["c1"] != 2 || got["c2"] != 2 {
+			t.Errorf("Expected c1 and c2 to take two reviews each, got %v", repo.Replacements)
+		}
+	})
+
+	t.Run("Mass deactivate picks from the repository scope", func(t *testing.T) {
+		teamRepo.Teams["core"] = &models.Team{Name: "core"}
+		for _, id := range []string{"p1", "p2", "p3"} {
+			repo.Users[id] = &models.User{ID: id, TeamName: "core", IsActive: true}
+		}
+		repoRepo.Repositories["core-api"] = &models.Repository{ID: "core-api", TeamName: "core", Reviewers: []string{"p1", "p2"}}
+		repo.Replacements = map[string]string{}
+		repo.OpenReviews = []models.ReviewToUpdate{
+			{PRID: "pr5", OldReviewerID: "p1", AuthorID: "a1", TeamName: "balance", RepositoryID: "core-api", Reviewers: []string{"p1"}},
+			{PRID: "pr6", OldReviewerID: "p1", AuthorID: "a1", TeamName: "balance", RepositoryID: "core-api", Reviewers: []string{"p1", "p2"}},
+		}
+
+		if err := service.DeactivateUsers(ctx, []string{"p1"}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		// Only p2 is in the pool, and it already reviews pr6.
+		if got := repo.Replacements; len(got) != 1 || got["pr5"] != "p2" {
+			t.Errorf("Expected only pr5 to be handed to p2, got %v", got)
+		}
+	})
+
+	t.Run("Mass deactivate walks the fallback teams", func(t *testing.T) {
+		for _, team := range []string{"solo", "idle", "helpers"} {
+			teamRepo.Teams[team] = &models.Team{Name: team}
+		}
+		teamRepo.Settings["solo"] = &models.TeamSettings{ReviewersCount: 1, FallbackTeams: []string{"idle", "helpers"}}
+		repo.Users["s1"] = &models.User{ID: "s1", TeamName: "solo", IsActive: true}
+		repo.Users["s2"] = &models.User{ID: "s2", TeamName: "solo", IsActive: true}
+		repo.Users["h1"] = &models.User{ID: "h1", TeamName: "helpers", IsActive: true}
+		repo.Replacements = map[string]string{}
+		repo.OpenReviews = []models.ReviewToUpdate{
+			{PRID: "pr7", OldReviewerID: "s2", AuthorID: "s1", TeamName: "solo", Reviewers: []string{"s2"}},
+		}
+
+		if err := service.DeactivateUsers(ctx, []string{"s2"}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if got := repo.Replacements["pr7"]; got != "h1" {
+			t.Errorf("Expected h1 from the fallback team to take pr7, got %q", got)
+		}
+		if !repo.FallbackReplacements["pr7"] {
+			t.Error("Expected the replacement to be marked as from a fallback team")
+		}
+	})
+
+	t.Run("Quiet hours", func(t *testing.T) {
+		err := service.SetQuietHours(ctx, "u2", models.QuietHours{From: "22:00", To: "7am"})
+		if !errors.Is(err, models.ErrInvalidQuietHours) {
+			t.Errorf("Expected ErrInvalidQuietHours, got %v", err)
+		}
+		err = service.SetQuietHours(ctx, "u2", models.QuietHours{From: "22:00", To: "07:00", Timezone: "Mars/Olympus"})
+		if !errors.Is(err, models.ErrInvalidQuietHours) {
+			t.Errorf("Expected ErrInvalidQuietHours for unknown timezone, got %v", err)
+		}
+
+		if err := service.SetQuietHours(ctx, "u2", models.QuietHours{From: "22:00", To: "07:00"}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got := repo.QuietHours["u2"]; got.Timezone != "UTC" {
+			t.Errorf("Expected timezone to default to UTC, got %+v", got)
+		}
+	})
+
+	t.Run("Absences", func(t *testing.T) {
+		now := time.Now()
+		_, err := service.CreateAbsence(ctx, &models.Absence{UserID: "u2", StartsAt: now, EndsAt: now.Add(-time.Hour)})
+		if !errors.Is(err, models.ErrInvalidAbsence) {
+			t.Errorf("Expected ErrInvalidAbsence, got %v", err)
+		}
+		_, err = service.CreateAbsence(ctx, &models.Absence{UserID: "ghost", StartsAt: now, EndsAt: now.Add(time.Hour)})
+		if !errors.Is(err, models.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+
+		absence, err := service.CreateAbsence(ctx, &models.Absence{UserID: "u2", StartsAt: now, EndsAt: now.Add(time.Hour)})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err := service.CancelAbsence(ctx, absence.ID); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if absences, _ := service.ListAbsences(ctx, "u2"); len(absences) != 0 {
+			t.Errorf("Expected no absences after cancel, got %+v", absences)
+		}
+	})
+
+	t.Run("Import absences from calendar", func(t *testing.T) {
+		_ = service.LinkIdentity(ctx, &models.UserIdentity{UserID: "u3", Provider: models.ProviderEmail, Login: "Carol@Example.com"})
+
+		start := time.Now().Add(24 * time.Hour).UTC().Format("20060102T150405Z")
+		end := time.Now().Add(72 * time.Hour).UTC().Format("20060102T150405Z")
+		feed := "BEGIN:VCALENDAR\n" +
+			"BEGIN:VEVENT\nUID:a\nSUMMARY:Vacation\nDTSTART:" + start + "\nDTEND:" + end + "\nATTENDEE:mailto:carol@example.com\nEND:VEVENT\n" +
+			"BEGIN:VEVENT\nUID:b\nDTSTART:" + start + "\nDTEND:" + end + "\nORGANIZER:u2\nEND:VEVENT\n" +
+			"BEGIN:VEVENT\nUID:c\nDTSTART:20200101\nORGANIZER:u2\nEND:VEVENT\n" +
+			"BEGIN:VEVENT\nUID:d\nDTSTART:" + start + "\nDTEND:" + end + "\nATTENDEE:mailto:nobody@example.com\nEND:VEVENT\n" +
+			"END:VCALENDAR\n"
+
+		result, err := service.ImportAbsences(ctx, strings.NewReader(feed))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if result.Created != 2 || result.Updated != 0 || len(result.Skipped) != 2 {
+			t.Errorf("Unexpected result: %+v", result)
+		}
+
+		again, _ := service.ImportAbsences(ctx, strings.NewReader(feed))
+		if again.Created != 0 || again.Updated != 2 {
+			t.Errorf("Expected re-import to update, got %+v", again)
+		}
+		if absences, _ := service.ListAbsences(ctx, "u3"); len(absences) != 1 || absences[0].Reason != "Vacation" {
+			t.Errorf("Unexpected absences: %+v", absences)
+		}
+
+		cancel := "BEGIN:VCALENDAR\nBEGIN:VEVENT\nUID:b\nSTATUS:CANCELLED\nDTSTART:" + start +
+			"\nDTEND:" + end + "\nEND:VEVENT\nEND:VCALENDAR\n"
+		result, _ = service.ImportAbsences(ctx, strings.NewReader(cancel))
+		if result.Cancelled != 1 {
+			t.Errorf("Expected cancelled absence, got %+v", result)
+		}
+
+		partly := "BEGIN:VCALENDAR\n" +
+			"BEGIN:VEVENT\nUID:e\nDTSTART:" + start + "\nDURATION:P2D\nORGANIZER:u2\nEND:VEVENT\n" +
+			"BEGIN:VEVENT\nUID:f\nDTSTART;TZID=Mars/Olympus:20300101T090000\nDURATION:PT1H\nORGANIZER:u2\nEND:VEVENT\n" +
+			"END:VCALENDAR\n"
+		result, err = service.ImportAbsences(ctx, strings.NewReader(partly))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if result.Created != 1 || len(result.Skipped) != 1 || result.Skipped[0].UID != "f" ||
+			!strings.HasPrefix(result.Skipped[0].Reason, "invalid event") {
+			t.Errorf("Expected the broken event to be skipped, got %+v", result)
+		}
+
+		if _, err := service.ImportAbsences(ctx, strings.NewReader("not a calendar")); !errors.Is(err, models.ErrInvalidCalendar) {
+			t.Errorf("Expected ErrInvalidCalendar, got %v", err)
+		}
+	})
+
+	t.Run("Failed import leaves no absences", func(t *testing.T) {
+		store := inmemory.NewStore()
+		teams := inmemory.NewTeamRepository(store)
+		err := teams.CreateTeam(ctx, &models.Team{Name: "backend", Members: []models.TeamMember{{UserID: "u1", IsActive: true}}})
+		if err != nil {
+			t.Fatalf("Failed to create team: %v", err)
+		}
+		users := &failingUpsertRepo{UserRepositoryInterface: inmemory.NewUserRepository(store), failUID: "b"}
+		stored := NewUserService(users, teams, inmemory.NewRepositoryRepository(store), inmemory.NewTxManager(store),
+			NewRandomSelector(), logger)
+
+		start := time.Now().Add(24 * time.Hour).UTC().Format("20060102T150405Z")
+		end := time.Now().Add(72 * time.Hour).UTC().Format("20060102T150405Z")
+		feed := "BEGIN:VCALENDAR\n" +
+			"BEGIN:VEVENT\nUID:a\nDTSTART:" + start + "\nDTEND:" + end + "\nORGANIZER:u1\nEND:VEVENT\n" +
+			"BEGIN:VEVENT\nUID:b\nDTSTART:" + start + "\nDTEND:" + end + "\nORGANIZER:u1\nEND:VEVENT\n" +
+			"END:VCALENDAR\n"
+
+		if _, err := stored.ImportAbsences(ctx, strings.NewReader(feed)); err == nil {
+			t.Fatal("Expected the import to fail")
+		}
+		if absences, _ := stored.ListAbsences(ctx, "u1"); len(absences) != 0 {
+			t.Errorf("Expected the import to be rolled back, got %+v", absences)
+		}
 	})
 }
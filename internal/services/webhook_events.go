@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/models"
+	"github.com/yohnnn/pr_reviewer_assignment_service/internal/repository"
+)
+
+const (
+	prActionOpen   = "open"
+	prActionClose  = "close"
+	prActionMerge  = "merge"
+	prActionReopen = "reopen"
+	prActionReady  = "ready"
+)
+
+var prActionTargets = map[string]models.PRStatus{
+	prActionClose:  models.PRStatusClosed,
+	prActionReopen: models.PRStatusOpen,
+	prActionReady:  models.PRStatusOpen,
+}
+
+// externalPREvent is a provider-neutral view of a pull/merge request event.
+type externalPREvent struct {
+	Provider     string
+	Action       string
+	PRID         string
+	RepositoryID string
+	Title        string
+	// AuthorProvider and AuthorLogin name the identity the author is linked by.
+	AuthorProvider string
+	AuthorLogin    string
+	// Actor is the login of whoever triggered the event, for logs only.
+	Actor string
+	Draft bool
+}
+
+type prEventApplier struct {
+	prService PullRequestServiceInterface
+	userRepo  repository.UserRepositoryInterface
+	log       *slog.Logger
+}
+
+// apply is idempotent: repeating an event that is already reflected in our state is not an error.
+func (a *prEventApplier) apply(ctx context.Context, event externalPREvent) error {
+	a.log.InfoContext(ctx, "applying external pr event",
+		"provider", event.Provider, "action", event.Action, "pr_id", event.PRID, "actor", event.Actor)
+
+	var err error
+	switch event.Action {
+	case prActionOpen:
+		var author *models.User
+		author, err = a.userRepo.GetUserByIdentity(ctx, event.AuthorProvider, event.AuthorLogin)
+		if err != nil {
+			if errors.Is(err, models.ErrNotFound) {
+				a.log.WarnContext(ctx, "login is not linked", "provider", event.AuthorProvider, "login", event.AuthorLogin)
+			}
+			return err
+		}
+		_, err = a.prService.CreatePullRequest(ctx, event.PRID, event.Title, author.ID, event.RepositoryID, event.Draft, nil)
+		if errors.Is(err, models.ErrAlreadyExists) {
+			err = nil
+		}
+	case prActionClose:
+		_, err = a.prService.ClosePullRequest(ctx, event.PRID)
+	case prActionMerge:
+		// The code host has merged the PR under its own rules, so the approval gate
+		// of /pullRequest/merge does not apply.
+		_, err = a.prService.RecordUpstreamMerge(ctx, event.PRID)
+	case prActionReopen:
+		_, err = a.prService.ReopenPullRequest(ctx, event.PRID)
+	case prActionReady:
+		_, err = a.prService.MarkReady(ctx, event.PRID)
+	}
+
+	if errors.Is(err, models.ErrInvalidStatus) && a.inTargetStatus(ctx, event) {
+		a.log.InfoContext(ctx, "pr already in target status", "pr_id", event.PRID, "action", event.Action)
+		err = nil
+	}
+	return err
+}
+
+// inTargetStatus reports whether the PR already has the status event leads to.
+func (a *prEventApplier) inTargetStatus(ctx context.Context, event externalPREvent) bool {
+	target, ok := prActionTargets[event.Action]
+	if !ok {
+		return false
+	}
+	pr, err := a.prService.GetPullRequest(ctx, event.PRID)
+	return err == nil && pr.Status == target
+}
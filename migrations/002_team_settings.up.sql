@@ -0,0 +1,5 @@
+CREATE TABLE team_settings (
+    team_name TEXT PRIMARY KEY REFERENCES teams(name) ON DELETE CASCADE,
+    reviewers_count INT NOT NULL DEFAULT 2 CHECK (reviewers_count >= 1),
+    min_reviewers INT NOT NULL DEFAULT 0 CHECK (min_reviewers >= 0 AND min_reviewers <= reviewers_count)
+);
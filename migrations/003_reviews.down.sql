@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS pr_reviews;
+
+ALTER TABLE team_settings DROP COLUMN IF EXISTS required_approvals;
@@ -0,0 +1,8 @@
+CREATE TABLE user_identities (
+    provider TEXT NOT NULL,
+    login TEXT NOT NULL,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    PRIMARY KEY (provider, login)
+);
+
+CREATE INDEX idx_user_identities_user ON user_identities(user_id);
@@ -0,0 +1,5 @@
+ALTER TABLE users
+    ADD COLUMN chat_handle TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE team_settings
+    ADD COLUMN chat_webhook_url TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_pr_reviewers_overdue;
+
+ALTER TABLE pr_reviewers
+    DROP COLUMN IF EXISTS overdue_at,
+    DROP COLUMN IF EXISTS assigned_at;
+
+ALTER TABLE team_settings
+    DROP COLUMN IF EXISTS sla_auto_reassign,
+    DROP COLUMN IF EXISTS review_sla_minutes;
@@ -0,0 +1,9 @@
+ALTER TABLE team_settings
+    ADD COLUMN review_sla_minutes INT NOT NULL DEFAULT 0 CHECK (review_sla_minutes >= 0),
+    ADD COLUMN sla_auto_reassign BOOLEAN NOT NULL DEFAULT false;
+
+ALTER TABLE pr_reviewers
+    ADD COLUMN assigned_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    ADD COLUMN overdue_at TIMESTAMPTZ;
+
+CREATE INDEX idx_pr_reviewers_overdue ON pr_reviewers (overdue_at) WHERE overdue_at IS NOT NULL;
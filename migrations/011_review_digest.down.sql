@@ -0,0 +1,5 @@
+ALTER TABLE users
+    DROP COLUMN IF EXISTS last_digest_at,
+    DROP COLUMN IF EXISTS timezone,
+    DROP COLUMN IF EXISTS quiet_hours_to,
+    DROP COLUMN IF EXISTS quiet_hours_from;
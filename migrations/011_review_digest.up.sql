@@ -0,0 +1,5 @@
+ALTER TABLE users
+    ADD COLUMN quiet_hours_from TEXT NOT NULL DEFAULT '',
+    ADD COLUMN quiet_hours_to TEXT NOT NULL DEFAULT '',
+    ADD COLUMN timezone TEXT NOT NULL DEFAULT 'UTC',
+    ADD COLUMN last_digest_at TIMESTAMPTZ;
@@ -0,0 +1 @@
+ALTER TABLE absences DROP COLUMN IF EXISTS external_uid;
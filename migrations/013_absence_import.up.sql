@@ -0,0 +1 @@
+ALTER TABLE absences ADD COLUMN external_uid TEXT UNIQUE;
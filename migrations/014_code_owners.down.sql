@@ -0,0 +1,3 @@
+ALTER TABLE pull_requests DROP COLUMN IF EXISTS files;
+
+DROP TABLE IF EXISTS code_owner_rules;
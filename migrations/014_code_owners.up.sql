@@ -0,0 +1,8 @@
+CREATE TABLE code_owner_rules (
+    position INT PRIMARY KEY,
+    pattern TEXT NOT NULL,
+    user_ids TEXT[] NOT NULL DEFAULT '{}',
+    team_names TEXT[] NOT NULL DEFAULT '{}'
+);
+
+ALTER TABLE pull_requests ADD COLUMN files TEXT[] NOT NULL DEFAULT '{}';
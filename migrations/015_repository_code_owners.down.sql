@@ -0,0 +1,6 @@
+ALTER TABLE pull_requests DROP COLUMN IF EXISTS repository_id;
+
+DELETE FROM code_owner_rules WHERE repository_id <> '';
+ALTER TABLE code_owner_rules DROP CONSTRAINT code_owner_rules_pkey;
+ALTER TABLE code_owner_rules DROP COLUMN repository_id;
+ALTER TABLE code_owner_rules ADD PRIMARY KEY (position);
@@ -0,0 +1,5 @@
+ALTER TABLE code_owner_rules ADD COLUMN repository_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE code_owner_rules DROP CONSTRAINT code_owner_rules_pkey;
+ALTER TABLE code_owner_rules ADD PRIMARY KEY (repository_id, position);
+
+ALTER TABLE pull_requests ADD COLUMN repository_id TEXT NOT NULL DEFAULT '';
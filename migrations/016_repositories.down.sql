@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_pull_requests_repository;
+DROP TABLE IF EXISTS repository_reviewers;
+DROP TABLE IF EXISTS repositories;
@@ -0,0 +1,17 @@
+CREATE TABLE repositories (
+    id TEXT PRIMARY KEY,
+    team_name TEXT NOT NULL REFERENCES teams(name) ON DELETE CASCADE,
+    reviewers_count INT CHECK (reviewers_count >= 1),
+    min_reviewers INT CHECK (min_reviewers >= 0 AND min_reviewers <= reviewers_count),
+    required_approvals INT CHECK (required_approvals >= 0 AND required_approvals <= reviewers_count),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE repository_reviewers (
+    repository_id TEXT NOT NULL REFERENCES repositories(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    PRIMARY KEY (repository_id, user_id)
+);
+
+CREATE INDEX idx_repositories_team ON repositories(team_name);
+CREATE INDEX idx_pull_requests_repository ON pull_requests(repository_id);
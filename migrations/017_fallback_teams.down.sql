@@ -0,0 +1,3 @@
+ALTER TABLE pr_reviewers DROP COLUMN IF EXISTS from_fallback;
+
+ALTER TABLE team_settings DROP COLUMN IF EXISTS fallback_teams;
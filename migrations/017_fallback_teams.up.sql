@@ -0,0 +1,3 @@
+ALTER TABLE team_settings ADD COLUMN fallback_teams TEXT[] NOT NULL DEFAULT '{}';
+
+ALTER TABLE pr_reviewers ADD COLUMN from_fallback BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,151 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gavv/httpexpect/v2"
+)
+
+func TestE2E_ConcurrentReassign(t *testing.T) {
+	e := httpexpect.Default(t, baseURL)
+
+	ts := time.Now().UnixNano()
+	teamName := fmt.Sprintf("e2e_race_team_%d", ts)
+	authorID := fmt.Sprintf("u_race_auth_%d", ts)
+	prID := fmt.Sprintf("pr_race_%d", ts)
+
+	userIDs := make([]string, 0, 8)
+	members := []map[string]interface{}{
+		{"user_id": authorID, "username": "Author", "is_active": true},
+	}
+	for i := range 8 {
+		userID := fmt.Sprintf("u_race%d_%d", i, ts)
+		userIDs = append(userIDs, userID)
+		members = append(members, map[string]interface{}{"user_id": userID, "username": userID, "is_active": true})
+	}
+
+	e.POST("/team/add").
+		WithJSON(map[string]interface{}{"team_name": teamName, "members": members}).
+		Expect().
+		Status(http.StatusCreated)
+
+	e.POST("/pullRequest/create").
+		WithJSON(map[string]interface{}{
+			"pull_request_id":   prID,
+			"pull_request_name": "Race",
+			"author_id":         authorID,
+		}).
+		Expect().
+		Status(http.StatusCreated)
+
+	hammer := func(workers int, extra func()) map[int]int {
+		var (
+			mu       sync.Mutex
+			wg       sync.WaitGroup
+			statuses = make(map[int]int)
+		)
+		for i := range workers {
+			wg.Add(1)
+			go func(oldUserID string) {
+				defer wg.Done()
+				status := postJSON(t, "/pullRequest/reassign", map[string]interface{}{
+					"pull_request_id": prID,
+					"old_user_id":     oldUserID,
+				})
+				mu.Lock()
+				statuses[status]++
+				mu.Unlock()
+			}(userIDs[i%len(userIDs)])
+		}
+		if extra != nil {
+			extra()
+		}
+		wg.Wait()
+		return statuses
+	}
+
+	assignedTo := func() []string {
+		var reviewers []string
+		for _, userID := range append([]string{authorID}, userIDs...) {
+			prs := e.GET("/users/getReview").
+				WithQuery("user_id", userID).
+				Expect().
+				Status(http.StatusOK).
+				JSON().Object().
+				Value("pull_requests").Array()
+			for _, pr := range prs.Iter() {
+				if pr.Object().Value("pull_request_id").String().Raw() == prID {
+					reviewers = append(reviewers, userID)
+				}
+			}
+		}
+		return reviewers
+	}
+
+	statuses := hammer(64, nil)
+	for status, count := range statuses {
+		if status != http.StatusOK && status != http.StatusConflict {
+			t.Errorf("Unexpected status %d returned %d times", status, count)
+		}
+	}
+	if statuses[http.StatusOK] == 0 {
+		t.Error("Expected some reassignments to succeed")
+	}
+	if reviewers := assignedTo(); len(reviewers) != 2 || reviewers[0] == reviewers[1] {
+		t.Fatalf("Expected two distinct reviewers after concurrent reassigns, got %v", reviewers)
+	}
+
+	statuses = hammer(32, func() {
+		e.POST("/pullRequest/merge").
+			WithJSON(map[string]interface{}{"pull_request_id": prID}).
+			Expect().
+			Status(http.StatusOK)
+	})
+	for status, count := range statuses {
+		if status != http.StatusOK && status != http.StatusConflict {
+			t.Errorf("Unexpected status %d returned %d times", status, count)
+		}
+	}
+	if reviewers := assignedTo(); len(reviewers) != 2 {
+		t.Fatalf("Expected two reviewers after merge, got %v", reviewers)
+	}
+
+	e.POST("/pullRequest/reassign").
+		WithJSON(map[string]interface{}{"pull_request_id": prID, "old_user_id": userIDs[0]}).
+		Expect().
+		Status(http.StatusConflict)
+}
+
+func postJSON(t *testing.T, path string, body interface{}) int {
+	t.Helper()
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		t.Errorf("failed to encode body: %v", err)
+		return 0
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		t.Errorf("failed to build request: %v", err)
+		return 0
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Errorf("request failed: %v", err)
+		return 0
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode
+}